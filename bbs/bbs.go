@@ -0,0 +1,218 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package bbs implements the BBS signature scheme (https://datatracker.ietf.org/doc/draft-irtf-cfrg-bbs-signatures/)
+// over BLS12-381, using the BLS12-381-SHA-256 ciphersuite. A BBS signature is over a list of messages. Its holder can
+// derive zero-knowledge proofs of the signature that disclose only some of the messages, which can't be linked to the
+// signature or to each other.
+package bbs
+
+import (
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/ugradid/ugradid-common/multiformat"
+)
+
+const (
+	// PublicKeySize is the size of a public key: a compressed point in G2.
+	PublicKeySize = 96
+	// PrivateKeySize is the size of a private key: a scalar.
+	PrivateKeySize = 32
+	// SignatureSize is the size of a signature: a compressed point in G1 and a scalar.
+	SignatureSize = pointSize + scalarSize
+
+	pointSize  = 48
+	scalarSize = 32
+)
+
+// ErrInvalidSignature is returned when a signature is invalid.
+var ErrInvalidSignature = errors.New("invalid BBS signature")
+
+// ErrInvalidProof is returned when a proof is invalid.
+var ErrInvalidProof = errors.New("invalid BBS proof")
+
+// PrivateKey is a BBS private key. It implements crypto.Signer, signing a single message with an empty header;
+// use SignMessages to sign multiple messages.
+type PrivateKey struct {
+	scalar    *bls12381.Fr
+	publicKey []byte
+}
+
+// GenerateKey generates a private key using the given source of randomness, which defaults to crypto/rand.
+func GenerateKey(random io.Reader) (*PrivateKey, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	for {
+		scalar, err := bls12381.NewFr().Rand(random)
+		if err != nil {
+			return nil, err
+		}
+		if !scalar.IsZero() {
+			return newPrivateKey(scalar), nil
+		}
+	}
+}
+
+// NewPrivateKey parses a private key, as returned by PrivateKey.Bytes.
+func NewPrivateKey(data []byte) (*PrivateKey, error) {
+	value := new(big.Int).SetBytes(data)
+	if len(data) != PrivateKeySize || value.Sign() == 0 || value.Cmp(order) >= 0 {
+		return nil, errors.New("invalid BBS private key")
+	}
+	return newPrivateKey(bls12381.NewFr().FromBytes(data)), nil
+}
+
+func newPrivateKey(scalar *bls12381.Fr) *PrivateKey {
+	g2 := bls12381.NewG2()
+	publicKey := g2.MulScalar(g2.New(), g2.One(), scalar)
+	return &PrivateKey{scalar: scalar, publicKey: g2.ToCompressed(publicKey)}
+}
+
+// Bytes returns the private key as big-endian scalar.
+func (k *PrivateKey) Bytes() []byte {
+	return k.scalar.ToBytes()
+}
+
+// Public returns the public key as multiformat.BLS12381G2PublicKey.
+func (k *PrivateKey) Public() crypto.PublicKey {
+	return multiformat.BLS12381G2PublicKey(append([]byte{}, k.publicKey...))
+}
+
+// Sign signs the data as a single message with an empty header. The random source and options are ignored, since BBS
+// signatures are deterministic.
+func (k *PrivateKey) Sign(_ io.Reader, data []byte, _ crypto.SignerOpts) ([]byte, error) {
+	return k.SignMessages(nil, [][]byte{data})
+}
+
+// SignMessages signs the messages and the header. Unlike messages, the header is always disclosed by proofs.
+func (k *PrivateKey) SignMessages(header []byte, messages [][]byte) ([]byte, error) {
+	generators := messageGenerators(len(messages) + 1)
+	scalars := messagesToScalars(messages)
+	domain := calculateDomain(k.publicKey, generators, header)
+
+	var input serializer
+	input.scalar(k.scalar)
+	for _, scalar := range scalars {
+		input.scalar(scalar)
+	}
+	input.scalar(domain)
+	e := hashToScalar(input.Bytes(), hashToScalarDST)
+
+	denominator := bls12381.NewFr()
+	denominator.Add(k.scalar, e)
+	if denominator.IsZero() {
+		return nil, errors.New("unable to create BBS signature")
+	}
+	denominator.Inverse(denominator)
+	g1 := bls12381.NewG1()
+	a := g1.MulScalar(g1.New(), commitment(generators, domain, scalars), denominator)
+	return append(g1.ToCompressed(a), e.ToBytes()...), nil
+}
+
+// Verify verifies the signature over the messages and header using the given public key.
+func Verify(publicKey []byte, signature []byte, header []byte, messages [][]byte) error {
+	w, err := decodePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	a, e, err := decodeSignature(signature)
+	if err != nil {
+		return err
+	}
+	generators := messageGenerators(len(messages) + 1)
+	b := commitment(generators, calculateDomain(publicKey, generators, header), messagesToScalars(messages))
+
+	// e(A, W + P2 * e) * e(B, -P2) == 1
+	g2 := bls12381.NewG2()
+	exponent := g2.MulScalar(g2.New(), g2.One(), e)
+	g2.Add(exponent, exponent, w)
+	if !bls12381.NewEngine().AddPair(a, exponent).AddPairInv(b, g2.One()).Check() {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// commitment returns B = P1 + Q_1 * domain + H_1 * msg_1 + ... + H_L * msg_L.
+func commitment(generators []*bls12381.PointG1, domain *bls12381.Fr, scalars []*bls12381.Fr) *bls12381.PointG1 {
+	g1 := bls12381.NewG1()
+	result := g1.New().Set(baseGenerator())
+	term := g1.New()
+	g1.Add(result, result, g1.MulScalar(term, generators[0], domain))
+	for i, scalar := range scalars {
+		g1.Add(result, result, g1.MulScalar(term, generators[i+1], scalar))
+	}
+	return result
+}
+
+func decodePublicKey(publicKey []byte) (*bls12381.PointG2, error) {
+	g2 := bls12381.NewG2()
+	if len(publicKey) != PublicKeySize {
+		return nil, fmt.Errorf("invalid BBS public key: must be %d bytes", PublicKeySize)
+	}
+	point, err := g2.FromCompressed(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BBS public key: %w", err)
+	}
+	if g2.IsZero(point) {
+		return nil, errors.New("invalid BBS public key: identity")
+	}
+	return point, nil
+}
+
+func decodeSignature(signature []byte) (*bls12381.PointG1, *bls12381.Fr, error) {
+	if len(signature) != SignatureSize {
+		return nil, nil, ErrInvalidSignature
+	}
+	a, err := decodePoint(signature[:pointSize])
+	if err != nil {
+		return nil, nil, ErrInvalidSignature
+	}
+	e, err := decodeScalar(signature[pointSize:])
+	if err != nil {
+		return nil, nil, ErrInvalidSignature
+	}
+	return a, e, nil
+}
+
+// decodePoint decodes a compressed point in G1, which must not be the identity.
+func decodePoint(data []byte) (*bls12381.PointG1, error) {
+	g1 := bls12381.NewG1()
+	point, err := g1.FromCompressed(data)
+	if err != nil {
+		return nil, err
+	}
+	if g1.IsZero(point) {
+		return nil, errors.New("point is identity")
+	}
+	return point, nil
+}
+
+// decodeScalar decodes a non-zero scalar.
+func decodeScalar(data []byte) (*bls12381.Fr, error) {
+	value := new(big.Int).SetBytes(data)
+	if value.Sign() == 0 || value.Cmp(order) >= 0 {
+		return nil, errors.New("invalid scalar")
+	}
+	return bls12381.NewFr().FromBytes(data), nil
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package bbs
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/ugradid/ugradid-common/multiformat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMessages = [][]byte{
+	[]byte("given name: Alice"),
+	[]byte("family name: Doe"),
+	[]byte("birth date: 1990-01-01"),
+	[]byte("nationality: NL"),
+}
+
+func TestGenerators(t *testing.T) {
+	// Test vector from draft-irtf-cfrg-bbs-signatures, BLS12-381-SHA-256 ciphersuite
+	expected := "a8ce256102840821a3e94ea9025e4662b205762f9776b3a766c872b948f1fd225e7c59698588e70d11406d161b4e28c9"
+
+	assert.Equal(t, expected, hex.EncodeToString(bls12381.NewG1().ToCompressed(baseGenerator())))
+	assert.Len(t, messageGenerators(11), 11)
+}
+
+func TestNewPrivateKey(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		// Test vector from draft-irtf-cfrg-bbs-signatures, BLS12-381-SHA-256 ciphersuite
+		data, _ := hex.DecodeString("60e55110f76883a13d030b2f6bd11883422d5abde717569fc0731f51237169fc")
+		expected := "a820f230f6ae38503b86c70dc50b61c58a77e45c39ab25c0652bbaa8fa136f2851bd4781c9dcde39fc9d1d52c9e60268061e7d7632171d91aa8d460acee0e96f1e7c4cfb12d3ff9ab5d5dc91c277db75c845d649ef3c4f63aebc364cd55ded0c"
+
+		key, err := NewPrivateKey(data)
+
+		require.NoError(t, err)
+		assert.Equal(t, data, key.Bytes())
+		require.IsType(t, multiformat.BLS12381G2PublicKey{}, key.Public())
+		assert.Equal(t, expected, hex.EncodeToString(key.Public().(multiformat.BLS12381G2PublicKey)))
+	})
+	t.Run("zero", func(t *testing.T) {
+		_, err := NewPrivateKey(make([]byte, PrivateKeySize))
+
+		assert.EqualError(t, err, "invalid BBS private key")
+	})
+	t.Run("invalid size", func(t *testing.T) {
+		_, err := NewPrivateKey([]byte{1, 2, 3})
+
+		assert.EqualError(t, err, "invalid BBS private key")
+	})
+}
+
+func TestSignMessages(t *testing.T) {
+	key, err := GenerateKey(nil)
+	require.NoError(t, err)
+	publicKey := key.Public().(multiformat.BLS12381G2PublicKey)
+	header := []byte("header")
+
+	signature, err := key.SignMessages(header, testMessages)
+
+	require.NoError(t, err)
+	assert.Len(t, signature, SignatureSize)
+	assert.NoError(t, Verify(publicKey, signature, header, testMessages))
+	t.Run("deterministic", func(t *testing.T) {
+		other, _ := key.SignMessages(header, testMessages)
+
+		assert.Equal(t, signature, other)
+	})
+	t.Run("other header", func(t *testing.T) {
+		assert.ErrorIs(t, Verify(publicKey, signature, []byte("other"), testMessages), ErrInvalidSignature)
+	})
+	t.Run("other message", func(t *testing.T) {
+		messages := append([][]byte{[]byte("given name: Bob")}, testMessages[1:]...)
+
+		assert.ErrorIs(t, Verify(publicKey, signature, header, messages), ErrInvalidSignature)
+	})
+	t.Run("other key", func(t *testing.T) {
+		other, _ := GenerateKey(nil)
+
+		assert.ErrorIs(t, Verify(other.Public().(multiformat.BLS12381G2PublicKey), signature, header, testMessages), ErrInvalidSignature)
+	})
+	t.Run("single message", func(t *testing.T) {
+		signature, err := key.Sign(nil, []byte("message"), nil)
+
+		require.NoError(t, err)
+		assert.NoError(t, Verify(publicKey, signature, nil, [][]byte{[]byte("message")}))
+	})
+}
+
+func TestCreateProof(t *testing.T) {
+	key, _ := GenerateKey(nil)
+	publicKey := key.Public().(multiformat.BLS12381G2PublicKey)
+	header := []byte("header")
+	presentationHeader := []byte("nonce")
+	signature, _ := key.SignMessages(header, testMessages)
+	disclosed := map[int][]byte{0: testMessages[0], 2: testMessages[2]}
+
+	proof, err := CreateProof(publicKey, signature, header, presentationHeader, testMessages, []int{2, 0})
+
+	require.NoError(t, err)
+	assert.NoError(t, VerifyProof(publicKey, proof, header, presentationHeader, len(testMessages), disclosed))
+	t.Run("unlinkable", func(t *testing.T) {
+		other, err := CreateProof(publicKey, signature, header, presentationHeader, testMessages, []int{0, 2})
+
+		require.NoError(t, err)
+		assert.False(t, bytes.Equal(proof, other))
+		assert.NoError(t, VerifyProof(publicKey, other, header, presentationHeader, len(testMessages), disclosed))
+	})
+	t.Run("disclose none", func(t *testing.T) {
+		proof, err := CreateProof(publicKey, signature, header, presentationHeader, testMessages, nil)
+
+		require.NoError(t, err)
+		assert.NoError(t, VerifyProof(publicKey, proof, header, presentationHeader, len(testMessages), nil))
+	})
+	t.Run("other presentation header", func(t *testing.T) {
+		err := VerifyProof(publicKey, proof, header, []byte("other"), len(testMessages), disclosed)
+
+		assert.ErrorIs(t, err, ErrInvalidProof)
+	})
+	t.Run("other disclosed message", func(t *testing.T) {
+		err := VerifyProof(publicKey, proof, header, presentationHeader, len(testMessages), map[int][]byte{0: []byte("given name: Bob"), 2: testMessages[2]})
+
+		assert.ErrorIs(t, err, ErrInvalidProof)
+	})
+	t.Run("other message count", func(t *testing.T) {
+		err := VerifyProof(publicKey, proof, header, presentationHeader, len(testMessages)+1, disclosed)
+
+		assert.ErrorIs(t, err, ErrInvalidProof)
+	})
+	t.Run("invalid signature", func(t *testing.T) {
+		_, err := CreateProof(publicKey, signature, []byte("other"), presentationHeader, testMessages, []int{0})
+
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+	t.Run("index out of range", func(t *testing.T) {
+		_, err := CreateProof(publicKey, signature, header, presentationHeader, testMessages, []int{4})
+
+		assert.EqualError(t, err, "disclosed message index out of range: 4")
+	})
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package bbs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sync"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// apiID identifies the ciphersuite (BLS12-381-SHA-256) and the interface (messages mapped to scalars by hashing).
+const apiID = "BBS_BLS12381G1_XMD:SHA-256_SSWU_RO_H2G_HM2S_"
+
+const (
+	// expandLength is the number of uniform bytes hashed to a scalar or used as generator seed.
+	expandLength         = 48
+	hashToScalarDST      = apiID + "H2S_"
+	mapMessageDST        = apiID + "MAP_MSG_TO_SCALAR_AS_HASH_"
+	generatorSeedDST     = apiID + "SIG_GENERATOR_SEED_"
+	generatorDST         = apiID + "SIG_GENERATOR_DST_"
+	messageGeneratorSeed = apiID + "MESSAGE_GENERATOR_SEED"
+	baseGeneratorSeed    = apiID + "BP_MESSAGE_GENERATOR_SEED"
+)
+
+// order is the order of the BLS12-381 groups, of which scalars are elements.
+var order = bls12381.NewG1().Q()
+
+var generatorCache = struct {
+	mux        sync.Mutex
+	base       *bls12381.PointG1
+	generators []*bls12381.PointG1
+}{}
+
+// baseGenerator returns P1, the base point of the ciphersuite.
+func baseGenerator() *bls12381.PointG1 {
+	generatorCache.mux.Lock()
+	defer generatorCache.mux.Unlock()
+	if generatorCache.base == nil {
+		generatorCache.base = createGenerators(baseGeneratorSeed, 1)[0]
+	}
+	return generatorCache.base
+}
+
+// messageGenerators returns the given number of generators: Q_1 followed by H_1, ..., H_L.
+func messageGenerators(count int) []*bls12381.PointG1 {
+	generatorCache.mux.Lock()
+	defer generatorCache.mux.Unlock()
+	if len(generatorCache.generators) < count {
+		generatorCache.generators = createGenerators(messageGeneratorSeed, count)
+	}
+	return generatorCache.generators[:count]
+}
+
+func createGenerators(seed string, count int) []*bls12381.PointG1 {
+	g1 := bls12381.NewG1()
+	v := expandMessageXMD([]byte(seed), []byte(generatorSeedDST), expandLength)
+	result := make([]*bls12381.PointG1, count)
+	for i := range result {
+		v = expandMessageXMD(append(v, i2osp(uint64(i+1))...), []byte(generatorSeedDST), expandLength)
+		generator, err := g1.HashToCurve(v, []byte(generatorDST))
+		if err != nil {
+			// Only fails for domain separation tags longer than 255 bytes
+			panic(err)
+		}
+		result[i] = generator
+	}
+	return result
+}
+
+// hashToScalar hashes the message to a scalar, using expand_message_xmd with SHA-256.
+func hashToScalar(message []byte, dst string) *bls12381.Fr {
+	value := new(big.Int).SetBytes(expandMessageXMD(message, []byte(dst), expandLength))
+	return bls12381.NewFr().FromBytes(value.Mod(value, order).Bytes())
+}
+
+// messagesToScalars maps the messages to scalars by hashing them.
+func messagesToScalars(messages [][]byte) []*bls12381.Fr {
+	result := make([]*bls12381.Fr, len(messages))
+	for i, message := range messages {
+		result[i] = hashToScalar(message, mapMessageDST)
+	}
+	return result
+}
+
+// calculateDomain returns the domain scalar, which binds a signature to the public key, the generators and the header.
+func calculateDomain(publicKey []byte, generators []*bls12381.PointG1, header []byte) *bls12381.Fr {
+	var input serializer
+	input.Write(publicKey)
+	input.integer(uint64(len(generators) - 1))
+	for _, generator := range generators {
+		input.g1(generator)
+	}
+	input.WriteString(apiID)
+	input.integer(uint64(len(header)))
+	input.Write(header)
+	return hashToScalar(input.Bytes(), hashToScalarDST)
+}
+
+// expandMessageXMD implements expand_message_xmd with SHA-256 (RFC 9380, section 5.3.1).
+func expandMessageXMD(message []byte, dst []byte, length int) []byte {
+	ell := (length + sha256.Size - 1) / sha256.Size
+	if ell > 255 || len(dst) > 255 {
+		panic(errors.New("expand_message_xmd: invalid length"))
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+	h := sha256.New()
+	h.Write(make([]byte, h.BlockSize()))
+	h.Write(message)
+	h.Write([]byte{byte(length >> 8), byte(length), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	result := make([]byte, 0, ell*sha256.Size)
+	previous := make([]byte, sha256.Size)
+	for i := 1; i <= ell; i++ {
+		h.Reset()
+		for j := range previous {
+			previous[j] ^= b0[j]
+		}
+		h.Write(previous)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		previous = h.Sum(nil)
+		result = append(result, previous...)
+	}
+	return result[:length]
+}
+
+func i2osp(value uint64) []byte {
+	result := make([]byte, 8)
+	binary.BigEndian.PutUint64(result, value)
+	return result
+}
+
+// serializer serializes points, scalars and integers as input for hashing.
+type serializer struct {
+	bytes.Buffer
+}
+
+func (s *serializer) g1(point *bls12381.PointG1) {
+	s.Write(bls12381.NewG1().ToCompressed(point))
+}
+
+func (s *serializer) scalar(scalar *bls12381.Fr) {
+	s.Write(scalar.ToBytes())
+}
+
+func (s *serializer) integer(value uint64) {
+	s.Write(i2osp(value))
+}
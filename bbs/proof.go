@@ -0,0 +1,221 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package bbs
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// CreateProof derives a zero-knowledge proof of the signature over the messages and header, which discloses only the
+// messages at the given (zero-based) indexes. The presentation header is disclosed by the proof and binds it to its
+// verifier, e.g. by holding a nonce provided by the verifier. Every call returns a different, unlinkable proof.
+func CreateProof(publicKey []byte, signature []byte, header []byte, presentationHeader []byte, messages [][]byte, disclosed []int) ([]byte, error) {
+	if err := Verify(publicKey, signature, header, messages); err != nil {
+		return nil, err
+	}
+	a, e, _ := decodeSignature(signature)
+	disclosed, undisclosed, err := partition(disclosed, len(messages))
+	if err != nil {
+		return nil, err
+	}
+	generators := messageGenerators(len(messages) + 1)
+	scalars := messagesToScalars(messages)
+	domain := calculateDomain(publicKey, generators, header)
+	random, err := randomScalars(5 + len(undisclosed))
+	if err != nil {
+		return nil, err
+	}
+	r1, r2, eTilde, r1Tilde, r3Tilde, mTilde := random[0], random[1], random[2], random[3], random[4], random[5:]
+
+	g1 := bls12381.NewG1()
+	d := g1.MulScalar(g1.New(), commitment(generators, domain, scalars), r2)
+	aBar := g1.MulScalar(g1.New(), a, mul(r1, r2))
+	// Bbar = D * r1 - Abar * e
+	bBar := g1.Sub(g1.New(), g1.MulScalar(g1.New(), d, r1), g1.MulScalar(g1.New(), aBar, e))
+	// T1 = Abar * e~ + D * r1~
+	t1 := g1.Add(g1.New(), g1.MulScalar(g1.New(), aBar, eTilde), g1.MulScalar(g1.New(), d, r1Tilde))
+	// T2 = D * r3~ + H_j1 * m~_j1 + ... + H_jU * m~_jU
+	t2 := g1.MulScalar(g1.New(), d, r3Tilde)
+	for i, index := range undisclosed {
+		g1.Add(t2, t2, g1.MulScalar(g1.New(), generators[index+1], mTilde[i]))
+	}
+	c := challenge(aBar, bBar, d, t1, t2, disclosed, scalars, domain, presentationHeader)
+
+	r3 := bls12381.NewFr()
+	r3.Inverse(r2)
+	var proof serializer
+	proof.g1(aBar)
+	proof.g1(bBar)
+	proof.g1(d)
+	proof.scalar(add(eTilde, mul(e, c)))
+	proof.scalar(sub(r1Tilde, mul(r1, c)))
+	proof.scalar(sub(r3Tilde, mul(r3, c)))
+	for i, index := range undisclosed {
+		proof.scalar(add(mTilde[i], mul(scalars[index], c)))
+	}
+	proof.scalar(c)
+	return proof.Bytes(), nil
+}
+
+// VerifyProof verifies a proof created by CreateProof, given the public key, the header, the presentation header, the
+// total number of signed messages and the disclosed messages by index.
+func VerifyProof(publicKey []byte, proof []byte, header []byte, presentationHeader []byte, messageCount int, disclosed map[int][]byte) error {
+	w, err := decodePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	disclosedIndexes := make([]int, 0, len(disclosed))
+	for index := range disclosed {
+		disclosedIndexes = append(disclosedIndexes, index)
+	}
+	disclosedIndexes, undisclosed, err := partition(disclosedIndexes, messageCount)
+	if err != nil {
+		return err
+	}
+	if len(proof) != 3*pointSize+(4+len(undisclosed))*scalarSize {
+		return fmt.Errorf("%w: invalid length", ErrInvalidProof)
+	}
+	var points [3]*bls12381.PointG1
+	for i := range points {
+		if points[i], err = decodePoint(proof[i*pointSize : (i+1)*pointSize]); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidProof, err)
+		}
+	}
+	aBar, bBar, d := points[0], points[1], points[2]
+	proofScalars := make([]*bls12381.Fr, 4+len(undisclosed))
+	for i := range proofScalars {
+		offset := 3*pointSize + i*scalarSize
+		if proofScalars[i], err = decodeScalar(proof[offset : offset+scalarSize]); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidProof, err)
+		}
+	}
+	eHat, r1Hat, r3Hat, mHat, c := proofScalars[0], proofScalars[1], proofScalars[2], proofScalars[3:len(proofScalars)-1], proofScalars[len(proofScalars)-1]
+
+	generators := messageGenerators(messageCount + 1)
+	domain := calculateDomain(publicKey, generators, header)
+	scalars := make([]*bls12381.Fr, messageCount)
+	for _, index := range disclosedIndexes {
+		scalars[index] = hashToScalar(disclosed[index], mapMessageDST)
+	}
+
+	g1 := bls12381.NewG1()
+	// T1 = Bbar * c + Abar * e^ + D * r1^
+	t1 := g1.MulScalar(g1.New(), bBar, c)
+	g1.Add(t1, t1, g1.MulScalar(g1.New(), aBar, eHat))
+	g1.Add(t1, t1, g1.MulScalar(g1.New(), d, r1Hat))
+	// Bv = P1 + Q_1 * domain + H_i1 * msg_i1 + ... + H_iR * msg_iR
+	bv := g1.New().Set(baseGenerator())
+	g1.Add(bv, bv, g1.MulScalar(g1.New(), generators[0], domain))
+	for _, index := range disclosedIndexes {
+		g1.Add(bv, bv, g1.MulScalar(g1.New(), generators[index+1], scalars[index]))
+	}
+	// T2 = Bv * c + D * r3^ + H_j1 * m^_j1 + ... + H_jU * m^_jU
+	t2 := g1.MulScalar(g1.New(), bv, c)
+	g1.Add(t2, t2, g1.MulScalar(g1.New(), d, r3Hat))
+	for i, index := range undisclosed {
+		g1.Add(t2, t2, g1.MulScalar(g1.New(), generators[index+1], mHat[i]))
+	}
+	if !challenge(aBar, bBar, d, t1, t2, disclosedIndexes, scalars, domain, presentationHeader).Equal(c) {
+		return ErrInvalidProof
+	}
+	// e(Abar, W) * e(Bbar, -P2) == 1
+	if !bls12381.NewEngine().AddPair(aBar, w).AddPairInv(bBar, bls12381.NewG2().One()).Check() {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// challenge computes the challenge of a proof from its commitments, the disclosed messages and the presentation header.
+func challenge(aBar, bBar, d, t1, t2 *bls12381.PointG1, disclosed []int, scalars []*bls12381.Fr, domain *bls12381.Fr, presentationHeader []byte) *bls12381.Fr {
+	var input serializer
+	for _, point := range []*bls12381.PointG1{aBar, bBar, d, t1, t2} {
+		input.g1(point)
+	}
+	input.integer(uint64(len(disclosed)))
+	for _, index := range disclosed {
+		input.integer(uint64(index))
+	}
+	for _, index := range disclosed {
+		input.scalar(scalars[index])
+	}
+	input.scalar(domain)
+	input.integer(uint64(len(presentationHeader)))
+	input.Write(presentationHeader)
+	return hashToScalar(input.Bytes(), hashToScalarDST)
+}
+
+// partition returns the sorted disclosed indexes and the undisclosed indexes of the messages.
+func partition(disclosed []int, messageCount int) ([]int, []int, error) {
+	isDisclosed := make([]bool, messageCount)
+	for _, index := range disclosed {
+		if index < 0 || index >= messageCount {
+			return nil, nil, fmt.Errorf("disclosed message index out of range: %d", index)
+		}
+		if isDisclosed[index] {
+			return nil, nil, fmt.Errorf("duplicate disclosed message index: %d", index)
+		}
+		isDisclosed[index] = true
+	}
+	sorted := append([]int{}, disclosed...)
+	sort.Ints(sorted)
+	var undisclosed []int
+	for index, ok := range isDisclosed {
+		if !ok {
+			undisclosed = append(undisclosed, index)
+		}
+	}
+	return sorted, undisclosed, nil
+}
+
+func randomScalars(count int) ([]*bls12381.Fr, error) {
+	result := make([]*bls12381.Fr, count)
+	for i := range result {
+		scalar, err := bls12381.NewFr().Rand(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		if scalar.IsZero() {
+			return nil, errors.New("unable to generate random scalar")
+		}
+		result[i] = scalar
+	}
+	return result, nil
+}
+
+func add(a, b *bls12381.Fr) *bls12381.Fr {
+	result := bls12381.NewFr()
+	result.Add(a, b)
+	return result
+}
+
+func sub(a, b *bls12381.Fr) *bls12381.Fr {
+	result := bls12381.NewFr()
+	result.Sub(a, b)
+	return result
+}
+
+func mul(a, b *bls12381.Fr) *bls12381.Fr {
+	result := bls12381.NewFr()
+	result.Mul(a, b)
+	return result
+}
@@ -0,0 +1,251 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package did
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is the time a resolved DID document is cached when no TTL is configured.
+const DefaultCacheTTL = 5 * time.Minute
+
+// DefaultCacheMaxEntries is the maximum number of cached DIDs when no maximum is configured.
+const DefaultCacheMaxEntries = 1000
+
+// CacheConfig configures a CachingResolver.
+type CacheConfig struct {
+	// TTL is the time a resolved DID document is cached. Defaults to DefaultCacheTTL.
+	TTL time.Duration
+	// NegativeTTL is the time a NotFoundErr is cached. When it is zero, NotFoundErr isn't cached.
+	NegativeTTL time.Duration
+	// MaxEntries is the maximum number of cached DIDs. When it is exceeded, the least recently used entry is evicted.
+	// Defaults to DefaultCacheMaxEntries.
+	MaxEntries int
+}
+
+// CachingResolver is a Resolver that caches the results of an underlying Resolver. Concurrent resolutions of the same
+// DID that isn't cached yet result in a single call to the underlying resolver.
+// Returned documents and metadata are shared between callers and must not be modified.
+type CachingResolver struct {
+	resolver Resolver
+	config   CacheConfig
+	now      func() time.Time
+
+	mux     sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	flights map[string]*flight
+}
+
+type cacheEntry struct {
+	did      string
+	document *Document
+	metadata *DocumentMetadata
+	err      error
+	expires  time.Time
+}
+
+// flight represents an in-progress resolution of a DID by the underlying resolver.
+type flight struct {
+	done     chan struct{}
+	document *Document
+	metadata *DocumentMetadata
+	err      error
+	// invalidated indicates the DID was invalidated while the flight was in progress, so its result must not be cached.
+	// It is guarded by the CachingResolver's mutex.
+	invalidated bool
+}
+
+// NewCachingResolver creates a CachingResolver that caches the results of the given resolver.
+func NewCachingResolver(resolver Resolver, config CacheConfig) *CachingResolver {
+	if config.TTL <= 0 {
+		config.TTL = DefaultCacheTTL
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultCacheMaxEntries
+	}
+	return &CachingResolver{
+		resolver: resolver,
+		config:   config,
+		now:      time.Now,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		flights:  make(map[string]*flight),
+	}
+}
+
+// Resolve returns the cached resolution result for the given DID, or resolves it using the underlying resolver.
+func (c *CachingResolver) Resolve(inputDID string) (*Document, *DocumentMetadata, error) {
+	return c.resolve(context.Background(), inputDID)
+}
+
+// ResolveContext is like Resolve, but stops waiting for the underlying resolver when the given context is cancelled.
+// The underlying resolution continues for other callers and to populate the cache.
+// Requests for specific versions of a DID document bypass the cache.
+func (c *CachingResolver) ResolveContext(ctx context.Context, inputDID string, options ResolutionOptions) (*ResolutionResult, error) {
+	if options.Versioned() {
+		return NewContextResolver(c.resolver).ResolveContext(ctx, inputDID, options)
+	}
+	start := c.now()
+	document, metadata, err := c.resolve(ctx, inputDID)
+	result, err := NewResolutionResult(document, metadata, err, options)
+	result.ResolutionMetadata.Duration = c.now().Sub(start)
+	return result, err
+}
+
+// Invalidate removes the given DID from the cache, so it is resolved again on next use.
+func (c *CachingResolver) Invalidate(inputDID string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.invalidate(inputDID)
+}
+
+// InvalidateAll removes all DIDs from the cache.
+func (c *CachingResolver) InvalidateAll() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	for _, f := range c.flights {
+		f.invalidated = true
+	}
+	c.flights = make(map[string]*flight)
+}
+
+// NotifyUpdated signals the DID document of the given DID was updated at the given time (e.g. when observing the update
+// on a ledger). If the cached DID document wasn't updated at or after that time, it is removed from the cache.
+func (c *CachingResolver) NotifyUpdated(inputDID string, updated time.Time) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if element, ok := c.entries[inputDID]; ok {
+		if cachedUpdated := updatedAt(element.Value.(*cacheEntry)); cachedUpdated != nil && !cachedUpdated.Before(updated) {
+			return
+		}
+	}
+	c.invalidate(inputDID)
+}
+
+func (c *CachingResolver) resolve(ctx context.Context, inputDID string) (*Document, *DocumentMetadata, error) {
+	c.mux.Lock()
+	if entry := c.get(inputDID); entry != nil {
+		c.mux.Unlock()
+		return entry.document, entry.metadata, entry.err
+	}
+	f, ok := c.flights[inputDID]
+	if !ok {
+		f = &flight{done: make(chan struct{})}
+		c.flights[inputDID] = f
+		go c.fly(inputDID, f)
+	}
+	c.mux.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case <-f.done:
+		return f.document, f.metadata, f.err
+	}
+}
+
+// fly resolves the DID using the underlying resolver and caches the result, unless the DID was invalidated meanwhile.
+// If the cache holds a newer version of the DID document than was resolved, the flight yields the cached version.
+func (c *CachingResolver) fly(inputDID string, f *flight) {
+	f.document, f.metadata, f.err = c.resolver.Resolve(inputDID)
+
+	c.mux.Lock()
+	if c.flights[inputDID] == f {
+		delete(c.flights, inputDID)
+	}
+	if !f.invalidated {
+		c.put(inputDID, f)
+	}
+	c.mux.Unlock()
+	close(f.done)
+}
+
+// get returns the cached entry for the given DID if it hasn't expired, otherwise nil. Expired entries are retained until
+// they're replaced, so put can make sure an entry is never replaced by an older version of the DID document.
+func (c *CachingResolver) get(inputDID string) *cacheEntry {
+	element, ok := c.entries[inputDID]
+	if !ok {
+		return nil
+	}
+	entry := element.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		return nil
+	}
+	c.lru.MoveToFront(element)
+	return entry
+}
+
+func (c *CachingResolver) put(inputDID string, f *flight) {
+	var ttl time.Duration
+	switch {
+	case f.err == nil:
+		ttl = c.config.TTL
+	case errors.Is(f.err, NotFoundErr) && c.config.NegativeTTL > 0:
+		ttl = c.config.NegativeTTL
+	default:
+		// Other errors are likely to be transient, so they're not cached
+		return
+	}
+	entry := &cacheEntry{did: inputDID, document: f.document, metadata: f.metadata, err: f.err, expires: c.now().Add(ttl)}
+
+	if element, ok := c.entries[inputDID]; ok {
+		current := element.Value.(*cacheEntry)
+		// Don't replace the cached document with an older version, e.g. returned by a lagging replica
+		if currentUpdated, newUpdated := updatedAt(current), updatedAt(entry); currentUpdated != nil && newUpdated != nil && newUpdated.Before(*currentUpdated) {
+			current.expires = entry.expires
+			c.lru.MoveToFront(element)
+			f.document, f.metadata = current.document, current.metadata
+			return
+		}
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[inputDID] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.config.MaxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).did)
+	}
+}
+
+func (c *CachingResolver) invalidate(inputDID string) {
+	if element, ok := c.entries[inputDID]; ok {
+		c.lru.Remove(element)
+		delete(c.entries, inputDID)
+	}
+	// Results of in-progress resolutions might predate the invalidation, so they must not be cached or shared.
+	if f, ok := c.flights[inputDID]; ok {
+		f.invalidated = true
+		delete(c.flights, inputDID)
+	}
+}
+
+func updatedAt(entry *cacheEntry) *time.Time {
+	if entry.metadata == nil {
+		return nil
+	}
+	return entry.metadata.Updated
+}
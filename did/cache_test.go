@@ -0,0 +1,263 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package did
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubResolver struct {
+	calls    int32
+	release  chan struct{}
+	err      error
+	updated  *time.Time
+	document *Document
+}
+
+func (s *stubResolver) Resolve(inputDID string) (*Document, *DocumentMetadata, error) {
+	atomic.AddInt32(&s.calls, 1)
+	if s.release != nil {
+		<-s.release
+	}
+	if s.err != nil {
+		return nil, nil, s.err
+	}
+	id, _ := ParseDID(inputDID)
+	document := s.document
+	if document == nil {
+		document = &Document{ID: *id}
+	}
+	return document, &DocumentMetadata{Updated: s.updated}, nil
+}
+
+func newTestCache(resolver Resolver, config CacheConfig) (*CachingResolver, *time.Time) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewCachingResolver(resolver, config)
+	cache.now = func() time.Time {
+		return now
+	}
+	return cache, &now
+}
+
+func TestCachingResolver_Resolve(t *testing.T) {
+	const id = "did:ugra:123"
+
+	t.Run("caches until TTL expires", func(t *testing.T) {
+		stub := &stubResolver{}
+		cache, now := newTestCache(stub, CacheConfig{TTL: time.Minute})
+
+		_, _, err := cache.Resolve(id)
+		require.NoError(t, err)
+		document, _, err := cache.Resolve(id)
+		require.NoError(t, err)
+
+		assert.Equal(t, id, document.ID.String())
+		assert.Equal(t, int32(1), stub.calls)
+
+		*now = now.Add(time.Minute)
+		_, _, _ = cache.Resolve(id)
+		assert.Equal(t, int32(2), stub.calls)
+	})
+	t.Run("negative caching", func(t *testing.T) {
+		stub := &stubResolver{err: NotFoundErr}
+		cache, now := newTestCache(stub, CacheConfig{NegativeTTL: time.Second})
+
+		_, _, err := cache.Resolve(id)
+		assert.ErrorIs(t, err, NotFoundErr)
+		_, _, err = cache.Resolve(id)
+		assert.ErrorIs(t, err, NotFoundErr)
+		assert.Equal(t, int32(1), stub.calls)
+
+		*now = now.Add(time.Second)
+		_, _, _ = cache.Resolve(id)
+		assert.Equal(t, int32(2), stub.calls)
+	})
+	t.Run("NotFoundErr isn't cached without NegativeTTL", func(t *testing.T) {
+		stub := &stubResolver{err: NotFoundErr}
+		cache, _ := newTestCache(stub, CacheConfig{})
+
+		_, _, _ = cache.Resolve(id)
+		_, _, _ = cache.Resolve(id)
+
+		assert.Equal(t, int32(2), stub.calls)
+	})
+	t.Run("other errors aren't cached", func(t *testing.T) {
+		stub := &stubResolver{err: errors.New("failed")}
+		cache, _ := newTestCache(stub, CacheConfig{NegativeTTL: time.Minute})
+
+		_, _, _ = cache.Resolve(id)
+		_, _, _ = cache.Resolve(id)
+
+		assert.Equal(t, int32(2), stub.calls)
+	})
+	t.Run("evicts least recently used", func(t *testing.T) {
+		stub := &stubResolver{}
+		cache, _ := newTestCache(stub, CacheConfig{MaxEntries: 2})
+
+		_, _, _ = cache.Resolve("did:ugra:1")
+		_, _, _ = cache.Resolve("did:ugra:2")
+		_, _, _ = cache.Resolve("did:ugra:1")
+		_, _, _ = cache.Resolve("did:ugra:3")
+		assert.Equal(t, int32(3), stub.calls)
+
+		_, _, _ = cache.Resolve("did:ugra:1")
+		assert.Equal(t, int32(3), stub.calls)
+		_, _, _ = cache.Resolve("did:ugra:2")
+		assert.Equal(t, int32(4), stub.calls)
+	})
+	t.Run("does not replace document with older version", func(t *testing.T) {
+		newer := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		older := newer.Add(-time.Hour)
+		stub := &stubResolver{updated: &newer}
+		cache, now := newTestCache(stub, CacheConfig{TTL: time.Minute})
+		_, _, _ = cache.Resolve(id)
+
+		stub.updated = &older
+		*now = now.Add(time.Minute)
+		_, metadata, _ := cache.Resolve(id)
+
+		assert.Equal(t, int32(2), stub.calls)
+		assert.Equal(t, newer, *metadata.Updated)
+	})
+	t.Run("concurrent resolutions are de-duplicated", func(t *testing.T) {
+		stub := &stubResolver{release: make(chan struct{})}
+		cache, _ := newTestCache(stub, CacheConfig{})
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _, _ = cache.Resolve(id)
+			}()
+		}
+		time.Sleep(10 * time.Millisecond)
+		close(stub.release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), stub.calls)
+	})
+}
+
+func TestCachingResolver_ResolveContext(t *testing.T) {
+	stub := &stubResolver{release: make(chan struct{})}
+	cache, _ := newTestCache(stub, CacheConfig{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := cache.ResolveContext(ctx, "did:ugra:123", ResolutionOptions{})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, InternalErrorCode, result.ResolutionMetadata.Error)
+	close(stub.release)
+}
+
+func TestCachingResolver_Invalidate(t *testing.T) {
+	const id = "did:ugra:123"
+	updated := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Invalidate", func(t *testing.T) {
+		stub := &stubResolver{}
+		cache, _ := newTestCache(stub, CacheConfig{})
+		_, _, _ = cache.Resolve(id)
+
+		cache.Invalidate(id)
+		_, _, _ = cache.Resolve(id)
+
+		assert.Equal(t, int32(2), stub.calls)
+	})
+	t.Run("InvalidateAll", func(t *testing.T) {
+		stub := &stubResolver{}
+		cache, _ := newTestCache(stub, CacheConfig{})
+		_, _, _ = cache.Resolve(id)
+
+		cache.InvalidateAll()
+		_, _, _ = cache.Resolve(id)
+
+		assert.Equal(t, int32(2), stub.calls)
+	})
+	t.Run("Invalidate during resolution", func(t *testing.T) {
+		stub := &stubResolver{release: make(chan struct{})}
+		cache, _ := newTestCache(stub, CacheConfig{})
+		resolved := make(chan struct{})
+		go func() {
+			_, _, _ = cache.Resolve(id)
+			close(resolved)
+		}()
+		waitForCalls(t, stub, 1)
+
+		cache.Invalidate(id)
+		close(stub.release)
+		<-resolved
+		_, _, _ = cache.Resolve(id)
+
+		assert.Equal(t, int32(2), stub.calls)
+	})
+	t.Run("Invalidate doesn't affect resolution of other DIDs", func(t *testing.T) {
+		const other = "did:ugra:456"
+		stub := &stubResolver{release: make(chan struct{})}
+		cache, _ := newTestCache(stub, CacheConfig{})
+		resolved := make(chan struct{})
+		go func() {
+			_, _, _ = cache.Resolve(other)
+			close(resolved)
+		}()
+		waitForCalls(t, stub, 1)
+
+		cache.Invalidate(id)
+		close(stub.release)
+		<-resolved
+		_, _, _ = cache.Resolve(other)
+
+		assert.Equal(t, int32(1), stub.calls)
+	})
+	t.Run("NotifyUpdated with newer update", func(t *testing.T) {
+		stub := &stubResolver{updated: &updated}
+		cache, _ := newTestCache(stub, CacheConfig{})
+		_, _, _ = cache.Resolve(id)
+
+		cache.NotifyUpdated(id, updated.Add(time.Second))
+		_, _, _ = cache.Resolve(id)
+
+		assert.Equal(t, int32(2), stub.calls)
+	})
+	t.Run("NotifyUpdated with known update", func(t *testing.T) {
+		stub := &stubResolver{updated: &updated}
+		cache, _ := newTestCache(stub, CacheConfig{})
+		_, _, _ = cache.Resolve(id)
+
+		cache.NotifyUpdated(id, updated)
+		_, _, _ = cache.Resolve(id)
+
+		assert.Equal(t, int32(1), stub.calls)
+	})
+}
+
+// waitForCalls waits until the stub resolver was invoked the given number of times.
+func waitForCalls(t *testing.T, stub *stubResolver, calls int32) {
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&stub.calls) == calls
+	}, time.Second, time.Millisecond)
+}
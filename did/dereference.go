@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package did
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// InvalidDIDURLErr indicates: "The DID URL supplied to the DID URL dereferencing function does not conform to valid syntax."
+const InvalidDIDURLErr = constError("supplied DID URL is invalid")
+
+const (
+	serviceParameter     = "service"
+	relativeRefParameter = "relativeRef"
+	versionIDParameter   = "versionId"
+	versionTimeParameter = "versionTime"
+)
+
+// Dereferencer dereferences DID URLs to the resource they identify, as specified by the DID Resolution specification
+// (https://w3c-ccg.github.io/did-resolution/#dereferencing). It resolves the DID document using the Resolver.
+type Dereferencer struct {
+	Resolver Resolver
+}
+
+// DereferenceResult holds the resource a DID URL was dereferenced to. Exactly one of Document, VerificationMethod and
+// Service is set, depending on the DID URL. URL is set in addition to Service when the DID URL selects a service
+// endpoint using the service parameter.
+type DereferenceResult struct {
+	// Document is the DID document, when the DID URL identifies the DID document itself.
+	Document *Document
+	// VerificationMethod is the verification method the DID URL's fragment refers to.
+	VerificationMethod *VerificationMethod
+	// Service is the service the DID URL's fragment or service parameter refers to.
+	Service *Service
+	// URL is the selected service endpoint, with the relativeRef parameter and fragment applied.
+	URL *url.URL
+	// DocumentMetadata is the metadata of the DID document the resource was taken from.
+	DocumentMetadata DocumentMetadata
+}
+
+// Dereference dereferences the given DID URL. Besides errors returned by the Resolver, it returns
+// InvalidDIDURLErr when the DID URL can't be parsed or its parameters are invalid, and NotFoundErr when the
+// referenced resource doesn't exist in the DID document.
+// Supported are DID URLs with a fragment (e.g. did:ugra:abc#key-1) and with the service and optional relativeRef
+// parameters (e.g. did:ugra:abc?service=files&relativeRef=/resume.pdf). The versionId and versionTime parameters
+// select the version of the DID document to dereference from.
+func (d Dereferencer) Dereference(ctx context.Context, didURL string) (*DereferenceResult, error) {
+	parsed, err := ParseDIDURL(didURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", InvalidDIDURLErr, err)
+	}
+	query, err := url.ParseQuery(parsed.Query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid query: %s", InvalidDIDURLErr, err)
+	}
+	if parsed.Path != "" || len(parsed.PathSegments) > 0 {
+		return nil, fmt.Errorf("%w: DID URL paths are not supported", NotFoundErr)
+	}
+
+	id := DID{}
+	id.Method = parsed.Method
+	id.ID = parsed.ID
+	id.IDStrings = parsed.IDStrings
+	options := ResolutionOptions{VersionID: query.Get(versionIDParameter)}
+	if query.Has(versionTimeParameter) {
+		versionTime, err := time.Parse(time.RFC3339, query.Get(versionTimeParameter))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid %s: %s", InvalidDIDURLErr, versionTimeParameter, err)
+		}
+		options.VersionTime = &versionTime
+	}
+	resolution, err := NewContextResolver(d.Resolver).ResolveContext(ctx, id.String(), options)
+	if err != nil {
+		return nil, err
+	}
+	document := resolution.Document
+	result := &DereferenceResult{DocumentMetadata: resolution.DocumentMetadata}
+
+	if query.Has(serviceParameter) {
+		service := findService(document, query.Get(serviceParameter))
+		if service == nil {
+			return nil, fmt.Errorf("%w: service %s", NotFoundErr, query.Get(serviceParameter))
+		}
+		endpointURL, err := selectServiceEndpoint(*service, query.Get(relativeRefParameter), parsed.Fragment)
+		if err != nil {
+			return nil, err
+		}
+		result.Service = service
+		result.URL = endpointURL
+		return result, nil
+	}
+	if query.Has(relativeRefParameter) {
+		return nil, fmt.Errorf("%w: %s parameter requires %s parameter", InvalidDIDURLErr, relativeRefParameter, serviceParameter)
+	}
+
+	if parsed.Fragment == "" {
+		result.Document = document
+		return result, nil
+	}
+	vmID := id
+	vmID.Fragment = parsed.Fragment
+	if vm := document.VerificationMethod.FindByID(vmID); vm != nil {
+		result.VerificationMethod = vm
+		return result, nil
+	}
+	if service := findService(document, parsed.Fragment); service != nil {
+		result.Service = service
+		return result, nil
+	}
+	return nil, fmt.Errorf("%w: %s", NotFoundErr, didURL)
+}
+
+// findService finds the service whose ID has the given fragment. The service ID may be absolute (did:ugra:abc#files)
+// or relative to the DID document (#files).
+func findService(document *Document, fragment string) *Service {
+	for i, service := range document.Service {
+		if service.ID.Fragment != fragment {
+			continue
+		}
+		if service.ID.Scheme == "" && service.ID.Opaque == "" && service.ID.Path == "" ||
+			strings.HasPrefix(service.ID.String(), document.ID.String()+"#") {
+			return &document.Service[i]
+		}
+	}
+	return nil
+}
+
+// selectServiceEndpoint returns the service endpoint URL, resolved against the given relative reference as specified
+// by RFC3986 (section 5.2). If the DID URL has a fragment, it is set on the resulting URL.
+func selectServiceEndpoint(service Service, relativeRef string, fragment string) (*url.URL, error) {
+	var endpoint string
+	if err := service.UnmarshalServiceEndpoint(&endpoint); err != nil {
+		var endpoints []string
+		if err := service.UnmarshalServiceEndpoint(&endpoints); err != nil || len(endpoints) == 0 {
+			return nil, fmt.Errorf("%w: service endpoint of %s is not a URL", NotFoundErr, service.ID.String())
+		}
+		endpoint = endpoints[0]
+	}
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: service endpoint of %s is not a URL: %s", NotFoundErr, service.ID.String(), err)
+	}
+	if relativeRef != "" {
+		reference, err := url.Parse(relativeRef)
+		if err != nil || reference.IsAbs() {
+			return nil, fmt.Errorf("%w: %s must be a relative reference", InvalidDIDURLErr, relativeRefParameter)
+		}
+		endpointURL = endpointURL.ResolveReference(reference)
+	}
+	if fragment != "" {
+		endpointURL.Fragment = fragment
+	}
+	return endpointURL, nil
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package did
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDereferenceDocument = `{
+  "@context": "https://www.w3.org/ns/did/v1",
+  "id": "did:ugra:123",
+  "verificationMethod": [{
+    "id": "did:ugra:123#key-1",
+    "type": "Ed25519VerificationKey2018",
+    "controller": "did:ugra:123",
+    "publicKeyBase58": "B12NYF8RrR3h41TDCTJojY59usg3mbtbjnFs7Eud1Y6u"
+  }],
+  "service": [{
+    "id": "#files",
+    "type": "LinkedDomains",
+    "serviceEndpoint": "https://example.com/files/"
+  }, {
+    "id": "did:ugra:123#hub",
+    "type": "IdentityHub",
+    "serviceEndpoint": ["https://hub.example.com/", "https://backup.example.com/"]
+  }]
+}`
+
+func TestDereferencer_Dereference(t *testing.T) {
+	document := &Document{}
+	require.NoError(t, json.Unmarshal([]byte(testDereferenceDocument), document))
+	dereferencer := Dereferencer{Resolver: &stubResolver{document: document}}
+	ctx := context.Background()
+
+	t.Run("DID document", func(t *testing.T) {
+		result, err := dereferencer.Dereference(ctx, "did:ugra:123")
+
+		require.NoError(t, err)
+		assert.Same(t, document, result.Document)
+		assert.Nil(t, result.VerificationMethod)
+		assert.Nil(t, result.Service)
+	})
+	t.Run("fragment refers to verification method", func(t *testing.T) {
+		result, err := dereferencer.Dereference(ctx, "did:ugra:123#key-1")
+
+		require.NoError(t, err)
+		require.NotNil(t, result.VerificationMethod)
+		assert.Equal(t, "did:ugra:123#key-1", result.VerificationMethod.ID.String())
+		assert.Nil(t, result.Document)
+		assert.Nil(t, result.Service)
+	})
+	t.Run("fragment refers to service", func(t *testing.T) {
+		result, err := dereferencer.Dereference(ctx, "did:ugra:123#files")
+
+		require.NoError(t, err)
+		require.NotNil(t, result.Service)
+		assert.Equal(t, "LinkedDomains", result.Service.Type)
+		assert.Nil(t, result.URL)
+		assert.Nil(t, result.VerificationMethod)
+	})
+	t.Run("unknown fragment", func(t *testing.T) {
+		_, err := dereferencer.Dereference(ctx, "did:ugra:123#key-2")
+
+		assert.ErrorIs(t, err, NotFoundErr)
+	})
+	t.Run("service", func(t *testing.T) {
+		result, err := dereferencer.Dereference(ctx, "did:ugra:123?service=hub")
+
+		require.NoError(t, err)
+		require.NotNil(t, result.Service)
+		assert.Equal(t, "IdentityHub", result.Service.Type)
+		require.NotNil(t, result.URL)
+		assert.Equal(t, "https://hub.example.com/", result.URL.String())
+	})
+	t.Run("service with relativeRef and fragment", func(t *testing.T) {
+		result, err := dereferencer.Dereference(ctx, "did:ugra:123?service=files&relativeRef=%2Fresume.pdf#page-1")
+
+		require.NoError(t, err)
+		require.NotNil(t, result.URL)
+		assert.Equal(t, "https://example.com/resume.pdf#page-1", result.URL.String())
+	})
+	t.Run("service with relative path relativeRef", func(t *testing.T) {
+		result, err := dereferencer.Dereference(ctx, "did:ugra:123?service=files&relativeRef=resume.pdf")
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/files/resume.pdf", result.URL.String())
+	})
+	t.Run("absolute relativeRef", func(t *testing.T) {
+		_, err := dereferencer.Dereference(ctx, "did:ugra:123?service=files&relativeRef=https%3A%2F%2Fother.example.com")
+
+		assert.ErrorIs(t, err, InvalidDIDURLErr)
+	})
+	t.Run("unknown service", func(t *testing.T) {
+		_, err := dereferencer.Dereference(ctx, "did:ugra:123?service=unknown")
+
+		assert.ErrorIs(t, err, NotFoundErr)
+	})
+	t.Run("relativeRef without service", func(t *testing.T) {
+		_, err := dereferencer.Dereference(ctx, "did:ugra:123?relativeRef=%2Fresume.pdf")
+
+		assert.ErrorIs(t, err, InvalidDIDURLErr)
+	})
+	t.Run("invalid versionTime", func(t *testing.T) {
+		_, err := dereferencer.Dereference(ctx, "did:ugra:123?versionTime=yesterday")
+
+		assert.ErrorIs(t, err, InvalidDIDURLErr)
+	})
+	t.Run("path", func(t *testing.T) {
+		_, err := dereferencer.Dereference(ctx, "did:ugra:123/path/to/resource")
+
+		assert.ErrorIs(t, err, NotFoundErr)
+	})
+	t.Run("invalid DID URL", func(t *testing.T) {
+		_, err := dereferencer.Dereference(ctx, "not a DID URL")
+
+		assert.ErrorIs(t, err, InvalidDIDURLErr)
+	})
+	t.Run("resolution error", func(t *testing.T) {
+		_, err := Dereferencer{Resolver: &stubResolver{err: NotFoundErr}}.Dereference(ctx, "did:ugra:456#key-1")
+
+		assert.ErrorIs(t, err, NotFoundErr)
+	})
+}
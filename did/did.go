@@ -23,6 +23,7 @@ import (
 	"fmt"
 	ssi "github.com/ugradid/ugradid-common"
 	"net/url"
+	"strings"
 
 	ockamDid "github.com/ockam-network/did"
 )
@@ -66,7 +67,7 @@ func (d *DID) UnmarshalJSON(bytes []byte) error {
 	if err != nil {
 		return ErrInvalidDID.wrap(err)
 	}
-	tmp, err := ockamDid.Parse(didString)
+	tmp, err := parse(didString)
 	if err != nil {
 		return ErrInvalidDID.wrap(err)
 	}
@@ -96,7 +97,7 @@ func (d DID) URI() ssi.URI {
 // https://www.w3.org/TR/did-core/#did-url-syntax
 // A DID URL is a URL that builds on the DID scheme.
 func ParseDIDURL(input string) (*DID, error) {
-	ockDid, err := ockamDid.Parse(input)
+	ockDid, err := parse(input)
 	if err != nil {
 		return nil, ErrInvalidDID.wrap(err)
 	}
@@ -104,6 +105,58 @@ func ParseDIDURL(input string) (*DID, error) {
 	return &DID{DID: *ockDid}, nil
 }
 
+// parse parses the input as DID URL. The underlying parser doesn't accept pct-encoded characters and underscores
+// in the method-specific ID, while DID Core does (e.g. did:web encodes ports as %3A). These characters are masked
+// for the underlying parser, after which the original method-specific ID is restored.
+func parse(input string) (*ockamDid.DID, error) {
+	start, end := methodSpecificIDBounds(input)
+	if start < 0 || !strings.ContainsAny(input[start:end], "%_") {
+		return ockamDid.Parse(input)
+	}
+	masked := []byte(input)
+	for i := start; i < end; i++ {
+		switch masked[i] {
+		case '%':
+			if i+2 >= end || !isHexDigit(masked[i+1]) || !isHexDigit(masked[i+2]) {
+				return nil, fmt.Errorf("invalid pct-encoding at index %d", i)
+			}
+			masked[i] = '.'
+		case '_':
+			masked[i] = '.'
+		}
+	}
+	result, err := ockamDid.Parse(string(masked))
+	if err != nil {
+		return nil, err
+	}
+	result.ID = input[start:end]
+	result.IDStrings = strings.Split(result.ID, ":")
+	return result, nil
+}
+
+// methodSpecificIDBounds returns the start and end index of the method-specific ID in the given DID URL,
+// or -1 if the input doesn't start with a DID scheme and method.
+func methodSpecificIDBounds(input string) (int, int) {
+	const scheme = "did:"
+	if !strings.HasPrefix(input, scheme) {
+		return -1, -1
+	}
+	methodEnd := strings.IndexByte(input[len(scheme):], ':')
+	if methodEnd < 0 {
+		return -1, -1
+	}
+	start := len(scheme) + methodEnd + 1
+	end := strings.IndexAny(input[start:], ";/?#")
+	if end < 0 {
+		return start, len(input)
+	}
+	return start, start + end
+}
+
+func isHexDigit(char byte) bool {
+	return (char >= '0' && char <= '9') || (char >= 'a' && char <= 'f') || (char >= 'A' && char <= 'F')
+}
+
 // ParseDID parses a raw DID.
 // If the input contains a path, query or fragment, use the ParseDIDURL instead.
 // If it can't be parsed, an error is returned.
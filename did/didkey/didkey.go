@@ -0,0 +1,204 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package didkey implements the did:key method (https://w3c-ccg.github.io/did-method-key/).
+package didkey
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/did"
+	"github.com/ugradid/ugradid-common/multiformat"
+)
+
+// MethodName is the DID method name of did:key.
+const MethodName = "key"
+
+const (
+	ed25519Context2018   = "https://w3id.org/security/suites/ed25519-2018/v1"
+	x25519Context2019    = "https://w3id.org/security/suites/x25519-2019/v1"
+	secp256k1Context2019 = "https://w3id.org/security/suites/secp256k1-2019/v1"
+	jws2020Context       = "https://w3id.org/security/suites/jws-2020/v1"
+)
+
+// NewDID creates a did:key DID from the given public key. Supported keys are ed25519.PublicKey,
+// X25519 *ecdh.PublicKey and *ecdsa.PublicKey on the secp256k1, P-256 and P-384 curves.
+func NewDID(publicKey crypto.PublicKey) (*did.DID, error) {
+	encodedKey, err := multiformat.EncodePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return did.ParseDID(fmt.Sprintf("did:%s:%s", MethodName, encodedKey))
+}
+
+// Resolver is a did.Resolver for did:key DIDs. Since the DID itself contains the public key,
+// the DID document is synthesized from it without consulting any registry.
+type Resolver struct{}
+
+// Resolve synthesizes the DID document for the given did:key DID.
+// It returns did.InvalidDIDErr when the DID isn't a valid did:key DID.
+func (r Resolver) Resolve(inputDID string) (*did.Document, *did.DocumentMetadata, error) {
+	id, err := did.ParseDID(inputDID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", did.InvalidDIDErr, err)
+	}
+	if id.Method != MethodName {
+		return nil, nil, fmt.Errorf("%w: not a did:%s DID", did.InvalidDIDErr, MethodName)
+	}
+	encoding, data, err := multiformat.DecodeMultibase(id.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", did.InvalidDIDErr, err)
+	}
+	if encoding != multiformat.Base58BTC {
+		return nil, nil, fmt.Errorf("%w: key must be base58btc encoded", did.InvalidDIDErr)
+	}
+	codec, keyBytes, err := multiformat.SplitCodecPrefix(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", did.InvalidDIDErr, err)
+	}
+	publicKey, err := multiformat.UnmarshalPublicKey(codec, keyBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", did.InvalidDIDErr, err)
+	}
+	document, err := createDocument(*id, publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return document, &did.DocumentMetadata{}, nil
+}
+
+// createDocument creates the DID document for the given did:key DID and the public key it encodes.
+// Signing keys are added to all verification relationships except keyAgreement. For Ed25519 keys the keyAgreement
+// key is derived by converting the key to X25519, X25519 keys are only used for keyAgreement.
+func createDocument(id did.DID, publicKey crypto.PublicKey) (*did.Document, error) {
+	document := &did.Document{
+		Context: []ssi.URI{did.DIDContextV1URI()},
+		ID:      id,
+	}
+	vm, context, err := createVerificationMethod(id, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	document.Context = append(document.Context, context)
+
+	if _, isX25519 := publicKey.(*ecdh.PublicKey); isX25519 {
+		document.AddKeyAgreement(vm)
+		return document, nil
+	}
+	document.AddAuthenticationMethod(vm)
+	document.AddAssertionMethod(vm)
+	document.AddCapabilityInvocation(vm)
+	document.AddCapabilityDelegation(vm)
+
+	if edKey, isEd25519 := publicKey.(ed25519.PublicKey); isEd25519 {
+		xKey, err := ed25519ToX25519(edKey)
+		if err != nil {
+			return nil, err
+		}
+		keyAgreement, context, err := createVerificationMethod(id, xKey)
+		if err != nil {
+			return nil, err
+		}
+		document.Context = append(document.Context, context)
+		document.AddKeyAgreement(keyAgreement)
+	} else {
+		document.AddKeyAgreement(vm)
+	}
+	return document, nil
+}
+
+// createVerificationMethod creates the verification method for the given key, identified by the multibase encoded key
+// as fragment. It also returns the JSON-LD context defining the verification method type.
+func createVerificationMethod(id did.DID, publicKey crypto.PublicKey) (*did.VerificationMethod, ssi.URI, error) {
+	encodedKey, err := multiformat.EncodePublicKey(publicKey)
+	if err != nil {
+		return nil, ssi.URI{}, err
+	}
+	vmID, err := did.ParseDIDURL(fmt.Sprintf("%s#%s", id.String(), encodedKey))
+	if err != nil {
+		return nil, ssi.URI{}, err
+	}
+	var (
+		vm      *did.VerificationMethod
+		context string
+	)
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		vm, err = did.NewVerificationMethod(*vmID, ssi.ED25519VerificationKey2018, id, key)
+		context = ed25519Context2018
+	case *ecdh.PublicKey:
+		vm, err = did.NewVerificationMethod(*vmID, ssi.X25519KeyAgreementKey2019, id, key)
+		context = x25519Context2019
+	case *ecdsa.PublicKey:
+		if key.Curve == elliptic.P256() || key.Curve == elliptic.P384() {
+			vm, err = did.NewVerificationMethod(*vmID, ssi.JsonWebKey2020, id, key)
+			context = jws2020Context
+			break
+		}
+		vm, err = did.NewVerificationMethod(*vmID, ssi.ECDSASECP256K1VerificationKey2019, id, key)
+		context = secp256k1Context2019
+	}
+	if err != nil {
+		return nil, ssi.URI{}, err
+	}
+	contextURI, err := ssi.ParseURI(context)
+	if err != nil {
+		return nil, ssi.URI{}, err
+	}
+	return vm, *contextURI, nil
+}
+
+// curve25519P is the prime 2^255 - 19 of the field underlying both Ed25519 and X25519.
+var curve25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// ed25519ToX25519 converts an Ed25519 public key to its birationally equivalent X25519 public key,
+// using u = (1 + y) / (1 - y) (RFC 7748, section 4.1).
+func ed25519ToX25519(publicKey ed25519.PublicKey) (*ecdh.PublicKey, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 key length: %d", len(publicKey))
+	}
+	// The key is the little-endian encoding of y, where the most significant bit holds the sign of x.
+	yBytes := make([]byte, ed25519.PublicKeySize)
+	for i, b := range publicKey {
+		yBytes[ed25519.PublicKeySize-1-i] = b
+	}
+	yBytes[0] &= 0x7f
+	y := new(big.Int).SetBytes(yBytes)
+
+	one := big.NewInt(1)
+	denominator := new(big.Int).Sub(one, y)
+	denominator.Mod(denominator, curve25519P)
+	if denominator.ModInverse(denominator, curve25519P) == nil {
+		return nil, fmt.Errorf("invalid Ed25519 key: can't be converted to X25519")
+	}
+	u := new(big.Int).Add(one, y)
+	u.Mul(u, denominator)
+	u.Mod(u, curve25519P)
+
+	uBytes := u.FillBytes(make([]byte, 32))
+	for i, j := 0, len(uBytes)-1; i < j; i, j = i+1, j-1 {
+		uBytes[i], uBytes[j] = uBytes[j], uBytes[i]
+	}
+	return ecdh.X25519().NewPublicKey(uBytes)
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package didkey
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/did"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	t.Run("Ed25519 with derived X25519 key agreement", func(t *testing.T) {
+		// Test vector from the did:key specification
+		const input = "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+
+		document, metadata, err := Resolver{}.Resolve(input)
+
+		require.NoError(t, err)
+		assert.NotNil(t, metadata)
+		assert.Equal(t, input, document.ID.String())
+		require.Len(t, document.VerificationMethod, 2)
+		assert.Equal(t, input+"#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", document.VerificationMethod[0].ID.String())
+		assert.Equal(t, ssi.ED25519VerificationKey2018, document.VerificationMethod[0].Type)
+		assert.Equal(t, input+"#z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p", document.VerificationMethod[1].ID.String())
+		assert.Equal(t, ssi.X25519KeyAgreementKey2019, document.VerificationMethod[1].Type)
+		assert.Len(t, document.Authentication, 1)
+		assert.Len(t, document.AssertionMethod, 1)
+		assert.Len(t, document.CapabilityInvocation, 1)
+		assert.Len(t, document.CapabilityDelegation, 1)
+		require.Len(t, document.KeyAgreement, 1)
+		assert.Equal(t, document.VerificationMethod[1], document.KeyAgreement[0].VerificationMethod)
+		assert.NoError(t, did.W3CSpecValidator{}.Validate(*document))
+	})
+	t.Run("document can be marshalled and unmarshalled", func(t *testing.T) {
+		document, _, err := Resolver{}.Resolve("did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK")
+		require.NoError(t, err)
+
+		data, err := json.Marshal(document)
+		require.NoError(t, err)
+		var actual did.Document
+		require.NoError(t, json.Unmarshal(data, &actual))
+
+		assert.Equal(t, document.ID, actual.ID)
+		assert.Len(t, actual.KeyAgreement, 1)
+	})
+	t.Run("invalid DIDs", func(t *testing.T) {
+		for _, input := range []string{
+			"did:web:example.com",
+			"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#key-1",
+			"did:key:6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp",
+			"did:key:z111",
+			"not a DID",
+		} {
+			_, _, err := Resolver{}.Resolve(input)
+			assert.ErrorIs(t, err, did.InvalidDIDErr, input)
+		}
+	})
+}
+
+func TestNewDID(t *testing.T) {
+	edKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	xKey, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	k1Key, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	tests := []struct {
+		name         string
+		key          interface{}
+		expectedType ssi.KeyType
+		relations    int
+	}{
+		{"Ed25519", edKey, ssi.ED25519VerificationKey2018, 2},
+		{"X25519", xKey.PublicKey(), ssi.X25519KeyAgreementKey2019, 1},
+		{"secp256k1", &k1Key.PublicKey, ssi.ECDSASECP256K1VerificationKey2019, 1},
+		{"P-256", &p256Key.PublicKey, ssi.JsonWebKey2020, 1},
+		{"P-384", &p384Key.PublicKey, ssi.JsonWebKey2020, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, err := NewDID(test.key)
+			require.NoError(t, err)
+
+			document, _, err := Resolver{}.Resolve(id.String())
+
+			require.NoError(t, err)
+			require.Len(t, document.VerificationMethod, test.relations)
+			assert.Equal(t, test.expectedType, document.VerificationMethod[0].Type)
+			assert.Len(t, document.KeyAgreement, 1)
+		})
+	}
+	t.Run("unsupported key", func(t *testing.T) {
+		_, err := NewDID("foo")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package didweb implements the did:web method (https://w3c-ccg.github.io/did-method-web/).
+package didweb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ugradid/ugradid-common/did"
+)
+
+// MethodName is the DID method name of did:web.
+const MethodName = "web"
+
+// maxDocumentSize limits the size of fetched DID documents to protect against misbehaving servers.
+const maxDocumentSize = 1024 * 1024
+
+// Resolver is a did.Resolver for did:web DIDs. It fetches the DID document over HTTPS.
+type Resolver struct {
+	// HTTPClient is used to fetch DID documents. If it is nil, http.DefaultClient is used.
+	// It can be set to e.g. the client of a httptest.Server for testing.
+	HTTPClient *http.Client
+}
+
+// NewResolver creates a did:web Resolver with an HTTP client that times out after the given duration.
+func NewResolver(timeout time.Duration) *Resolver {
+	return &Resolver{HTTPClient: &http.Client{Timeout: timeout}}
+}
+
+// URL returns the HTTPS URL the DID document of the given did:web DID is published at:
+// - did:web:example.com resolves to https://example.com/.well-known/did.json
+// - did:web:example.com%3A3000 resolves to https://example.com:3000/.well-known/did.json
+// - did:web:example.com:user:alice resolves to https://example.com/user/alice/did.json
+func URL(id did.DID) (*url.URL, error) {
+	if id.Method != MethodName {
+		return nil, fmt.Errorf("not a did:%s DID", MethodName)
+	}
+	if id.IsURL() {
+		return nil, errors.New("DID can not have path, fragment or query params")
+	}
+	segments := strings.Split(id.ID, ":")
+	host, err := url.PathUnescape(segments[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid host: %w", err)
+	}
+	if host == "" || strings.ContainsAny(host, "/?#@") {
+		return nil, fmt.Errorf("invalid host: %s", host)
+	}
+	path := []string{""}
+	if len(segments) == 1 {
+		path = append(path, ".well-known")
+	}
+	for _, segment := range segments[1:] {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path segment: %w", err)
+		}
+		if unescaped == "" || strings.Contains(unescaped, "/") || unescaped == "." || unescaped == ".." {
+			return nil, fmt.Errorf("invalid path segment: %s", segment)
+		}
+		path = append(path, unescaped)
+	}
+	path = append(path, "did.json")
+	return &url.URL{Scheme: "https", Host: host, Path: strings.Join(path, "/")}, nil
+}
+
+// Resolve fetches the DID document of the given did:web DID. It returns did.InvalidDIDErr when the DID isn't a valid
+// did:web DID, did.NotFoundErr when the server responds with 404 Not Found and did.DeactivatedErr when the server
+// responds with 410 Gone.
+func (r Resolver) Resolve(inputDID string) (*did.Document, *did.DocumentMetadata, error) {
+	return r.resolve(context.Background(), inputDID)
+}
+
+// ResolveContext is like Resolve, but aborts fetching the DID document when the given context is cancelled.
+// Since did:web has no version history, it returns did.InvalidOptionsErr when a specific version is requested.
+func (r Resolver) ResolveContext(ctx context.Context, inputDID string, options did.ResolutionOptions) (*did.ResolutionResult, error) {
+	if options.Versioned() {
+		return did.NewResolutionResult(nil, nil, fmt.Errorf("%w: did:%s does not support versions", did.InvalidOptionsErr, MethodName), options)
+	}
+	start := time.Now()
+	document, metadata, err := r.resolve(ctx, inputDID)
+	result, err := did.NewResolutionResult(document, metadata, err, options)
+	result.ResolutionMetadata.Duration = time.Since(start)
+	return result, err
+}
+
+func (r Resolver) resolve(ctx context.Context, inputDID string) (*did.Document, *did.DocumentMetadata, error) {
+	id, err := did.ParseDID(inputDID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", did.InvalidDIDErr, err)
+	}
+	documentURL, err := URL(*id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", did.InvalidDIDErr, err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, documentURL.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	request.Header.Set("Accept", "application/did+json, application/json")
+	response, err := r.client().Do(request)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to fetch DID document (url=%s): %w", documentURL, err)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil, did.NotFoundErr
+	case http.StatusGone:
+		return nil, nil, did.DeactivatedErr
+	default:
+		return nil, nil, fmt.Errorf("unable to fetch DID document (url=%s): unexpected HTTP status %d", documentURL, response.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxDocumentSize+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read DID document (url=%s): %w", documentURL, err)
+	}
+	if len(data) > maxDocumentSize {
+		return nil, nil, fmt.Errorf("DID document exceeds maximum size of %d bytes (url=%s)", maxDocumentSize, documentURL)
+	}
+	var document did.Document
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, nil, fmt.Errorf("unable to parse DID document (url=%s): %w", documentURL, err)
+	}
+	if !document.ID.Equals(*id) {
+		return nil, nil, fmt.Errorf("DID document ID does not match requested DID (id=%s)", document.ID)
+	}
+
+	metadata := &did.DocumentMetadata{}
+	if lastModified, err := http.ParseTime(response.Header.Get("Last-Modified")); err == nil {
+		metadata.Updated = &lastModified
+	}
+	return &document, metadata, nil
+}
+
+func (r Resolver) client() *http.Client {
+	if r.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return r.HTTPClient
+}
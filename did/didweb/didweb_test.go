@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package didweb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ugradid/ugradid-common/did"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"did:web:example.com", "https://example.com/.well-known/did.json"},
+		{"did:web:example.com%3A3000", "https://example.com:3000/.well-known/did.json"},
+		{"did:web:example.com:user:alice", "https://example.com/user/alice/did.json"},
+		{"did:web:example.com%3A3000:user:alice", "https://example.com:3000/user/alice/did.json"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			id, err := did.ParseDID(test.input)
+			require.NoError(t, err)
+
+			actual, err := URL(*id)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, actual.String())
+		})
+	}
+	t.Run("invalid path segment", func(t *testing.T) {
+		id, _ := did.ParseDID("did:web:example.com:user%2Falice")
+
+		_, err := URL(*id)
+
+		assert.Error(t, err)
+	})
+	t.Run("other method", func(t *testing.T) {
+		id, _ := did.ParseDID("did:ugra:abc")
+
+		_, err := URL(*id)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	var (
+		status   = http.StatusOK
+		document string
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/user/alice/did.json" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/did+json")
+		writer.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+		writer.WriteHeader(status)
+		_, _ = writer.Write([]byte(document))
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	id := fmt.Sprintf("did:web:%s:user:alice", strings.ReplaceAll(serverURL.Host, ":", "%3A"))
+	resolver := Resolver{HTTPClient: server.Client()}
+
+	t.Run("ok", func(t *testing.T) {
+		status = http.StatusOK
+		document = fmt.Sprintf(`{"@context": "https://www.w3.org/ns/did/v1", "id": "%s"}`, id)
+
+		actual, metadata, err := resolver.Resolve(id)
+
+		require.NoError(t, err)
+		assert.Equal(t, id, actual.ID.String())
+		require.NotNil(t, metadata.Updated)
+		assert.Equal(t, 2015, metadata.Updated.Year())
+	})
+	t.Run("ID mismatch", func(t *testing.T) {
+		status = http.StatusOK
+		document = `{"@context": "https://www.w3.org/ns/did/v1", "id": "did:web:example.com"}`
+
+		_, _, err := resolver.Resolve(id)
+
+		assert.EqualError(t, err, "DID document ID does not match requested DID (id=did:web:example.com)")
+	})
+	t.Run("invalid document", func(t *testing.T) {
+		status = http.StatusOK
+		document = `[]`
+
+		_, _, err := resolver.Resolve(id)
+
+		assert.Error(t, err)
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, _, err := resolver.Resolve(id + ":other")
+
+		assert.ErrorIs(t, err, did.NotFoundErr)
+	})
+	t.Run("deactivated", func(t *testing.T) {
+		status = http.StatusGone
+
+		_, _, err := resolver.Resolve(id)
+
+		assert.ErrorIs(t, err, did.DeactivatedErr)
+	})
+	t.Run("server error", func(t *testing.T) {
+		status = http.StatusInternalServerError
+
+		_, _, err := resolver.Resolve(id)
+
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, did.NotFoundErr)
+	})
+	t.Run("invalid DID", func(t *testing.T) {
+		_, _, err := resolver.Resolve("did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK")
+
+		assert.ErrorIs(t, err, did.InvalidDIDErr)
+	})
+}
+
+func TestResolver_ResolveContext(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		<-request.Context().Done()
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	id := fmt.Sprintf("did:web:%s", strings.ReplaceAll(serverURL.Host, ":", "%3A"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	result, err := Resolver{HTTPClient: server.Client()}.ResolveContext(ctx, id, did.ResolutionOptions{})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, result.Document)
+	assert.Equal(t, did.InternalErrorCode, result.ResolutionMetadata.Error)
+}
@@ -20,11 +20,15 @@ package did
 import (
 	"crypto"
 	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	ssi "github.com/ugradid/ugradid-common"
 	"github.com/ugradid/ugradid-common/marshal"
+	"github.com/ugradid/ugradid-common/multiformat"
 
 	"github.com/lestrrat-go/jwx/jwk"
 	"github.com/shengdoushi/base58"
@@ -318,6 +322,11 @@ type VerificationMethod struct {
 	Controller      DID                    `json:"controller,omitempty"`
 	PublicKeyBase58 string                 `json:"publicKeyBase58,omitempty"`
 	PublicKeyJwk    map[string]interface{} `json:"publicKeyJwk,omitempty"`
+	// PublicKeyMultibase holds the multibase encoded public key. Depending on the key type, the key bytes are
+	// prefixed with their multicodec.
+	PublicKeyMultibase string `json:"publicKeyMultibase,omitempty"`
+	// PublicKeyPem holds the PEM encoded public key, e.g. of RSA keys.
+	PublicKeyPem string `json:"publicKeyPem,omitempty"`
 }
 
 // NewVerificationMethod is a convenience method to easily create verificationMethods based on a set of given params.
@@ -330,22 +339,10 @@ func NewVerificationMethod(id DID, keyType ssi.KeyType, controller DID, key cryp
 	}
 
 	if keyType == ssi.JsonWebKey2020 {
-		keyAsJWK, err := jwk.New(key)
+		keyAsMap, err := PublicKeyJWK(key)
 		if err != nil {
 			return nil, err
 		}
-		// Convert to JSON and back to fix encoding of key material to make sure
-		// an unmarshalled and newly created VerificationMethod are equal on object level.
-		// The format of PublicKeyJwk in verificationMethod is a map[string]interface{}.
-		// We can't use the Key.AsMap since the values of the map will all be internal jwk lib structs.
-		// After unmarshalling all the fields will be map[string]string.
-		keyAsJSON, err := json.Marshal(keyAsJWK)
-		if err != nil {
-			return nil, err
-		}
-		keyAsMap := map[string]interface{}{}
-		json.Unmarshal(keyAsJSON, &keyAsMap)
-
 		vm.PublicKeyJwk = keyAsMap
 	}
 	if keyType == ssi.ED25519VerificationKey2018 {
@@ -356,10 +353,57 @@ func NewVerificationMethod(id DID, keyType ssi.KeyType, controller DID, key cryp
 		encodedKey := base58.Encode(ed25519Key, base58.BitcoinAlphabet)
 		vm.PublicKeyBase58 = encodedKey
 	}
+	if keyType == ssi.RSAVerificationKey2018 {
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("wrong key type")
+		}
+		der, err := x509.MarshalPKIXPublicKey(rsaKey)
+		if err != nil {
+			return nil, err
+		}
+		vm.PublicKeyPem = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+	if codec, ok := keyTypeCodecs[keyType]; ok && codec != multiformat.Ed25519Pub {
+		actualCodec, keyBytes, err := multiformat.MarshalPublicKey(key)
+		if err != nil || actualCodec != codec {
+			return nil, errors.New("wrong key type")
+		}
+		vm.PublicKeyBase58 = base58.Encode(keyBytes, base58.BitcoinAlphabet)
+	}
+	if codec, ok := multikeyTypeCodecs[keyType]; ok {
+		actualCodec, _, err := multiformat.MarshalPublicKey(key)
+		if err != nil || (codec != anyCodec && actualCodec != codec) {
+			return nil, errors.New("wrong key type")
+		}
+		if vm.PublicKeyMultibase, err = multiformat.EncodePublicKey(key); err != nil {
+			return nil, err
+		}
+	}
 
 	return vm, nil
 }
 
+// keyTypeCodecs maps verification method types of a single key type to the multicodec of that key type.
+var keyTypeCodecs = map[ssi.KeyType]multiformat.Codec{
+	ssi.ED25519VerificationKey2018:        multiformat.Ed25519Pub,
+	ssi.X25519KeyAgreementKey2019:         multiformat.X25519Pub,
+	ssi.ECDSASECP256K1VerificationKey2019: multiformat.Secp256k1Pub,
+	ssi.ECDSASECP256R1VerificationKey2019: multiformat.P256Pub,
+	ssi.Bls12381G2Key2020:                 multiformat.BLS12381G2Pub,
+}
+
+// anyCodec indicates a verification method type supports keys of any multicodec.
+const anyCodec = multiformat.Codec(0)
+
+// multikeyTypeCodecs maps verification method types holding a multicodec prefixed publicKeyMultibase to the
+// multicodec of the key type they support.
+var multikeyTypeCodecs = map[ssi.KeyType]multiformat.Codec{
+	ssi.Multikey:                   anyCodec,
+	ssi.ED25519VerificationKey2020: multiformat.Ed25519Pub,
+	ssi.X25519KeyAgreementKey2020:  multiformat.X25519Pub,
+}
+
 // JWK returns the key described by the VerificationMethod as JSON Web Key.
 func (v VerificationMethod) JWK() (jwk.Key, error) {
 	if v.PublicKeyJwk == nil {
@@ -373,27 +417,114 @@ func (v VerificationMethod) JWK() (jwk.Key, error) {
 	return key, nil
 }
 
+// PublicKey returns the public key described by the VerificationMethod.
+// For verification method types of a single key type (e.g. EcdsaSecp256k1VerificationKey2019), the key is decoded
+// from publicKeyBase58, publicKeyJwk or publicKeyMultibase. For JsonWebKey2020, it is decoded from publicKeyJwk.
+// RSA keys (RsaVerificationKey2018) are decoded from publicKeyPem or publicKeyJwk. For Multikey,
+// Ed25519VerificationKey2020 and X25519KeyAgreementKey2020, the key is decoded from the multicodec prefixed
+// publicKeyMultibase.
 func (v VerificationMethod) PublicKey() (crypto.PublicKey, error) {
-	var pubKey crypto.PublicKey
 	switch v.Type {
-	case ssi.ED25519VerificationKey2018:
+	case ssi.JsonWebKey2020:
+		return v.jwkPublicKey()
+	case ssi.RSAVerificationKey2018:
+		return v.rsaPublicKey()
+	}
+	if codec, ok := multikeyTypeCodecs[v.Type]; ok {
+		return v.multikeyPublicKey(codec)
+	}
+	codec, ok := keyTypeCodecs[v.Type]
+	if !ok {
+		return nil, errors.New("unsupported verification method type")
+	}
+	switch {
+	case v.PublicKeyBase58 != "":
 		keyBytes, err := base58.Decode(v.PublicKeyBase58, base58.BitcoinAlphabet)
 		if err != nil {
 			return nil, err
 		}
-		return ed25519.PublicKey(keyBytes), err
-	case ssi.JsonWebKey2020:
-		keyAsJWK, err := v.JWK()
+		return multiformat.UnmarshalPublicKey(codec, keyBytes)
+	case v.PublicKeyMultibase != "":
+		_, keyBytes, err := multiformat.DecodeMultibase(v.PublicKeyMultibase)
 		if err != nil {
 			return nil, err
 		}
-		err = keyAsJWK.Raw(&pubKey)
+		// The key bytes may or may not be prefixed with the multicodec
+		if prefixCodec, unprefixed, err := multiformat.SplitCodecPrefix(keyBytes); err == nil && prefixCodec == codec {
+			keyBytes = unprefixed
+		}
+		return multiformat.UnmarshalPublicKey(codec, keyBytes)
+	case v.PublicKeyJwk != nil:
+		key, err := v.jwkPublicKey()
+		if err != nil {
+			return nil, err
+		}
+		if actualCodec, _, err := multiformat.MarshalPublicKey(key); err != nil || actualCodec != codec {
+			return nil, fmt.Errorf("publicKeyJwk does not hold a %s key", codec)
+		}
+		return key, nil
+	}
+	return nil, errors.New("verification method has no public key")
+}
+
+func (v VerificationMethod) multikeyPublicKey(codec multiformat.Codec) (crypto.PublicKey, error) {
+	if v.PublicKeyMultibase == "" {
+		return nil, errors.New("verification method has no publicKeyMultibase")
+	}
+	_, data, err := multiformat.DecodeMultibase(v.PublicKeyMultibase)
+	if err != nil {
+		return nil, err
+	}
+	actualCodec, keyBytes, err := multiformat.SplitCodecPrefix(data)
+	if err != nil {
+		return nil, err
+	}
+	if codec != anyCodec && actualCodec != codec {
+		return nil, fmt.Errorf("%s can't hold a %s key", v.Type, actualCodec)
+	}
+	return multiformat.UnmarshalPublicKey(actualCodec, keyBytes)
+}
+
+func (v VerificationMethod) rsaPublicKey() (*rsa.PublicKey, error) {
+	var key crypto.PublicKey
+	switch {
+	case v.PublicKeyPem != "":
+		block, _ := pem.Decode([]byte(v.PublicKeyPem))
+		if block == nil {
+			return nil, errors.New("invalid publicKeyPem")
+		}
+		var err error
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		default:
+			err = fmt.Errorf("unsupported PEM block type: %s", block.Type)
+		}
 		if err != nil {
+			return nil, fmt.Errorf("invalid publicKeyPem: %w", err)
+		}
+	case v.PublicKeyJwk != nil:
+		var err error
+		if key, err = v.jwkPublicKey(); err != nil {
 			return nil, err
 		}
-		return pubKey, nil
+	default:
+		return nil, errors.New("verification method has no public key")
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an RSA public key: %T", key)
+	}
+	return rsaKey, nil
+}
+
+func (v VerificationMethod) jwkPublicKey() (crypto.PublicKey, error) {
+	if v.PublicKeyJwk == nil {
+		return nil, errors.New("verification method has no publicKeyJwk")
 	}
-	return nil, errors.New("unsupported verification method type")
+	return ParsePublicKeyJWK(v.PublicKeyJwk)
 }
 
 // VerificationRelationship represents the usage of a VerificationMethod e.g. in authentication, assertionMethod, or keyAgreement.
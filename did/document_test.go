@@ -0,0 +1,231 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package did
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/shengdoushi/base58"
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/bbs"
+	"github.com/ugradid/ugradid-common/multiformat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationMethod_PublicKey(t *testing.T) {
+	id, _ := ParseDIDURL("did:example:123#key-1")
+	controller, _ := ParseDID("did:example:123")
+	secp256k1Key, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	// roundTrip marshals and unmarshals the verification method, and returns its public key
+	roundTrip := func(t *testing.T, vm *VerificationMethod) *ecdsa.PublicKey {
+		data, err := json.Marshal(vm)
+		require.NoError(t, err)
+		var actual VerificationMethod
+		require.NoError(t, json.Unmarshal(data, &actual))
+		key, err := actual.PublicKey()
+		require.NoError(t, err)
+		require.IsType(t, &ecdsa.PublicKey{}, key)
+		return key.(*ecdsa.PublicKey)
+	}
+
+	t.Run("secp256k1 - publicKeyBase58", func(t *testing.T) {
+		vm, err := NewVerificationMethod(*id, ssi.ECDSASECP256K1VerificationKey2019, *controller, &secp256k1Key.PublicKey)
+		require.NoError(t, err)
+		keyBytes, _ := base58.Decode(vm.PublicKeyBase58, base58.BitcoinAlphabet)
+		assert.Len(t, keyBytes, 33)
+
+		assert.True(t, secp256k1Key.PublicKey.Equal(roundTrip(t, vm)))
+	})
+	t.Run("secp256k1 - uncompressed publicKeyBase58", func(t *testing.T) {
+		vm := &VerificationMethod{ID: *id, Type: ssi.ECDSASECP256K1VerificationKey2019, Controller: *controller,
+			PublicKeyBase58: base58.Encode(elliptic.Marshal(secp256k1.S256(), secp256k1Key.X, secp256k1Key.Y), base58.BitcoinAlphabet)}
+
+		assert.True(t, secp256k1Key.PublicKey.Equal(roundTrip(t, vm)))
+	})
+	t.Run("secp256k1 - publicKeyJwk", func(t *testing.T) {
+		vm, err := NewVerificationMethod(*id, ssi.JsonWebKey2020, *controller, &secp256k1Key.PublicKey)
+		require.NoError(t, err)
+		assert.Equal(t, "secp256k1", vm.PublicKeyJwk["crv"])
+
+		assert.True(t, secp256k1Key.PublicKey.Equal(roundTrip(t, vm)))
+
+		vm.Type = ssi.ECDSASECP256K1VerificationKey2019
+		assert.True(t, secp256k1Key.PublicKey.Equal(roundTrip(t, vm)))
+	})
+	t.Run("secp256k1 - publicKeyMultibase", func(t *testing.T) {
+		encoded, err := multiformat.EncodePublicKey(&secp256k1Key.PublicKey)
+		require.NoError(t, err)
+		vm := &VerificationMethod{ID: *id, Type: ssi.ECDSASECP256K1VerificationKey2019, Controller: *controller, PublicKeyMultibase: encoded}
+
+		assert.True(t, secp256k1Key.PublicKey.Equal(roundTrip(t, vm)))
+
+		// Without multicodec prefix
+		vm.PublicKeyMultibase, _ = multiformat.EncodeMultibase(multiformat.Base58BTC, elliptic.MarshalCompressed(secp256k1.S256(), secp256k1Key.X, secp256k1Key.Y))
+		assert.True(t, secp256k1Key.PublicKey.Equal(roundTrip(t, vm)))
+	})
+	t.Run("P-256 - publicKeyBase58", func(t *testing.T) {
+		vm, err := NewVerificationMethod(*id, ssi.ECDSASECP256R1VerificationKey2019, *controller, &p256Key.PublicKey)
+		require.NoError(t, err)
+
+		assert.True(t, p256Key.PublicKey.Equal(roundTrip(t, vm)))
+	})
+	t.Run("BLS12-381 G2 - publicKeyBase58", func(t *testing.T) {
+		blsKey, _ := bbs.GenerateKey(rand.Reader)
+		vm, err := NewVerificationMethod(*id, ssi.Bls12381G2Key2020, *controller, blsKey.Public())
+		require.NoError(t, err)
+		assert.NotEmpty(t, vm.PublicKeyBase58)
+
+		data, _ := json.Marshal(vm)
+		var actual VerificationMethod
+		require.NoError(t, json.Unmarshal(data, &actual))
+		key, err := actual.PublicKey()
+		require.NoError(t, err)
+		assert.Equal(t, blsKey.Public(), key)
+
+		_, err = NewVerificationMethod(*id, ssi.Bls12381G2Key2020, *controller, &p256Key.PublicKey)
+		assert.EqualError(t, err, "wrong key type")
+	})
+	t.Run("P-384 - publicKeyJwk", func(t *testing.T) {
+		vm, err := NewVerificationMethod(*id, ssi.JsonWebKey2020, *controller, &p384Key.PublicKey)
+		require.NoError(t, err)
+
+		assert.True(t, p384Key.PublicKey.Equal(roundTrip(t, vm)))
+	})
+	t.Run("RSA - publicKeyPem", func(t *testing.T) {
+		rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+		vm, err := NewVerificationMethod(*id, ssi.RSAVerificationKey2018, *controller, &rsaKey.PublicKey)
+		require.NoError(t, err)
+		assert.Contains(t, vm.PublicKeyPem, "-----BEGIN PUBLIC KEY-----")
+
+		key, err := vm.PublicKey()
+		require.NoError(t, err)
+		assert.True(t, rsaKey.PublicKey.Equal(key))
+
+		// PKCS #1
+		vm.PublicKeyPem = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)}))
+		key, err = vm.PublicKey()
+		require.NoError(t, err)
+		assert.True(t, rsaKey.PublicKey.Equal(key))
+	})
+	t.Run("RSA - publicKeyJwk", func(t *testing.T) {
+		rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+		vm, err := NewVerificationMethod(*id, ssi.JsonWebKey2020, *controller, &rsaKey.PublicKey)
+		require.NoError(t, err)
+
+		vm.Type = ssi.RSAVerificationKey2018
+		key, err := vm.PublicKey()
+
+		require.NoError(t, err)
+		assert.True(t, rsaKey.PublicKey.Equal(key))
+	})
+	t.Run("RSA - not an RSA key", func(t *testing.T) {
+		vm, _ := NewVerificationMethod(*id, ssi.JsonWebKey2020, *controller, &p256Key.PublicKey)
+		vm.Type = ssi.RSAVerificationKey2018
+
+		_, err := vm.PublicKey()
+
+		assert.Error(t, err)
+	})
+	t.Run("Multikey", func(t *testing.T) {
+		for _, key := range []crypto.PublicKey{&secp256k1Key.PublicKey, &p256Key.PublicKey, &p384Key.PublicKey} {
+			vm, err := NewVerificationMethod(*id, ssi.Multikey, *controller, key)
+			require.NoError(t, err)
+			assert.True(t, key.(*ecdsa.PublicKey).Equal(roundTrip(t, vm)))
+		}
+	})
+	t.Run("Multikey - base64url encoded", func(t *testing.T) {
+		publicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+		encoded, _ := multiformat.EncodeMultibase(multiformat.Base64URL, multiformat.AddCodecPrefix(multiformat.Ed25519Pub, publicKey))
+		vm := &VerificationMethod{ID: *id, Type: ssi.Multikey, Controller: *controller, PublicKeyMultibase: encoded}
+
+		key, err := vm.PublicKey()
+
+		require.NoError(t, err)
+		assert.Equal(t, publicKey, key)
+	})
+	t.Run("Ed25519VerificationKey2020", func(t *testing.T) {
+		publicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+		vm, err := NewVerificationMethod(*id, ssi.ED25519VerificationKey2020, *controller, publicKey)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(vm.PublicKeyMultibase, "z6Mk"))
+
+		key, err := vm.PublicKey()
+
+		require.NoError(t, err)
+		assert.Equal(t, publicKey, key)
+		_, err = NewVerificationMethod(*id, ssi.ED25519VerificationKey2020, *controller, &p256Key.PublicKey)
+		assert.Error(t, err)
+	})
+	t.Run("X25519KeyAgreementKey2020", func(t *testing.T) {
+		privateKey, _ := ecdh.X25519().GenerateKey(rand.Reader)
+		vm, err := NewVerificationMethod(*id, ssi.X25519KeyAgreementKey2020, *controller, privateKey.PublicKey())
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(vm.PublicKeyMultibase, "z6LS"))
+
+		key, err := vm.PublicKey()
+
+		require.NoError(t, err)
+		assert.True(t, privateKey.PublicKey().Equal(key))
+
+		// Ed25519 key in X25519 verification method
+		vm.PublicKeyMultibase, _ = multiformat.EncodePublicKey(make(ed25519.PublicKey, ed25519.PublicKeySize))
+		_, err = vm.PublicKey()
+		assert.Error(t, err)
+	})
+	t.Run("key doesn't match type", func(t *testing.T) {
+		_, err := NewVerificationMethod(*id, ssi.ECDSASECP256K1VerificationKey2019, *controller, &p256Key.PublicKey)
+		assert.Error(t, err)
+
+		vm, _ := NewVerificationMethod(*id, ssi.JsonWebKey2020, *controller, &p256Key.PublicKey)
+		vm.Type = ssi.ECDSASECP256K1VerificationKey2019
+		_, err = vm.PublicKey()
+		assert.Error(t, err)
+	})
+	t.Run("invalid secp256k1 JWK", func(t *testing.T) {
+		vm, _ := NewVerificationMethod(*id, ssi.JsonWebKey2020, *controller, &secp256k1Key.PublicKey)
+		vm.PublicKeyJwk["y"] = vm.PublicKeyJwk["x"]
+
+		_, err := vm.PublicKey()
+
+		assert.Error(t, err)
+	})
+	t.Run("no key material", func(t *testing.T) {
+		vm := &VerificationMethod{ID: *id, Type: ssi.ECDSASECP256K1VerificationKey2019, Controller: *controller}
+
+		_, err := vm.PublicKey()
+
+		assert.Error(t, err)
+	})
+}
@@ -28,5 +28,16 @@ const capabilityInvocationKey = "capabilityInvocation"
 const capabilityDelegationKey = "capabilityDelegation"
 const verificationMethodKey = "verificationMethod"
 const serviceEndpointKey = "serviceEndpoint"
+const contentTypeKey = "contentType"
+const errorKey = "error"
+const durationKey = "duration"
+const createdKey = "created"
+const updatedKey = "updated"
+const deactivatedKey = "deactivated"
+const nextUpdateKey = "nextUpdate"
+const versionIDKey = "versionId"
+const nextVersionIDKey = "nextVersionId"
+const equivalentIDKey = "equivalentId"
+const canonicalIDKey = "canonicalId"
 
 var pluralContext = marshal.Plural(contextKey)
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package did
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/lestrrat-go/jwx/jwk"
+)
+
+// secp256k1Curve is the JWK curve name of secp256k1 (RFC 8812).
+const secp256k1Curve = "secp256k1"
+
+// PublicKeyJWK returns the members of the JWK (RFC 7517) of the given public key, as they appear in publicKeyJwk.
+func PublicKeyJWK(key crypto.PublicKey) (map[string]interface{}, error) {
+	if ecKey, ok := key.(*ecdsa.PublicKey); ok && ecKey.Curve == secp256k1.S256() {
+		// secp256k1 isn't supported by the JWK library
+		return secp256k1JWK(ecKey), nil
+	}
+	keyAsJWK, err := jwk.New(key)
+	if err != nil {
+		return nil, err
+	}
+	// Convert to JSON and back to fix encoding of key material to make sure
+	// an unmarshalled and newly created JWK are equal on object level.
+	// We can't use the Key.AsMap since the values of the map will all be internal jwk lib structs.
+	// After unmarshalling all the fields will be map[string]string.
+	keyAsJSON, err := json.Marshal(keyAsJWK)
+	if err != nil {
+		return nil, err
+	}
+	keyAsMap := map[string]interface{}{}
+	if err := json.Unmarshal(keyAsJSON, &keyAsMap); err != nil {
+		return nil, err
+	}
+	return keyAsMap, nil
+}
+
+// ParsePublicKeyJWK returns the public key of the given JWK members.
+func ParsePublicKeyJWK(members map[string]interface{}) (crypto.PublicKey, error) {
+	if members["crv"] == secp256k1Curve {
+		return parseSecp256k1JWK(members)
+	}
+	keyAsJSON, _ := json.Marshal(members)
+	keyAsJWK, err := jwk.ParseKey(keyAsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse public key: %w", err)
+	}
+	var publicKey crypto.PublicKey
+	if err = keyAsJWK.Raw(&publicKey); err != nil {
+		return nil, err
+	}
+	return publicKey, nil
+}
+
+// secp256k1JWK returns the JWK members of the given secp256k1 public key.
+func secp256k1JWK(key *ecdsa.PublicKey) map[string]interface{} {
+	return map[string]interface{}{
+		"kty": "EC",
+		"crv": secp256k1Curve,
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+// parseSecp256k1JWK parses the JWK members of a secp256k1 public key.
+func parseSecp256k1JWK(members map[string]interface{}) (*ecdsa.PublicKey, error) {
+	if members["kty"] != "EC" || members["crv"] != secp256k1Curve {
+		return nil, errors.New("not a secp256k1 JWK")
+	}
+	var coordinates [2]secp256k1.FieldVal
+	for i, name := range []string{"x", "y"} {
+		encoded, _ := members[name].(string)
+		data, err := base64.RawURLEncoding.DecodeString(encoded)
+		if err != nil || len(data) != 32 || coordinates[i].SetByteSlice(data) {
+			return nil, fmt.Errorf("invalid secp256k1 JWK: invalid %s coordinate", name)
+		}
+	}
+	key := secp256k1.NewPublicKey(&coordinates[0], &coordinates[1])
+	if !key.IsOnCurve() {
+		return nil, errors.New("invalid secp256k1 JWK: point is not on curve")
+	}
+	return key.ToECDSA(), nil
+}
@@ -0,0 +1,306 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package did
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ResolutionContextV1 is the JSON-LD context of a DID Resolution Result.
+const ResolutionContextV1 = "https://w3id.org/did-resolution/v1"
+
+const (
+	// MediaTypeDIDJSON is the media type of the JSON representation of a DID document.
+	MediaTypeDIDJSON = "application/did+json"
+	// MediaTypeDIDLDJSON is the media type of the JSON-LD representation of a DID document.
+	MediaTypeDIDLDJSON = "application/did+ld+json"
+)
+
+// Error codes for DID Resolution Metadata as specified by the DID Resolution specification
+// (https://w3c-ccg.github.io/did-resolution/#did-resolution-metadata).
+const (
+	// InvalidDIDErrorCode indicates the DID supplied to the DID resolution function does not conform to valid syntax.
+	InvalidDIDErrorCode = "invalidDid"
+	// InvalidDIDURLErrorCode indicates the DID URL supplied to the DID URL dereferencing function does not conform to valid syntax.
+	InvalidDIDURLErrorCode = "invalidDidUrl"
+	// NotFoundErrorCode indicates the DID resolver was unable to find the DID document resulting from the resolution request.
+	NotFoundErrorCode = "notFound"
+	// MethodNotSupportedErrorCode indicates the DID method of the supplied DID is not supported by the DID resolver.
+	MethodNotSupportedErrorCode = "methodNotSupported"
+	// RepresentationNotSupportedErrorCode indicates the representation requested via the accept option is not supported.
+	RepresentationNotSupportedErrorCode = "representationNotSupported"
+	// InvalidOptionsErrorCode indicates the DID resolution options are invalid or not supported by the DID resolver.
+	InvalidOptionsErrorCode = "invalidOptions"
+	// InternalErrorCode indicates an unexpected error occurred during DID resolution.
+	InternalErrorCode = "internalError"
+)
+
+// RepresentationNotSupportedErr indicates: "This error code is returned if the representation requested via the accept
+// input metadata property is not supported by the DID method and/or DID resolver implementation."
+const RepresentationNotSupportedErr = constError("requested DID document representation is not supported")
+
+// InvalidOptionsErr indicates the DID resolution options are invalid or not supported by the DID resolver,
+// e.g. when requesting a version of the DID document from a resolver that doesn't support versions.
+const InvalidOptionsErr = constError("DID resolution options are invalid")
+
+// ContextResolver defines the interface for DID resolution as specified by the DID Resolution specification
+// (https://w3c-ccg.github.io/did-resolution/#resolving), which supports cancellation through the given context.
+// Existing Resolver implementations can be used as ContextResolver through NewContextResolver.
+type ContextResolver interface {
+	// ResolveContext resolves the given input DID to a ResolutionResult. It always returns a result, which describes
+	// the error in its resolution metadata if resolution failed. The returned error then is the same as returned
+	// by Resolver.Resolve (e.g. NotFoundErr) or the context's error if it was cancelled.
+	ResolveContext(ctx context.Context, inputDID string, options ResolutionOptions) (*ResolutionResult, error)
+}
+
+// ResolutionOptions represents the DID Resolution Options as specified by the DID Resolution specification
+// (https://w3c-ccg.github.io/did-resolution/#did-resolution-options).
+type ResolutionOptions struct {
+	// Accept is the media type of the preferred representation of the DID document. Defaults to MediaTypeDIDJSON.
+	Accept string
+	// VersionID identifies a specific version of the DID document to be resolved.
+	VersionID string
+	// VersionTime requests the version of the DID document that was valid at the given time.
+	VersionTime *time.Time
+}
+
+// Versioned returns whether the options request a specific version of the DID document.
+func (o ResolutionOptions) Versioned() bool {
+	return o.VersionID != "" || o.VersionTime != nil
+}
+
+// ResolutionMetadata represents DID Resolution Metadata as specified by the DID Resolution specification
+// (https://w3c-ccg.github.io/did-resolution/#did-resolution-metadata).
+type ResolutionMetadata struct {
+	// ContentType is the media type of the returned DID document representation.
+	ContentType string
+	// Error is the error code of the resolution, if it failed.
+	Error string
+	// Duration is the time it took to resolve the DID. It is (un)marshalled as milliseconds.
+	Duration time.Duration
+	// Properties contains all other resolution metadata properties.
+	Properties map[string]interface{}
+}
+
+// ResolutionResult represents a DID Resolution Result as specified by the DID Resolution specification
+// (https://w3c-ccg.github.io/did-resolution/#did-resolution-result).
+type ResolutionResult struct {
+	// Document is the resolved DID document. It is nil when resolution failed.
+	Document *Document `json:"didDocument"`
+	// ResolutionMetadata contains metadata about the resolution process.
+	ResolutionMetadata ResolutionMetadata `json:"didResolutionMetadata"`
+	// DocumentMetadata contains metadata about the resolved DID document.
+	DocumentMetadata DocumentMetadata `json:"didDocumentMetadata"`
+}
+
+// NewResolutionResult creates the ResolutionResult for the outcome of Resolver.Resolve. When err is not nil, the
+// result contains the matching error code and err is returned. Otherwise, the content type is set according to the
+// accept option and RepresentationNotSupportedErr is returned when that isn't supported.
+func NewResolutionResult(document *Document, metadata *DocumentMetadata, err error, options ResolutionOptions) (*ResolutionResult, error) {
+	result := &ResolutionResult{}
+	if err == nil {
+		result.ResolutionMetadata.ContentType, err = contentType(options.Accept)
+	}
+	if errors.Is(err, DeactivatedErr) {
+		result.DocumentMetadata.Deactivated = true
+		return result, err
+	}
+	if err != nil {
+		result.ResolutionMetadata.Error = ErrorCode(err)
+		return result, err
+	}
+	result.Document = document
+	if metadata != nil {
+		result.DocumentMetadata = *metadata
+	}
+	return result, nil
+}
+
+// ErrorCode returns the DID Resolution Metadata error code for the given resolution or dereferencing error.
+func ErrorCode(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, InvalidDIDURLErr):
+		return InvalidDIDURLErrorCode
+	case errors.Is(err, InvalidDIDErr) || errors.Is(err, ErrInvalidDID):
+		return InvalidDIDErrorCode
+	case errors.Is(err, NotFoundErr):
+		return NotFoundErrorCode
+	case errors.Is(err, MethodNotSupportedErr):
+		return MethodNotSupportedErrorCode
+	case errors.Is(err, RepresentationNotSupportedErr):
+		return RepresentationNotSupportedErrorCode
+	case errors.Is(err, InvalidOptionsErr):
+		return InvalidOptionsErrorCode
+	default:
+		return InternalErrorCode
+	}
+}
+
+func contentType(accept string) (string, error) {
+	switch accept {
+	case "", "*/*", MediaTypeDIDJSON, "application/json":
+		return MediaTypeDIDJSON, nil
+	case MediaTypeDIDLDJSON:
+		return MediaTypeDIDLDJSON, nil
+	}
+	return "", fmt.Errorf("%w: %s", RepresentationNotSupportedErr, accept)
+}
+
+// NewContextResolver returns a ContextResolver for the given Resolver. If the resolver already implements
+// ContextResolver, it is returned as-is. Otherwise, Resolve is invoked in a separate goroutine so the caller can stop
+// waiting for it when the context is cancelled; the underlying resolution itself can't be aborted.
+// Since Resolver doesn't support resolving specific versions, InvalidOptionsErr is returned when they're requested.
+func NewContextResolver(resolver Resolver) ContextResolver {
+	if contextResolver, ok := resolver.(ContextResolver); ok {
+		return contextResolver
+	}
+	return contextResolverAdapter{resolver: resolver}
+}
+
+type contextResolverAdapter struct {
+	resolver Resolver
+}
+
+func (a contextResolverAdapter) ResolveContext(ctx context.Context, inputDID string, options ResolutionOptions) (*ResolutionResult, error) {
+	if options.Versioned() {
+		return NewResolutionResult(nil, nil, fmt.Errorf("%w: resolver does not support versions", InvalidOptionsErr), options)
+	}
+	start := time.Now()
+	type resolution struct {
+		document *Document
+		metadata *DocumentMetadata
+		err      error
+	}
+	resolved := make(chan resolution, 1)
+	go func() {
+		document, metadata, err := a.resolver.Resolve(inputDID)
+		resolved <- resolution{document: document, metadata: metadata, err: err}
+	}()
+
+	var (
+		result *ResolutionResult
+		err    error
+	)
+	select {
+	case <-ctx.Done():
+		result, err = NewResolutionResult(nil, nil, ctx.Err(), options)
+	case r := <-resolved:
+		result, err = NewResolutionResult(r.document, r.metadata, r.err, options)
+	}
+	result.ResolutionMetadata.Duration = time.Since(start)
+	return result, err
+}
+
+func (r ResolutionResult) MarshalJSON() ([]byte, error) {
+	type alias ResolutionResult
+	return json.Marshal(struct {
+		Context string `json:"@context"`
+		alias
+	}{Context: ResolutionContextV1, alias: alias(r)})
+}
+
+func (m ResolutionMetadata) MarshalJSON() ([]byte, error) {
+	return marshalWithProperties(resolutionMetadataJSON{
+		ContentType: m.ContentType,
+		Error:       m.Error,
+		Duration:    m.Duration.Milliseconds(),
+	}, m.Properties)
+}
+
+func (m *ResolutionMetadata) UnmarshalJSON(b []byte) error {
+	var tmp resolutionMetadataJSON
+	properties, err := unmarshalWithProperties(b, &tmp, contentTypeKey, errorKey, durationKey)
+	if err != nil {
+		return err
+	}
+	*m = ResolutionMetadata{
+		ContentType: tmp.ContentType,
+		Error:       tmp.Error,
+		Duration:    time.Duration(tmp.Duration) * time.Millisecond,
+		Properties:  properties,
+	}
+	return nil
+}
+
+type resolutionMetadataJSON struct {
+	ContentType string `json:"contentType,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Duration    int64  `json:"duration,omitempty"`
+}
+
+func (m DocumentMetadata) MarshalJSON() ([]byte, error) {
+	type alias DocumentMetadata
+	return marshalWithProperties(alias(m), m.Properties)
+}
+
+func (m *DocumentMetadata) UnmarshalJSON(b []byte) error {
+	type alias DocumentMetadata
+	var tmp alias
+	properties, err := unmarshalWithProperties(b, &tmp, createdKey, updatedKey, deactivatedKey, nextUpdateKey,
+		versionIDKey, nextVersionIDKey, equivalentIDKey, canonicalIDKey)
+	if err != nil {
+		return err
+	}
+	*m = DocumentMetadata(tmp)
+	m.Properties = properties
+	return nil
+}
+
+// marshalWithProperties marshals the given struct and adds the given additional properties to the resulting JSON object.
+// Properties never overwrite the struct's fields.
+func marshalWithProperties(value interface{}, properties map[string]interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil || len(properties) == 0 {
+		return data, err
+	}
+	result := make(map[string]interface{}, len(properties))
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	for key, value := range properties {
+		if _, exists := result[key]; !exists {
+			result[key] = value
+		}
+	}
+	return json.Marshal(result)
+}
+
+// unmarshalWithProperties unmarshals the given JSON object into target and returns all properties except the given
+// keys (which map to the target's fields), or nil when there are none.
+func unmarshalWithProperties(data []byte, target interface{}, keys ...string) (map[string]interface{}, error) {
+	if err := json.Unmarshal(data, target); err != nil {
+		return nil, err
+	}
+	properties := make(map[string]interface{})
+	if err := json.Unmarshal(data, &properties); err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		delete(properties, key)
+	}
+	if len(properties) == 0 {
+		return nil, nil
+	}
+	return properties, nil
+}
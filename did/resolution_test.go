@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package did
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewResolutionResult(t *testing.T) {
+	id, _ := ParseDID("did:ugra:123")
+	document := &Document{ID: *id}
+
+	t.Run("ok", func(t *testing.T) {
+		metadata := &DocumentMetadata{VersionID: "1"}
+
+		result, err := NewResolutionResult(document, metadata, nil, ResolutionOptions{})
+
+		require.NoError(t, err)
+		assert.Same(t, document, result.Document)
+		assert.Equal(t, *metadata, result.DocumentMetadata)
+		assert.Equal(t, MediaTypeDIDJSON, result.ResolutionMetadata.ContentType)
+		assert.Empty(t, result.ResolutionMetadata.Error)
+	})
+	t.Run("JSON-LD representation", func(t *testing.T) {
+		result, err := NewResolutionResult(document, nil, nil, ResolutionOptions{Accept: MediaTypeDIDLDJSON})
+
+		require.NoError(t, err)
+		assert.Equal(t, MediaTypeDIDLDJSON, result.ResolutionMetadata.ContentType)
+	})
+	t.Run("representation not supported", func(t *testing.T) {
+		result, err := NewResolutionResult(document, nil, nil, ResolutionOptions{Accept: "application/did+cbor"})
+
+		assert.ErrorIs(t, err, RepresentationNotSupportedErr)
+		assert.Nil(t, result.Document)
+		assert.Equal(t, RepresentationNotSupportedErrorCode, result.ResolutionMetadata.Error)
+	})
+	t.Run("not found", func(t *testing.T) {
+		result, err := NewResolutionResult(nil, nil, NotFoundErr, ResolutionOptions{})
+
+		assert.ErrorIs(t, err, NotFoundErr)
+		assert.Nil(t, result.Document)
+		assert.Equal(t, NotFoundErrorCode, result.ResolutionMetadata.Error)
+		assert.Empty(t, result.ResolutionMetadata.ContentType)
+	})
+	t.Run("deactivated", func(t *testing.T) {
+		result, err := NewResolutionResult(nil, nil, DeactivatedErr, ResolutionOptions{})
+
+		assert.ErrorIs(t, err, DeactivatedErr)
+		assert.True(t, result.DocumentMetadata.Deactivated)
+		assert.Empty(t, result.ResolutionMetadata.Error)
+	})
+}
+
+func TestErrorCode(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected string
+	}{
+		{nil, ""},
+		{InvalidDIDErr, InvalidDIDErrorCode},
+		{ErrInvalidDID, InvalidDIDErrorCode},
+		{InvalidDIDURLErr, InvalidDIDURLErrorCode},
+		{fmt.Errorf("wrapped: %w", NotFoundErr), NotFoundErrorCode},
+		{MethodNotSupportedError{Method: "example"}, MethodNotSupportedErrorCode},
+		{RepresentationNotSupportedErr, RepresentationNotSupportedErrorCode},
+		{InvalidOptionsErr, InvalidOptionsErrorCode},
+		{errors.New("failure"), InternalErrorCode},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, ErrorCode(testCase.err), "%v", testCase.err)
+	}
+}
+
+func TestNewContextResolver(t *testing.T) {
+	const id = "did:ugra:123"
+
+	t.Run("resolves", func(t *testing.T) {
+		resolver := NewContextResolver(&stubResolver{})
+
+		result, err := resolver.ResolveContext(context.Background(), id, ResolutionOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, id, result.Document.ID.String())
+		assert.Equal(t, MediaTypeDIDJSON, result.ResolutionMetadata.ContentType)
+	})
+	t.Run("returns ContextResolver as-is", func(t *testing.T) {
+		resolver := &MultiResolver{}
+
+		assert.Same(t, resolver, NewContextResolver(resolver))
+	})
+	t.Run("cancelled", func(t *testing.T) {
+		stub := &stubResolver{release: make(chan struct{})}
+		defer close(stub.release)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result, err := NewContextResolver(stub).ResolveContext(ctx, id, ResolutionOptions{})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, result.Document)
+		assert.Equal(t, InternalErrorCode, result.ResolutionMetadata.Error)
+	})
+	t.Run("versions not supported", func(t *testing.T) {
+		stub := &stubResolver{}
+
+		result, err := NewContextResolver(stub).ResolveContext(context.Background(), id, ResolutionOptions{VersionID: "1"})
+
+		assert.ErrorIs(t, err, InvalidOptionsErr)
+		assert.Equal(t, InvalidOptionsErrorCode, result.ResolutionMetadata.Error)
+		assert.Equal(t, int32(0), stub.calls)
+	})
+}
+
+func TestResolutionResult_MarshalJSON(t *testing.T) {
+	updated := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := ResolutionResult{
+		ResolutionMetadata: ResolutionMetadata{
+			ContentType: MediaTypeDIDJSON,
+			Duration:    1500 * time.Millisecond,
+			Properties:  map[string]interface{}{"driver": "ugra"},
+		},
+		DocumentMetadata: DocumentMetadata{
+			Updated:    &updated,
+			VersionID:  "2",
+			Properties: map[string]interface{}{"method": map[string]interface{}{"published": true}, "versionId": "ignored"},
+		},
+	}
+
+	data, err := json.Marshal(result)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"@context": "https://w3id.org/did-resolution/v1",
+		"didDocument": null,
+		"didResolutionMetadata": {"contentType": "application/did+json", "duration": 1500, "driver": "ugra"},
+		"didDocumentMetadata": {"updated": "2021-01-01T00:00:00Z", "versionId": "2", "method": {"published": true}}
+	}`, string(data))
+
+	t.Run("unmarshal", func(t *testing.T) {
+		var actual ResolutionResult
+
+		err := json.Unmarshal(data, &actual)
+
+		require.NoError(t, err)
+		assert.Equal(t, result.ResolutionMetadata, actual.ResolutionMetadata)
+		assert.Equal(t, updated, *actual.DocumentMetadata.Updated)
+		assert.Equal(t, "2", actual.DocumentMetadata.VersionID)
+		assert.Equal(t, map[string]interface{}{"method": map[string]interface{}{"published": true}}, actual.DocumentMetadata.Properties)
+	})
+	t.Run("unmarshal without properties", func(t *testing.T) {
+		var metadata DocumentMetadata
+
+		err := json.Unmarshal([]byte(`{"versionId": "1", "deactivated": true}`), &metadata)
+
+		require.NoError(t, err)
+		assert.Equal(t, DocumentMetadata{VersionID: "1", Deactivated: true}, metadata)
+	})
+}
@@ -18,6 +18,8 @@
 package did
 
 import (
+	"context"
+	"fmt"
 	"time"
 )
 
@@ -34,8 +36,29 @@ const (
 	NotFoundErr = constError("supplied DID wasn't found")
 	// DeactivatedErr indicates: The DID supplied to the DID resolution function has been deactivated. (See § 7.2.4 Deactivate .)
 	DeactivatedErr = constError("supplied DID is deactivated")
+	// MethodNotSupportedErr indicates: "The DID method is not supported by the DID resolver."
+	MethodNotSupportedErr = constError("DID method not supported")
 )
 
+// MethodNotSupportedError is returned when resolving a DID of a method no resolver is available for.
+// It matches MethodNotSupportedErr when using errors.Is.
+type MethodNotSupportedError struct {
+	Method string
+}
+
+func (e MethodNotSupportedError) Error() string {
+	return fmt.Sprintf("%s: %s", MethodNotSupportedErr, e.Method)
+}
+
+// Is checks whether the given error is MethodNotSupportedErr or a MethodNotSupportedError
+func (e MethodNotSupportedError) Is(other error) bool {
+	if other == MethodNotSupportedErr {
+		return true
+	}
+	_, ok := other.(MethodNotSupportedError)
+	return ok
+}
+
 // Resolver defines the interface for DID resolution as specified by the DID Core specification (https://www.w3.org/TR/did-core/#did-resolution).
 type Resolver interface {
 	// Resolve tries to resolve the given input DID to its DID Document and Metadata. In addition to errors specific
@@ -44,10 +67,68 @@ type Resolver interface {
 	Resolve(inputDID string) (*Document, *DocumentMetadata, error)
 }
 
-// DocumentMedata represents DID Document Metadata as specified by the DID Core specification (https://www.w3.org/TR/did-core/#did-document-metadata-properties).
+// DocumentMetadata represents DID Document Metadata as specified by the DID Core specification (https://www.w3.org/TR/did-core/#did-document-metadata-properties).
 type DocumentMetadata struct {
-	Created    *time.Time
-	Updated    *time.Time
-	Properties map[string]interface{}
+	// Created is the time the DID was created.
+	Created *time.Time `json:"created,omitempty"`
+	// Updated is the time the resolved version of the DID document was created, unless it is the initial version.
+	Updated *time.Time `json:"updated,omitempty"`
+	// Deactivated indicates the DID has been deactivated.
+	Deactivated bool `json:"deactivated,omitempty"`
+	// NextUpdate is the time the next version of the DID document was created, if the resolved version isn't the latest.
+	NextUpdate *time.Time `json:"nextUpdate,omitempty"`
+	// VersionID identifies the resolved version of the DID document.
+	VersionID string `json:"versionId,omitempty"`
+	// NextVersionID identifies the next version of the DID document, if the resolved version isn't the latest.
+	NextVersionID string `json:"nextVersionId,omitempty"`
+	// EquivalentID contains DIDs that are logically equivalent to the resolved DID, as guaranteed by the DID method.
+	EquivalentID []string `json:"equivalentId,omitempty"`
+	// CanonicalID is the canonical DID of the resolved DID, as guaranteed by the DID method.
+	CanonicalID string `json:"canonicalId,omitempty"`
+	// Properties contains all other metadata properties. They're merged into the JSON object when marshalling.
+	Properties map[string]interface{} `json:"-"`
+}
+
+// MultiResolver is a resolver that routes resolution to the resolver registered for the DID's method.
+// It returns a MethodNotSupportedError for DIDs of methods no resolver is registered for.
+// Resolvers should be registered before the MultiResolver is used, since registering is not safe for concurrent use.
+type MultiResolver struct {
+	Resolvers map[string]Resolver
 }
 
+// Register registers the resolver for the given DID method (e.g. "web" for did:web), replacing any resolver
+// previously registered for that method.
+func (m *MultiResolver) Register(method string, resolver Resolver) {
+	if m.Resolvers == nil {
+		m.Resolvers = make(map[string]Resolver)
+	}
+	m.Resolvers[method] = resolver
+}
+
+func (m MultiResolver) Resolve(inputDID string) (*Document, *DocumentMetadata, error) {
+	resolver, err := m.route(inputDID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resolver.Resolve(inputDID)
+}
+
+func (m MultiResolver) ResolveContext(ctx context.Context, inputDID string, options ResolutionOptions) (*ResolutionResult, error) {
+	resolver, err := m.route(inputDID)
+	if err != nil {
+		return NewResolutionResult(nil, nil, err, options)
+	}
+	return NewContextResolver(resolver).ResolveContext(ctx, inputDID, options)
+}
+
+func (m MultiResolver) route(inputDID string) (Resolver, error) {
+	id, err := ParseDID(inputDID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", InvalidDIDErr, err)
+	}
+	resolver, ok := m.Resolvers[id.Method]
+	if !ok {
+		return nil, MethodNotSupportedError{Method: id.Method}
+	}
+	return resolver, nil
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package did
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contextStubResolver is a stubResolver that also implements ContextResolver.
+type contextStubResolver struct {
+	stubResolver
+	options *ResolutionOptions
+}
+
+func (s *contextStubResolver) ResolveContext(_ context.Context, inputDID string, options ResolutionOptions) (*ResolutionResult, error) {
+	s.options = &options
+	document, metadata, err := s.Resolve(inputDID)
+	return NewResolutionResult(document, metadata, err, options)
+}
+
+func TestMultiResolver_Resolve(t *testing.T) {
+	web := &stubResolver{}
+	ugra := &stubResolver{err: NotFoundErr}
+	resolver := MultiResolver{}
+	resolver.Register("web", web)
+	resolver.Register("ugra", ugra)
+
+	t.Run("routes by method", func(t *testing.T) {
+		document, _, err := resolver.Resolve("did:web:example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, "did:web:example.com", document.ID.String())
+		assert.Equal(t, int32(1), web.calls)
+		assert.Equal(t, int32(0), ugra.calls)
+	})
+	t.Run("returns errors of the routed resolver", func(t *testing.T) {
+		_, _, err := resolver.Resolve("did:ugra:123")
+
+		assert.ErrorIs(t, err, NotFoundErr)
+		assert.Equal(t, int32(1), ugra.calls)
+	})
+	t.Run("method not supported", func(t *testing.T) {
+		_, _, err := resolver.Resolve("did:example:123")
+
+		assert.ErrorIs(t, err, MethodNotSupportedErr)
+		var methodErr MethodNotSupportedError
+		require.True(t, errors.As(err, &methodErr))
+		assert.Equal(t, "example", methodErr.Method)
+		assert.EqualError(t, err, "DID method not supported: example")
+	})
+	t.Run("invalid DID", func(t *testing.T) {
+		_, _, err := resolver.Resolve("not a DID")
+
+		assert.ErrorIs(t, err, InvalidDIDErr)
+	})
+	t.Run("register replaces resolver", func(t *testing.T) {
+		other := &stubResolver{}
+		resolver := MultiResolver{}
+		resolver.Register("web", web)
+		resolver.Register("web", other)
+
+		_, _, err := resolver.Resolve("did:web:example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), other.calls)
+	})
+}
+
+func TestMultiResolver_ResolveContext(t *testing.T) {
+	t.Run("delegates to ContextResolver", func(t *testing.T) {
+		ugra := &contextStubResolver{}
+		resolver := MultiResolver{}
+		resolver.Register("ugra", ugra)
+		options := ResolutionOptions{VersionID: "1"}
+
+		result, err := resolver.ResolveContext(context.Background(), "did:ugra:123", options)
+
+		require.NoError(t, err)
+		assert.Equal(t, "did:ugra:123", result.Document.ID.String())
+		require.NotNil(t, ugra.options)
+		assert.Equal(t, options, *ugra.options)
+	})
+	t.Run("adapts Resolver", func(t *testing.T) {
+		web := &stubResolver{}
+		resolver := MultiResolver{}
+		resolver.Register("web", web)
+
+		result, err := resolver.ResolveContext(context.Background(), "did:web:example.com", ResolutionOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "did:web:example.com", result.Document.ID.String())
+		assert.Equal(t, int32(1), web.calls)
+	})
+	t.Run("method not supported", func(t *testing.T) {
+		result, err := MultiResolver{}.ResolveContext(context.Background(), "did:example:123", ResolutionOptions{})
+
+		assert.ErrorIs(t, err, MethodNotSupportedErr)
+		assert.Equal(t, MethodNotSupportedErrorCode, result.ResolutionMetadata.Error)
+	})
+	t.Run("invalid DID", func(t *testing.T) {
+		result, err := MultiResolver{}.ResolveContext(context.Background(), "not a DID", ResolutionOptions{})
+
+		assert.ErrorIs(t, err, InvalidDIDErr)
+		assert.Equal(t, InvalidDIDErrorCode, result.ResolutionMetadata.Error)
+	})
+}
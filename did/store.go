@@ -0,0 +1,160 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package did
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory store of versioned DID documents, which can be used as Resolver.
+// Each stored DID document becomes a new version of it, identified by a sequence number starting at 1.
+// Through ResolveContext, versions can be resolved using the versionId and versionTime resolution options.
+type MemoryStore struct {
+	mux      sync.RWMutex
+	versions map[string][]storedVersion
+}
+
+type storedVersion struct {
+	document    []byte
+	timestamp   time.Time
+	deactivated bool
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{versions: make(map[string][]storedVersion)}
+}
+
+// Put stores the given DID document as new version, created at the given time. It returns the ID of the new version.
+// An error is returned when the DID is deactivated or the time is before the time of the latest version.
+func (s *MemoryStore) Put(document Document, timestamp time.Time) (string, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+	return s.add(document.ID, storedVersion{document: data, timestamp: timestamp})
+}
+
+// Deactivate deactivates the given DID at the given time, by storing a final version of its DID document
+// marked as deactivated. It returns the ID of that version.
+func (s *MemoryStore) Deactivate(id DID, timestamp time.Time) (string, error) {
+	return s.add(id, storedVersion{timestamp: timestamp, deactivated: true})
+}
+
+func (s *MemoryStore) add(id DID, version storedVersion) (string, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	versions := s.versions[id.String()]
+	if len(versions) == 0 && version.deactivated {
+		return "", NotFoundErr
+	}
+	if len(versions) > 0 {
+		latest := versions[len(versions)-1]
+		if latest.deactivated {
+			return "", DeactivatedErr
+		}
+		if version.timestamp.Before(latest.timestamp) {
+			return "", errors.New("version can't predate the latest version of the DID document")
+		}
+		if version.deactivated {
+			version.document = latest.document
+		}
+	}
+	s.versions[id.String()] = append(versions, version)
+	return strconv.Itoa(len(versions) + 1), nil
+}
+
+// Resolve returns the latest version of the DID document. It returns NotFoundErr when the DID isn't stored and
+// DeactivatedErr when the DID is deactivated.
+func (s *MemoryStore) Resolve(inputDID string) (*Document, *DocumentMetadata, error) {
+	return s.resolve(inputDID, ResolutionOptions{})
+}
+
+// ResolveContext resolves the version of the DID document requested by the versionId or versionTime option, or the
+// latest version if neither is set. Both options can't be used at the same time. Resolving a version that predates
+// deactivation of the DID returns that version.
+func (s *MemoryStore) ResolveContext(_ context.Context, inputDID string, options ResolutionOptions) (*ResolutionResult, error) {
+	document, metadata, err := s.resolve(inputDID, options)
+	return NewResolutionResult(document, metadata, err, options)
+}
+
+func (s *MemoryStore) resolve(inputDID string, options ResolutionOptions) (*Document, *DocumentMetadata, error) {
+	if options.VersionID != "" && options.VersionTime != nil {
+		return nil, nil, fmt.Errorf("%w: versionId and versionTime are mutually exclusive", InvalidOptionsErr)
+	}
+	if _, err := ParseDID(inputDID); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", InvalidDIDErr, err)
+	}
+	s.mux.RLock()
+	versions := s.versions[inputDID]
+	s.mux.RUnlock()
+	if len(versions) == 0 {
+		return nil, nil, NotFoundErr
+	}
+
+	index := len(versions) - 1
+	switch {
+	case options.VersionID != "":
+		versionNumber, err := strconv.Atoi(options.VersionID)
+		if err != nil || versionNumber < 1 || versionNumber > len(versions) {
+			return nil, nil, fmt.Errorf("%w: version %s", NotFoundErr, options.VersionID)
+		}
+		index = versionNumber - 1
+	case options.VersionTime != nil:
+		index = -1
+		for i, version := range versions {
+			if version.timestamp.After(*options.VersionTime) {
+				break
+			}
+			index = i
+		}
+		if index < 0 {
+			return nil, nil, fmt.Errorf("%w: no version at %s", NotFoundErr, options.VersionTime.Format(time.RFC3339))
+		}
+	}
+	version := versions[index]
+	if version.deactivated {
+		return nil, nil, DeactivatedErr
+	}
+
+	document := &Document{}
+	if err := json.Unmarshal(version.document, document); err != nil {
+		return nil, nil, err
+	}
+	created := versions[0].timestamp
+	metadata := &DocumentMetadata{
+		Created:   &created,
+		VersionID: strconv.Itoa(index + 1),
+	}
+	if index > 0 {
+		updated := version.timestamp
+		metadata.Updated = &updated
+	}
+	if index < len(versions)-1 {
+		nextUpdate := versions[index+1].timestamp
+		metadata.NextUpdate = &nextUpdate
+		metadata.NextVersionID = strconv.Itoa(index + 2)
+	}
+	return document, metadata, nil
+}
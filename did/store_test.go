@@ -0,0 +1,147 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package did
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	id, _ := ParseDID("did:ugra:123")
+	controller, _ := ParseDID("did:ugra:456")
+	created := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+	ctx := context.Background()
+
+	newStore := func(t *testing.T) *MemoryStore {
+		store := NewMemoryStore()
+		versionID, err := store.Put(Document{ID: *id}, created)
+		require.NoError(t, err)
+		assert.Equal(t, "1", versionID)
+		versionID, err = store.Put(Document{ID: *id, Controller: []DID{*controller}}, updated)
+		require.NoError(t, err)
+		assert.Equal(t, "2", versionID)
+		return store
+	}
+
+	t.Run("latest version", func(t *testing.T) {
+		document, metadata, err := newStore(t).Resolve(id.String())
+
+		require.NoError(t, err)
+		assert.Len(t, document.Controller, 1)
+		assert.Equal(t, "2", metadata.VersionID)
+		assert.Equal(t, created, *metadata.Created)
+		assert.Equal(t, updated, *metadata.Updated)
+		assert.Nil(t, metadata.NextUpdate)
+		assert.Empty(t, metadata.NextVersionID)
+	})
+	t.Run("versionId", func(t *testing.T) {
+		result, err := newStore(t).ResolveContext(ctx, id.String(), ResolutionOptions{VersionID: "1"})
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Document.Controller)
+		metadata := result.DocumentMetadata
+		assert.Equal(t, "1", metadata.VersionID)
+		assert.Equal(t, created, *metadata.Created)
+		assert.Nil(t, metadata.Updated)
+		assert.Equal(t, updated, *metadata.NextUpdate)
+		assert.Equal(t, "2", metadata.NextVersionID)
+	})
+	t.Run("unknown versionId", func(t *testing.T) {
+		result, err := newStore(t).ResolveContext(ctx, id.String(), ResolutionOptions{VersionID: "3"})
+
+		assert.ErrorIs(t, err, NotFoundErr)
+		assert.Equal(t, NotFoundErrorCode, result.ResolutionMetadata.Error)
+	})
+	t.Run("versionTime", func(t *testing.T) {
+		store := newStore(t)
+		versionTime := updated.Add(-time.Second)
+
+		result, err := store.ResolveContext(ctx, id.String(), ResolutionOptions{VersionTime: &versionTime})
+
+		require.NoError(t, err)
+		assert.Equal(t, "1", result.DocumentMetadata.VersionID)
+		assert.Equal(t, "2", result.DocumentMetadata.NextVersionID)
+
+		result, err = store.ResolveContext(ctx, id.String(), ResolutionOptions{VersionTime: &updated})
+
+		require.NoError(t, err)
+		assert.Equal(t, "2", result.DocumentMetadata.VersionID)
+		assert.Nil(t, result.DocumentMetadata.NextUpdate)
+	})
+	t.Run("versionTime before creation", func(t *testing.T) {
+		versionTime := created.Add(-time.Second)
+
+		_, err := newStore(t).ResolveContext(ctx, id.String(), ResolutionOptions{VersionTime: &versionTime})
+
+		assert.ErrorIs(t, err, NotFoundErr)
+	})
+	t.Run("versionId and versionTime are mutually exclusive", func(t *testing.T) {
+		result, err := newStore(t).ResolveContext(ctx, id.String(), ResolutionOptions{VersionID: "1", VersionTime: &created})
+
+		assert.ErrorIs(t, err, InvalidOptionsErr)
+		assert.Equal(t, InvalidOptionsErrorCode, result.ResolutionMetadata.Error)
+	})
+	t.Run("unknown DID", func(t *testing.T) {
+		_, _, err := newStore(t).Resolve("did:ugra:789")
+
+		assert.ErrorIs(t, err, NotFoundErr)
+	})
+	t.Run("version can't predate latest version", func(t *testing.T) {
+		_, err := newStore(t).Put(Document{ID: *id}, created)
+
+		assert.Error(t, err)
+	})
+	t.Run("deactivate", func(t *testing.T) {
+		store := newStore(t)
+		deactivated := updated.Add(time.Hour)
+
+		versionID, err := store.Deactivate(*id, deactivated)
+
+		require.NoError(t, err)
+		assert.Equal(t, "3", versionID)
+		_, _, err = store.Resolve(id.String())
+		assert.ErrorIs(t, err, DeactivatedErr)
+		result, err := store.ResolveContext(ctx, id.String(), ResolutionOptions{})
+		assert.ErrorIs(t, err, DeactivatedErr)
+		assert.True(t, result.DocumentMetadata.Deactivated)
+
+		t.Run("versions before deactivation can be resolved", func(t *testing.T) {
+			result, err := store.ResolveContext(ctx, id.String(), ResolutionOptions{VersionID: "2"})
+
+			require.NoError(t, err)
+			assert.Equal(t, deactivated, *result.DocumentMetadata.NextUpdate)
+			assert.Equal(t, "3", result.DocumentMetadata.NextVersionID)
+		})
+		t.Run("deactivated DID can't be updated", func(t *testing.T) {
+			_, err := store.Put(Document{ID: *id}, deactivated.Add(time.Hour))
+
+			assert.ErrorIs(t, err, DeactivatedErr)
+		})
+	})
+	t.Run("deactivate unknown DID", func(t *testing.T) {
+		_, err := NewMemoryStore().Deactivate(*id, created)
+
+		assert.ErrorIs(t, err, NotFoundErr)
+	})
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package ugra
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ugradid/ugradid-common/did"
+	"github.com/ugradid/ugradid-common/multiformat"
+)
+
+// ErrInvalidGenesis indicates a genesis (initial) DID document doesn't match its identifier.
+var ErrInvalidGenesis = errors.New("genesis document does not match DID")
+
+// DeriveID derives the method-specific ID of a did:ugra DID from its genesis public key: the hex encoded SHA-256 hash
+// of the multicodec encoded public key. Since the ID is bound to the key, only its holder can create the DID.
+func DeriveID(publicKey crypto.PublicKey) (string, error) {
+	codec, data, err := multiformat.MarshalPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(multiformat.AddCodecPrefix(codec, data))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// NewDID returns the did:ugra DID derived from the given genesis public key.
+func NewDID(publicKey crypto.PublicKey) (*did.DID, error) {
+	id, err := DeriveID(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return did.ParseDID(fmt.Sprintf("did:%s:%s", MethodName, id))
+}
+
+// VerifyGenesis checks the given genesis DID document matches its DID: one of its capabilityInvocation
+// verification methods must hold the public key the DID was derived from.
+func VerifyGenesis(document did.Document) error {
+	_, err := genesisMethod(document)
+	return err
+}
+
+// genesisMethod returns the capabilityInvocation verification method of the genesis document holding the public key
+// the DID was derived from.
+func genesisMethod(document did.Document) (*did.VerificationMethod, error) {
+	if document.ID.Method != MethodName {
+		return nil, fmt.Errorf("%w: not a did:%s DID: %s", ErrInvalidGenesis, MethodName, document.ID)
+	}
+	for _, relationship := range document.CapabilityInvocation {
+		publicKey, err := relationship.PublicKey()
+		if err != nil {
+			continue
+		}
+		if id, err := DeriveID(publicKey); err == nil && id == document.ID.ID {
+			return relationship.VerificationMethod, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no capabilityInvocation method holds the genesis key of %s", ErrInvalidGenesis, document.ID)
+}
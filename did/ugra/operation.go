@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package ugra implements the lifecycle of did:ugra DIDs: signed operations to create, update and deactivate
+// DID documents, and a registry applying them.
+package ugra
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ugradid/ugradid-common/did"
+	"github.com/ugradid/ugradid-common/jcs"
+	"github.com/ugradid/ugradid-common/signature"
+)
+
+// MethodName is the DID method name of did:ugra.
+const MethodName = "ugra"
+
+// OperationType defines the kind of change an Operation makes to a DID.
+type OperationType string
+
+const (
+	// CreateOperation creates a DID with its initial DID document.
+	CreateOperation = OperationType("create")
+	// UpdateOperation replaces the DID document of a DID.
+	UpdateOperation = OperationType("update")
+	// DeactivateOperation deactivates a DID, after which it can't be updated anymore.
+	DeactivateOperation = OperationType("deactivate")
+)
+
+// ErrInvalidOperation indicates an operation is malformed or can't be applied to the current state of the DID.
+var ErrInvalidOperation = errors.New("invalid operation")
+
+// ErrUnauthorized indicates an operation isn't signed by a capabilityInvocation key of the DID document it applies to.
+var ErrUnauthorized = errors.New("operation not authorized")
+
+// Operation is a signed change to a did:ugra DID. Create operations are signed with the genesis key the DID is derived
+// from (see DeriveID), update and deactivate operations with a capabilityInvocation key of the current DID document.
+type Operation struct {
+	// Type defines the kind of operation.
+	Type OperationType `json:"type"`
+	// DID is the DID the operation applies to.
+	DID did.DID `json:"did"`
+	// Document is the new DID document for create and update operations.
+	Document *did.Document `json:"document,omitempty"`
+	// PreviousVersion is the version ID of the DID document an update or deactivate operation applies to.
+	// It prevents operations from being replayed or applied to a different version than intended.
+	PreviousVersion string `json:"previousVersion,omitempty"`
+	// Created is the time the operation was created.
+	Created time.Time `json:"created"`
+	// Proof contains the signature over the operation.
+	Proof *OperationProof `json:"proof,omitempty"`
+}
+
+// OperationProof holds the signature over an Operation.
+type OperationProof struct {
+	// VerificationMethod is the ID of the capabilityInvocation verification method that created the signature.
+	VerificationMethod did.DID `json:"verificationMethod"`
+	// JWS is a JWS with detached payload, over the canonicalized (JCS) operation without its proof.
+	JWS string `json:"jws"`
+}
+
+// NewCreateOperation returns an unsigned operation that creates the DID of the given DID document.
+func NewCreateOperation(document did.Document, created time.Time) Operation {
+	return Operation{Type: CreateOperation, DID: document.ID, Document: &document, Created: created}
+}
+
+// NewUpdateOperation returns an unsigned operation that replaces the given version of the DID document.
+func NewUpdateOperation(document did.Document, previousVersion string, created time.Time) Operation {
+	return Operation{Type: UpdateOperation, DID: document.ID, Document: &document, PreviousVersion: previousVersion, Created: created}
+}
+
+// NewDeactivateOperation returns an unsigned operation that deactivates the DID, of which the given version of the
+// DID document is the latest.
+func NewDeactivateOperation(id did.DID, previousVersion string, created time.Time) Operation {
+	return Operation{Type: DeactivateOperation, DID: id, PreviousVersion: previousVersion, Created: created}
+}
+
+// Sign signs the operation with the given signer, which must hold the private key of the given verification method.
+func (o *Operation) Sign(signer crypto.Signer, verificationMethod did.DID) error {
+	payload, err := o.signingPayload()
+	if err != nil {
+		return err
+	}
+	jws, err := signature.SignDetachedJWS(signer, payload, map[string]interface{}{"kid": verificationMethod.String()})
+	if err != nil {
+		return fmt.Errorf("unable to sign operation: %w", err)
+	}
+	o.Proof = &OperationProof{VerificationMethod: verificationMethod, JWS: jws}
+	return nil
+}
+
+// Verify checks the operation's signature was created by a capabilityInvocation key of the given DID document.
+func (o Operation) Verify(document did.Document) error {
+	if o.Proof == nil {
+		return fmt.Errorf("%w: operation is not signed", ErrUnauthorized)
+	}
+	vm := document.CapabilityInvocation.FindByID(o.Proof.VerificationMethod)
+	if vm == nil {
+		return fmt.Errorf("%w: %s is not a capabilityInvocation method of %s", ErrUnauthorized, o.Proof.VerificationMethod, document.ID)
+	}
+	publicKey, err := vm.PublicKey()
+	if err != nil {
+		return fmt.Errorf("%w: unable to get public key of %s: %s", ErrUnauthorized, vm.ID, err)
+	}
+	payload, err := o.signingPayload()
+	if err != nil {
+		return err
+	}
+	if _, err := signature.VerifyDetachedJWS(o.Proof.JWS, payload, publicKey); err != nil {
+		return fmt.Errorf("%w: %s", ErrUnauthorized, err)
+	}
+	return nil
+}
+
+// validate checks the operation is well-formed.
+func (o Operation) validate() error {
+	if o.DID.Method != MethodName || o.DID.IsURL() {
+		return fmt.Errorf("%w: not a did:%s DID: %s", ErrInvalidOperation, MethodName, o.DID)
+	}
+	switch o.Type {
+	case CreateOperation, UpdateOperation:
+		if o.Document == nil {
+			return fmt.Errorf("%w: %s operation requires a document", ErrInvalidOperation, o.Type)
+		}
+		if !o.Document.ID.Equals(o.DID) {
+			return fmt.Errorf("%w: document ID does not match DID", ErrInvalidOperation)
+		}
+		if err := (did.W3CSpecValidator{}).Validate(*o.Document); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidOperation, err)
+		}
+	case DeactivateOperation:
+		if o.Document != nil {
+			return fmt.Errorf("%w: %s operation can't have a document", ErrInvalidOperation, o.Type)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type: %s", ErrInvalidOperation, o.Type)
+	}
+	if (o.Type == CreateOperation) != (o.PreviousVersion == "") {
+		return fmt.Errorf("%w: previousVersion must be set for update and deactivate operations only", ErrInvalidOperation)
+	}
+	return nil
+}
+
+func (o Operation) signingPayload() ([]byte, error) {
+	unsigned := o
+	unsigned.Proof = nil
+	return jcs.Marshal(unsigned)
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package ugra
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ugradid/ugradid-common/did"
+)
+
+// Registry keeps track of did:ugra DIDs by applying operations to them. It resolves the resulting DID documents,
+// including earlier versions using the versionId and versionTime resolution options.
+// A registry can be kept in memory only (NewRegistry) or backed by a file (OpenFileRegistry).
+type Registry struct {
+	mux   sync.Mutex
+	store *did.MemoryStore
+	file  *os.File
+}
+
+// NewRegistry creates an empty, in-memory Registry.
+func NewRegistry() *Registry {
+	return &Registry{store: did.NewMemoryStore()}
+}
+
+// OpenFileRegistry opens a Registry backed by the file at the given path, which is created if it doesn't exist.
+// The file contains the applied operations as JSON, one per line. On opening, the operations in the file are replayed
+// and operations applied afterwards are appended to it. The registry must be closed after use.
+func OpenFileRegistry(path string) (*Registry, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	registry := NewRegistry()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var operation Operation
+		if err := json.Unmarshal(scanner.Bytes(), &operation); err == nil {
+			_, err = registry.apply(operation)
+		}
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("unable to replay operation on line %d of %s: %w", line, path, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	registry.file = file
+	return registry, nil
+}
+
+// Close closes the file backing the registry, if any.
+func (r *Registry) Close() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// Apply validates the given signed operation against the current state of its DID and applies it.
+// It returns the version ID of the resulting DID document version.
+func (r *Registry) Apply(operation Operation) (string, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if err := r.check(operation); err != nil {
+		return "", err
+	}
+	if r.file != nil {
+		data, err := json.Marshal(operation)
+		if err != nil {
+			return "", err
+		}
+		if _, err := r.file.Write(append(data, '\n')); err != nil {
+			return "", fmt.Errorf("unable to write operation: %w", err)
+		}
+	}
+	return r.persist(operation)
+}
+
+// Resolve resolves the latest version of the DID document of the given DID.
+func (r *Registry) Resolve(inputDID string) (*did.Document, *did.DocumentMetadata, error) {
+	return r.store.Resolve(inputDID)
+}
+
+// ResolveContext resolves the DID document of the given DID, honouring the versionId and versionTime options.
+func (r *Registry) ResolveContext(ctx context.Context, inputDID string, options did.ResolutionOptions) (*did.ResolutionResult, error) {
+	return r.store.ResolveContext(ctx, inputDID, options)
+}
+
+func (r *Registry) apply(operation Operation) (string, error) {
+	if err := r.check(operation); err != nil {
+		return "", err
+	}
+	return r.persist(operation)
+}
+
+// check validates the operation and its signature against the current state of the DID.
+func (r *Registry) check(operation Operation) error {
+	if err := operation.validate(); err != nil {
+		return err
+	}
+	current, metadata, err := r.store.Resolve(operation.DID.String())
+	if operation.Type == CreateOperation {
+		if err == nil || errors.Is(err, did.DeactivatedErr) {
+			return fmt.Errorf("%w: DID already exists: %s", ErrInvalidOperation, operation.DID)
+		}
+		if !errors.Is(err, did.NotFoundErr) {
+			return err
+		}
+		// The DID must be derived from the key that signed its creation, to prevent identifier squatting
+		genesis, err := genesisMethod(*operation.Document)
+		if err != nil {
+			return err
+		}
+		if operation.Proof != nil && !operation.Proof.VerificationMethod.Equals(genesis.ID) {
+			return fmt.Errorf("%w: create operation must be signed with the genesis key (%s)", ErrUnauthorized, genesis.ID)
+		}
+		return operation.Verify(*operation.Document)
+	}
+	if err != nil {
+		return err
+	}
+	if operation.PreviousVersion != metadata.VersionID {
+		return fmt.Errorf("%w: operation applies to version %s, but the current version is %s", ErrInvalidOperation, operation.PreviousVersion, metadata.VersionID)
+	}
+	latest := metadata.Created
+	if metadata.Updated != nil {
+		latest = metadata.Updated
+	}
+	if latest != nil && operation.Created.Before(*latest) {
+		return fmt.Errorf("%w: operation predates the current version", ErrInvalidOperation)
+	}
+	return operation.Verify(*current)
+}
+
+func (r *Registry) persist(operation Operation) (string, error) {
+	if operation.Type == DeactivateOperation {
+		return r.store.Deactivate(operation.DID, operation.Created)
+	}
+	return r.store.Put(*operation.Document, operation.Created)
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package ugra
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/did"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDocument creates a DID document for the given DID with a new capabilityInvocation key.
+func newDocument(t *testing.T, id did.DID) (did.Document, ed25519.PrivateKey) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return newDocumentWithKey(t, id, publicKey), privateKey
+}
+
+func newDocumentWithKey(t *testing.T, id did.DID, publicKey ed25519.PublicKey) did.Document {
+	keyID := id
+	keyID.Fragment = "key-" + hex.EncodeToString(publicKey[:4])
+	vm, err := did.NewVerificationMethod(keyID, ssi.ED25519VerificationKey2018, id, publicKey)
+	require.NoError(t, err)
+	document := did.Document{Context: []ssi.URI{did.DIDContextV1URI()}, ID: id}
+	document.AddCapabilityInvocation(vm)
+	return document
+}
+
+// newGenesisDocument creates a DID document for a new DID derived from its capabilityInvocation key.
+func newGenesisDocument(t *testing.T) (did.Document, ed25519.PrivateKey) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	id, err := NewDID(publicKey)
+	require.NoError(t, err)
+	return newDocumentWithKey(t, *id, publicKey), privateKey
+}
+
+func signCreate(t *testing.T, document did.Document, key ed25519.PrivateKey, created time.Time) Operation {
+	operation := NewCreateOperation(document, created)
+	require.NoError(t, operation.Sign(key, document.CapabilityInvocation[0].ID))
+	return operation
+}
+
+func TestRegistry(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("lifecycle", func(t *testing.T) {
+		registry := NewRegistry()
+		document, key := newGenesisDocument(t)
+		id := document.ID
+		keyID := document.CapabilityInvocation[0].ID
+
+		version, err := registry.Apply(signCreate(t, document, key, start))
+		require.NoError(t, err)
+		assert.Equal(t, "1", version)
+
+		// Rotate key, signed by the current key
+		updated, newKey := newDocument(t, id)
+		newKeyID := updated.CapabilityInvocation[0].ID
+		update := NewUpdateOperation(updated, version, start.Add(time.Hour))
+		require.NoError(t, update.Sign(key, keyID))
+		version, err = registry.Apply(update)
+		require.NoError(t, err)
+		assert.Equal(t, "2", version)
+
+		resolved, metadata, err := registry.Resolve(id.String())
+		require.NoError(t, err)
+		assert.NotNil(t, resolved.CapabilityInvocation.FindByID(newKeyID))
+		assert.Equal(t, "2", metadata.VersionID)
+
+		// Old key is no longer authorized
+		deactivate := NewDeactivateOperation(id, version, start.Add(2*time.Hour))
+		require.NoError(t, deactivate.Sign(key, keyID))
+		_, err = registry.Apply(deactivate)
+		assert.ErrorIs(t, err, ErrUnauthorized)
+
+		require.NoError(t, deactivate.Sign(newKey, newKeyID))
+		version, err = registry.Apply(deactivate)
+		require.NoError(t, err)
+		assert.Equal(t, "3", version)
+
+		_, _, err = registry.Resolve(id.String())
+		assert.ErrorIs(t, err, did.DeactivatedErr)
+		result, err := registry.ResolveContext(context.Background(), id.String(), did.ResolutionOptions{VersionID: "1"})
+		require.NoError(t, err)
+		assert.NotNil(t, result.Document.CapabilityInvocation.FindByID(keyID))
+	})
+	t.Run("create must be self-signed", func(t *testing.T) {
+		document, _ := newGenesisDocument(t)
+		_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		_, err = NewRegistry().Apply(signCreate(t, document, otherKey, start))
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+	t.Run("create must be signed with the genesis key", func(t *testing.T) {
+		// Squatting attempt: the genesis key is included, but the operation is signed with another key
+		document, _ := newGenesisDocument(t)
+		other, otherKey := newDocument(t, document.ID)
+		document.AddCapabilityInvocation(other.CapabilityInvocation[0].VerificationMethod)
+		create := NewCreateOperation(document, start)
+		require.NoError(t, create.Sign(otherKey, other.CapabilityInvocation[0].ID))
+
+		_, err := NewRegistry().Apply(create)
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+	t.Run("create of DID not derived from its key", func(t *testing.T) {
+		document, key := newDocument(t, *mustParseDID("did:ugra:123456"))
+
+		_, err := NewRegistry().Apply(signCreate(t, document, key, start))
+
+		assert.ErrorIs(t, err, ErrInvalidGenesis)
+	})
+	t.Run("create of existing DID", func(t *testing.T) {
+		registry := NewRegistry()
+		document, key := newGenesisDocument(t)
+		create := signCreate(t, document, key, start)
+		_, err := registry.Apply(create)
+		require.NoError(t, err)
+
+		_, err = registry.Apply(create)
+
+		assert.ErrorIs(t, err, ErrInvalidOperation)
+	})
+	t.Run("update of unknown DID", func(t *testing.T) {
+		document, key := newGenesisDocument(t)
+		update := NewUpdateOperation(document, "1", start)
+		require.NoError(t, update.Sign(key, document.CapabilityInvocation[0].ID))
+
+		_, err := NewRegistry().Apply(update)
+
+		assert.ErrorIs(t, err, did.NotFoundErr)
+	})
+	t.Run("update of outdated version", func(t *testing.T) {
+		registry := NewRegistry()
+		document, key := newGenesisDocument(t)
+		_, err := registry.Apply(signCreate(t, document, key, start))
+		require.NoError(t, err)
+		update := NewUpdateOperation(document, "1", start.Add(time.Hour))
+		require.NoError(t, update.Sign(key, document.CapabilityInvocation[0].ID))
+		_, err = registry.Apply(update)
+		require.NoError(t, err)
+
+		_, err = registry.Apply(update)
+
+		assert.ErrorIs(t, err, ErrInvalidOperation)
+	})
+	t.Run("tampered operation", func(t *testing.T) {
+		document, key := newGenesisDocument(t)
+		create := signCreate(t, document, key, start)
+		create.Created = start.Add(time.Second)
+
+		_, err := NewRegistry().Apply(create)
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+	t.Run("invalid operations", func(t *testing.T) {
+		document, key := newDocument(t, *mustParseDID("did:web:example.com"))
+		_, err := NewRegistry().Apply(signCreate(t, document, key, start))
+		assert.ErrorIs(t, err, ErrInvalidOperation)
+
+		document, key = newGenesisDocument(t)
+		document.Context = nil
+		_, err = NewRegistry().Apply(signCreate(t, document, key, start))
+		assert.ErrorIs(t, err, ErrInvalidOperation)
+	})
+}
+
+func TestOpenFileRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.jsonl")
+	document, key := newGenesisDocument(t)
+
+	registry, err := OpenFileRegistry(path)
+	require.NoError(t, err)
+	_, err = registry.Apply(signCreate(t, document, key, time.Now()))
+	require.NoError(t, err)
+	update := NewUpdateOperation(document, "1", time.Now())
+	require.NoError(t, update.Sign(key, document.CapabilityInvocation[0].ID))
+	_, err = registry.Apply(update)
+	require.NoError(t, err)
+	require.NoError(t, registry.Close())
+
+	registry, err = OpenFileRegistry(path)
+	require.NoError(t, err)
+	defer registry.Close()
+	_, metadata, err := registry.Resolve(document.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "2", metadata.VersionID)
+}
+
+func TestVerifyGenesis(t *testing.T) {
+	document, _ := newGenesisDocument(t)
+	assert.NoError(t, VerifyGenesis(document))
+
+	other, _ := newDocument(t, document.ID)
+	assert.ErrorIs(t, VerifyGenesis(other), ErrInvalidGenesis)
+}
+
+func mustParseDID(input string) *did.DID {
+	id, err := did.ParseDID(input)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
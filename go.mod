@@ -1,10 +1,21 @@
 module github.com/ugradid/ugradid-common
 
-go 1.17
+go 1.20
 
 require (
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.2.0
+	github.com/kilic/bls12-381 v0.1.0
 	github.com/lestrrat-go/jwx v1.0.5
 	github.com/ockam-network/did v0.1.4-0.20210103172416-02ae01ce06d8
 	github.com/shengdoushi/base58 v1.0.0
 	github.com/stretchr/testify v1.7.0
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/lestrrat-go/iter v0.0.0-20200422075355-fc1769541911 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.0.0-20201101102859-da207088b7d1 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
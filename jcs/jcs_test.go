@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransform(t *testing.T) {
+	t.Run("RFC 8785 example", func(t *testing.T) {
+		input := `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`
+		expected := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+
+		actual, err := Transform([]byte(input))
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(actual))
+	})
+	t.Run("numbers", func(t *testing.T) {
+		tests := map[string]string{
+			"0":                       "0",
+			"-0":                      "0",
+			"5e-324":                  "5e-324",
+			"-5e-324":                 "-5e-324",
+			"1.7976931348623157e308":  "1.7976931348623157e+308",
+			"9007199254740992":        "9007199254740992",
+			"999999999999999900000":   "999999999999999900000",
+			"1e21":                    "1e+21",
+			"0.000001":                "0.000001",
+			"9.999999999999997e-7":    "9.999999999999997e-7",
+			"100":                     "100",
+			"-1.5":                    "-1.5",
+			"295147905179352830000.0": "295147905179352830000",
+		}
+		for input, expected := range tests {
+			actual, err := Transform([]byte(input))
+			require.NoError(t, err, input)
+			assert.Equal(t, expected, string(actual), input)
+		}
+	})
+	t.Run("properties are sorted by UTF-16 code units", func(t *testing.T) {
+		actual, err := Transform([]byte("{\"\uFB33\": 2, \"\U0001F600\": 1, \"a\": 3}"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "{\"a\":3,\"\U0001F600\":1,\"\uFB33\":2}", string(actual))
+	})
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := Transform([]byte(`{"a": 1} {}`))
+
+		assert.Error(t, err)
+	})
+	t.Run("number out of range", func(t *testing.T) {
+		_, err := Transform([]byte(`1e400`))
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package multiformat
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// ErrUnsupportedKey is returned when a public key or codec can't be converted.
+var ErrUnsupportedKey = errors.New("unsupported public key")
+
+// BLS12381G2PublicKey is a compressed BLS12-381 G2 public key, as used for BBS+ signatures.
+// Only its length is validated when it is unmarshalled.
+type BLS12381G2PublicKey []byte
+
+// bls12381G2PublicKeySize is the size of a compressed BLS12-381 G2 point.
+const bls12381G2PublicKeySize = 96
+
+// MarshalPublicKey returns the multicodec and the raw key bytes for the given public key.
+// Elliptic curve keys are returned in compressed form.
+func MarshalPublicKey(publicKey crypto.PublicKey) (Codec, []byte, error) {
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		return Ed25519Pub, key, nil
+	case BLS12381G2PublicKey:
+		return BLS12381G2Pub, key, nil
+	case *ecdh.PublicKey:
+		if key.Curve() != ecdh.X25519() {
+			break
+		}
+		return X25519Pub, key.Bytes(), nil
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return P256Pub, elliptic.MarshalCompressed(key.Curve, key.X, key.Y), nil
+		case elliptic.P384():
+			return P384Pub, elliptic.MarshalCompressed(key.Curve, key.X, key.Y), nil
+		case secp256k1.S256():
+			return Secp256k1Pub, elliptic.MarshalCompressed(key.Curve, key.X, key.Y), nil
+		}
+	}
+	return 0, nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, publicKey)
+}
+
+// UnmarshalPublicKey parses the raw key bytes as a public key of the given multicodec.
+// Elliptic curve keys may be in compressed or uncompressed form.
+func UnmarshalPublicKey(codec Codec, data []byte) (crypto.PublicKey, error) {
+	switch codec {
+	case Ed25519Pub:
+		if len(data) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid %s key length: %d", codec, len(data))
+		}
+		return ed25519.PublicKey(data), nil
+	case X25519Pub:
+		key, err := ecdh.X25519().NewPublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s key: %w", codec, err)
+		}
+		return key, nil
+	case Secp256k1Pub:
+		key, err := secp256k1.ParsePubKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s key: %w", codec, err)
+		}
+		return key.ToECDSA(), nil
+	case P256Pub:
+		return unmarshalNISTKey(codec, elliptic.P256(), data)
+	case P384Pub:
+		return unmarshalNISTKey(codec, elliptic.P384(), data)
+	case BLS12381G2Pub:
+		if len(data) != bls12381G2PublicKeySize {
+			return nil, fmt.Errorf("invalid %s key length: %d", codec, len(data))
+		}
+		return BLS12381G2PublicKey(data), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, codec)
+}
+
+// EncodePublicKey encodes the public key as base58btc multibase value of its multicodec prefixed bytes,
+// as used by e.g. did:key.
+func EncodePublicKey(publicKey crypto.PublicKey) (string, error) {
+	codec, data, err := MarshalPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return EncodeMultibase(Base58BTC, AddCodecPrefix(codec, data))
+}
+
+// DecodePublicKey decodes a multibase value of multicodec prefixed key bytes to a public key.
+func DecodePublicKey(input string) (crypto.PublicKey, error) {
+	_, data, err := DecodeMultibase(input)
+	if err != nil {
+		return nil, err
+	}
+	codec, keyBytes, err := SplitCodecPrefix(data)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalPublicKey(codec, keyBytes)
+}
+
+func unmarshalNISTKey(codec Codec, curve elliptic.Curve, data []byte) (crypto.PublicKey, error) {
+	var x, y = elliptic.UnmarshalCompressed(curve, data)
+	if x == nil {
+		x, y = elliptic.Unmarshal(curve, data)
+	}
+	if x == nil {
+		return nil, fmt.Errorf("invalid %s key", codec)
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package multiformat
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shengdoushi/base58"
+)
+
+// Encoding identifies a multibase encoding by its prefix character (https://github.com/multiformats/multibase).
+type Encoding byte
+
+const (
+	// Base58BTC is the base58 encoding using the Bitcoin alphabet, prefixed with 'z'.
+	Base58BTC = Encoding('z')
+	// Base64URL is the unpadded base64 encoding using the URL and filename safe alphabet (RFC 4648), prefixed with 'u'.
+	Base64URL = Encoding('u')
+	// Base32 is the unpadded, lowercase base32 encoding (RFC 4648), prefixed with 'b'.
+	Base32 = Encoding('b')
+)
+
+var base32Encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ErrUnsupportedEncoding is returned when a multibase value uses an encoding that isn't supported.
+var ErrUnsupportedEncoding = errors.New("unsupported multibase encoding")
+
+// EncodeMultibase encodes the given data using the given encoding and prepends the encoding's prefix character.
+func EncodeMultibase(encoding Encoding, data []byte) (string, error) {
+	switch encoding {
+	case Base58BTC:
+		return string(encoding) + base58.Encode(data, base58.BitcoinAlphabet), nil
+	case Base64URL:
+		return string(encoding) + base64.RawURLEncoding.EncodeToString(data), nil
+	case Base32:
+		return string(encoding) + strings.ToLower(base32Encoding.EncodeToString(data)), nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedEncoding, encoding)
+}
+
+// DecodeMultibase decodes a multibase encoded value. It returns the encoding that was used and the decoded data.
+func DecodeMultibase(input string) (Encoding, []byte, error) {
+	if len(input) == 0 {
+		return 0, nil, errors.New("empty multibase value")
+	}
+	encoding := Encoding(input[0])
+	switch encoding {
+	case Base58BTC:
+		data, err := base58.Decode(input[1:], base58.BitcoinAlphabet)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid base58btc value: %w", err)
+		}
+		return encoding, data, nil
+	case Base64URL:
+		data, err := base64.RawURLEncoding.DecodeString(input[1:])
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid base64url value: %w", err)
+		}
+		return encoding, data, nil
+	case Base32:
+		if strings.ToLower(input[1:]) != input[1:] {
+			return 0, nil, errors.New("invalid base32 value: must be lowercase")
+		}
+		data, err := base32Encoding.DecodeString(strings.ToUpper(input[1:]))
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid base32 value: %w", err)
+		}
+		return encoding, data, nil
+	}
+	return 0, nil, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, encoding)
+}
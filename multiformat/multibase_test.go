@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package multiformat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultibase(t *testing.T) {
+	// Test vectors from the multibase specification
+	input := []byte("yes mani !")
+	for encoding, expected := range map[Encoding]string{
+		Base58BTC: "z7paNL19xttacUY",
+		Base64URL: "ueWVzIG1hbmkgIQ",
+		Base32:    "bpfsxgidnmfxgsibb",
+	} {
+		t.Run(string(encoding), func(t *testing.T) {
+			encoded, err := EncodeMultibase(encoding, input)
+			require.NoError(t, err)
+			assert.Equal(t, expected, encoded)
+
+			actualEncoding, decoded, err := DecodeMultibase(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, encoding, actualEncoding)
+			assert.Equal(t, input, decoded)
+		})
+	}
+	t.Run("unsupported encoding", func(t *testing.T) {
+		_, _, err := DecodeMultibase("f796573206d616e692021")
+		assert.ErrorIs(t, err, ErrUnsupportedEncoding)
+		_, err = EncodeMultibase(Encoding('f'), input)
+		assert.ErrorIs(t, err, ErrUnsupportedEncoding)
+	})
+	t.Run("invalid values", func(t *testing.T) {
+		for _, input := range []string{"", "z0OIl", "u+/", "bPFSXGIDNMFXGSIBB"} {
+			_, _, err := DecodeMultibase(input)
+			assert.Error(t, err, input)
+		}
+	})
+}
+
+func TestDecodePublicKey(t *testing.T) {
+	t.Run("bls12_381-g2-pub", func(t *testing.T) {
+		// BLS12-381 G2 did:key from the did:key specification
+		const input = "zUC7EK3ZakmukHhuncwkbySmomv3FmrkmS36E4Ks5rsb6VQSRpoCrx6Hb8e2Nk6UvJFSdyw9NK1scFXJp21gNNYFjVWNgaqyGnkyhtagagCpQb5B7tagJu3HDbjQ8h5ypoHjwBb"
+
+		key, err := DecodePublicKey(input)
+
+		require.NoError(t, err)
+		require.IsType(t, BLS12381G2PublicKey{}, key)
+		assert.Len(t, key, 96)
+		encoded, err := EncodePublicKey(key)
+		require.NoError(t, err)
+		assert.Equal(t, input, encoded)
+	})
+	t.Run("unknown codec", func(t *testing.T) {
+		encoded, _ := EncodeMultibase(Base58BTC, AddCodecPrefix(Codec(0x1205), []byte{1, 2, 3}))
+
+		_, err := DecodePublicKey(encoded)
+
+		assert.ErrorIs(t, err, ErrUnsupportedKey)
+	})
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package multiformat
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Codec is a multicodec code as registered in the multicodec table (https://github.com/multiformats/multicodec).
+type Codec uint64
+
+const (
+	// Ed25519Pub is the multicodec for Ed25519 public keys.
+	Ed25519Pub = Codec(0xed)
+	// X25519Pub is the multicodec for Curve25519 (X25519) public keys.
+	X25519Pub = Codec(0xec)
+	// Secp256k1Pub is the multicodec for compressed secp256k1 public keys.
+	Secp256k1Pub = Codec(0xe7)
+	// P256Pub is the multicodec for compressed P-256 public keys.
+	P256Pub = Codec(0x1200)
+	// P384Pub is the multicodec for compressed P-384 public keys.
+	P384Pub = Codec(0x1201)
+	// BLS12381G2Pub is the multicodec for compressed BLS12-381 G2 public keys.
+	BLS12381G2Pub = Codec(0xeb)
+	// SHA2256 is the multicodec for SHA-256 multihashes.
+	SHA2256 = Codec(0x12)
+	// SHA2384 is the multicodec for SHA-384 multihashes.
+	SHA2384 = Codec(0x20)
+	// SHA2512 is the multicodec for SHA-512 multihashes.
+	SHA2512 = Codec(0x13)
+)
+
+var codecNames = map[Codec]string{
+	Ed25519Pub:    "ed25519-pub",
+	X25519Pub:     "x25519-pub",
+	Secp256k1Pub:  "secp256k1-pub",
+	P256Pub:       "p256-pub",
+	P384Pub:       "p384-pub",
+	BLS12381G2Pub: "bls12_381-g2-pub",
+	SHA2256:       "sha2-256",
+	SHA2384:       "sha2-384",
+	SHA2512:       "sha2-512",
+}
+
+// String returns the name of the codec as listed in the multicodec table.
+func (c Codec) String() string {
+	if name, ok := codecNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%x", uint64(c))
+}
+
+// AddCodecPrefix prepends the unsigned varint encoded codec to the given data.
+func AddCodecPrefix(codec Codec, data []byte) []byte {
+	prefix := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(prefix, uint64(codec))
+	return append(prefix[:n], data...)
+}
+
+// SplitCodecPrefix reads the unsigned varint encoded codec from the given data.
+// It returns the codec and the remaining data.
+func SplitCodecPrefix(data []byte) (Codec, []byte, error) {
+	code, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, nil, errors.New("invalid multicodec prefix")
+	}
+	return Codec(code), data[n:], nil
+}
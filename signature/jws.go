@@ -0,0 +1,220 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package signature
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	algorithmHeader = "alg"
+	b64Header       = "b64"
+	critHeader      = "crit"
+)
+
+// supportedCriticalHeaders contains the header parameters this package understands when listed in the crit header.
+var supportedCriticalHeaders = map[string]bool{b64Header: true}
+
+// SignJWS creates a JWS in compact serialization (RFC 7515, section 7.1) over the given payload.
+// The alg header is set according to the signer's key, unless it's present in the given headers.
+func SignJWS(signer crypto.Signer, payload []byte, headers map[string]interface{}) (string, error) {
+	encodedHeaders, algorithm, err := encodeHeaders(signer, headers)
+	if err != nil {
+		return "", err
+	}
+	signingInput := encodedHeaders + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sig, err := Sign(signer, algorithm, []byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// SignDetachedJWS creates a JWS in compact serialization with a detached, unencoded payload (RFC 7797),
+// as used by Linked Data proofs: <headers>..<signature>.
+// The alg header is set according to the signer's key, unless it's present in the given headers.
+func SignDetachedJWS(signer crypto.Signer, payload []byte, headers map[string]interface{}) (string, error) {
+	allHeaders := map[string]interface{}{b64Header: false, critHeader: []string{b64Header}}
+	for key, value := range headers {
+		allHeaders[key] = value
+	}
+	encodedHeaders, algorithm, err := encodeHeaders(signer, allHeaders)
+	if err != nil {
+		return "", err
+	}
+	sig, err := Sign(signer, algorithm, append([]byte(encodedHeaders+"."), payload...))
+	if err != nil {
+		return "", err
+	}
+	return encodedHeaders + ".." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// ParseJWS parses a JWS in compact serialization without verifying it. It returns the protected headers and the payload.
+func ParseJWS(jws string) (map[string]interface{}, []byte, error) {
+	headers, payload, _, _, err := parseJWS(jws)
+	return headers, payload, err
+}
+
+// VerifyJWS verifies a JWS in compact serialization using the given public key.
+// It returns the protected headers and the payload. Unencoded payloads (RFC 7797) are only supported by VerifyDetachedJWS.
+func VerifyJWS(jws string, publicKey crypto.PublicKey) (map[string]interface{}, []byte, error) {
+	headers, payload, signingInput, sig, err := parseJWS(jws)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkCriticalHeaders(headers); err != nil {
+		return nil, nil, err
+	}
+	if encoded, ok := headers[b64Header].(bool); ok && !encoded {
+		return nil, nil, errors.New("invalid JWS: unencoded payload must be detached")
+	}
+	if err := verifyWithHeaders(publicKey, headers, signingInput, sig); err != nil {
+		return nil, nil, err
+	}
+	return headers, payload, nil
+}
+
+// VerifyDetachedJWS verifies a JWS in compact serialization with detached payload, using the given payload and public key.
+// Both encoded and unencoded (RFC 7797) payloads are supported. It returns the protected headers.
+// As required by RFC 7797 (section 6), the b64 header must be listed in the crit header.
+func VerifyDetachedJWS(jws string, payload []byte, publicKey crypto.PublicKey) (map[string]interface{}, error) {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return nil, errors.New("invalid JWS: expected detached payload")
+	}
+	headers, err := decodeHeaders(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCriticalHeaders(headers); err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWS signature: %w", err)
+	}
+	signingInput := []byte(parts[0] + ".")
+	if encoded, ok := headers[b64Header].(bool); ok && !encoded {
+		signingInput = append(signingInput, payload...)
+	} else {
+		signingInput = append(signingInput, base64.RawURLEncoding.EncodeToString(payload)...)
+	}
+	if err := verifyWithHeaders(publicKey, headers, signingInput, sig); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+func parseJWS(jws string) (headers map[string]interface{}, payload []byte, signingInput []byte, sig []byte, err error) {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return nil, nil, nil, nil, errors.New("invalid JWS: expected 3 parts")
+	}
+	if headers, err = decodeHeaders(parts[0]); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if payload, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("invalid JWS payload: %w", err)
+	}
+	if sig, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("invalid JWS signature: %w", err)
+	}
+	return headers, payload, []byte(parts[0] + "." + parts[1]), sig, nil
+}
+
+// checkCriticalHeaders checks the crit header as specified by RFC 7515 (section 4.1.11): it must list header
+// parameters that are present and understood by this package. The b64 header must be listed in it when present.
+func checkCriticalHeaders(headers map[string]interface{}) error {
+	critical := make(map[string]bool)
+	if value, ok := headers[critHeader]; ok {
+		names, ok := value.([]interface{})
+		if !ok || len(names) == 0 {
+			return errors.New("invalid JWS: crit header must be a non-empty list")
+		}
+		for _, value := range names {
+			name, ok := value.(string)
+			if !ok {
+				return errors.New("invalid JWS: crit header must be a non-empty list")
+			}
+			if !supportedCriticalHeaders[name] {
+				return fmt.Errorf("invalid JWS: unsupported critical header: %s", name)
+			}
+			if _, present := headers[name]; !present {
+				return fmt.Errorf("invalid JWS: critical header is missing: %s", name)
+			}
+			critical[name] = true
+		}
+	}
+	if value, ok := headers[b64Header]; ok {
+		if _, isBool := value.(bool); !isBool {
+			return errors.New("invalid JWS: b64 header must be a boolean")
+		}
+		if !critical[b64Header] {
+			return errors.New("invalid JWS: b64 header must be listed in crit header")
+		}
+	}
+	return nil
+}
+
+func verifyWithHeaders(publicKey crypto.PublicKey, headers map[string]interface{}, signingInput []byte, sig []byte) error {
+	algorithm, ok := headers[algorithmHeader].(string)
+	if !ok {
+		return errors.New("invalid JWS: missing alg header")
+	}
+	return Verify(publicKey, Algorithm(algorithm), signingInput, sig)
+}
+
+func encodeHeaders(signer crypto.Signer, headers map[string]interface{}) (string, Algorithm, error) {
+	allHeaders := make(map[string]interface{}, len(headers)+1)
+	for key, value := range headers {
+		allHeaders[key] = value
+	}
+	algorithm, ok := allHeaders[algorithmHeader].(Algorithm)
+	if name, isString := allHeaders[algorithmHeader].(string); isString {
+		algorithm, ok = Algorithm(name), true
+	}
+	if !ok {
+		var err error
+		if algorithm, err = AlgorithmForKey(signer.Public()); err != nil {
+			return "", "", err
+		}
+	}
+	allHeaders[algorithmHeader] = string(algorithm)
+	data, err := json.Marshal(allHeaders)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), algorithm, nil
+}
+
+func decodeHeaders(encoded string) (map[string]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWS header: %w", err)
+	}
+	headers := make(map[string]interface{})
+	if err := json.Unmarshal(data, &headers); err != nil {
+		return nil, fmt.Errorf("invalid JWS header: %w", err)
+	}
+	return headers, nil
+}
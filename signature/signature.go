@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package signature creates and verifies signatures using the JSON Web Signature algorithms (RFC 7518),
+// and provides JWS serializations built upon them.
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// Algorithm is a JWS signature algorithm as registered in the IANA JSON Web Signature and Encryption Algorithms registry.
+type Algorithm string
+
+const (
+	// EdDSA is the Edwards-curve signature algorithm, supported with Ed25519 keys (RFC 8037).
+	EdDSA = Algorithm("EdDSA")
+	// ES256 is ECDSA using P-256 and SHA-256.
+	ES256 = Algorithm("ES256")
+	// ES384 is ECDSA using P-384 and SHA-384.
+	ES384 = Algorithm("ES384")
+	// ES256K is ECDSA using secp256k1 and SHA-256 (RFC 8812).
+	ES256K = Algorithm("ES256K")
+	// PS256 is RSASSA-PSS using SHA-256 and MGF1 with SHA-256.
+	PS256 = Algorithm("PS256")
+	// RS256 is RSASSA-PKCS1-v1_5 using SHA-256.
+	RS256 = Algorithm("RS256")
+)
+
+// minRSAKeySize is the minimum size in bits of RSA keys (RFC 7518, section 3.3).
+const minRSAKeySize = 2048
+
+// ErrInvalidSignature is returned when a signature doesn't verify.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// ErrUnsupportedAlgorithm is returned when an algorithm isn't supported or doesn't match the key.
+var ErrUnsupportedAlgorithm = errors.New("unsupported signature algorithm")
+
+// AlgorithmForKey returns the default signature algorithm for the given public key.
+// For RSA keys, this is PS256; RS256 can be used as well.
+func AlgorithmForKey(publicKey crypto.PublicKey) (Algorithm, error) {
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		return EdDSA, nil
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return ES256, nil
+		case elliptic.P384():
+			return ES384, nil
+		case secp256k1.S256():
+			return ES256K, nil
+		}
+	case *rsa.PublicKey:
+		return PS256, nil
+	}
+	return "", fmt.Errorf("%w: no algorithm for key type %T", ErrUnsupportedAlgorithm, publicKey)
+}
+
+// Sign signs the data with the given signer using the given algorithm. ECDSA signatures are returned
+// as the concatenation of R and S, as required by JWS (RFC 7518, section 3.4).
+func Sign(signer crypto.Signer, algorithm Algorithm, data []byte) ([]byte, error) {
+	if err := checkKey(signer.Public(), algorithm); err != nil {
+		return nil, err
+	}
+	switch algorithm {
+	case EdDSA:
+		return signer.Sign(rand.Reader, data, crypto.Hash(0))
+	case ES256, ES384, ES256K:
+		hash := hashFunction(algorithm)
+		asn1Signature, err := signer.Sign(rand.Reader, digest(hash, data), hash)
+		if err != nil {
+			return nil, err
+		}
+		var sig struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(asn1Signature, &sig); err != nil {
+			return nil, fmt.Errorf("invalid ECDSA signature: %w", err)
+		}
+		size := (signer.Public().(*ecdsa.PublicKey).Curve.Params().BitSize + 7) / 8
+		result := make([]byte, 2*size)
+		sig.R.FillBytes(result[:size])
+		sig.S.FillBytes(result[size:])
+		return result, nil
+	case PS256:
+		hash := hashFunction(algorithm)
+		return signer.Sign(rand.Reader, digest(hash, data), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash})
+	case RS256:
+		hash := hashFunction(algorithm)
+		return signer.Sign(rand.Reader, digest(hash, data), hash)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+}
+
+// Verify verifies the signature over the data using the given public key and algorithm.
+// It returns ErrInvalidSignature when the signature doesn't verify.
+func Verify(publicKey crypto.PublicKey, algorithm Algorithm, data []byte, signature []byte) error {
+	if err := checkKey(publicKey, algorithm); err != nil {
+		return err
+	}
+	valid := false
+	switch algorithm {
+	case EdDSA:
+		valid = ed25519.Verify(publicKey.(ed25519.PublicKey), data, signature)
+	case ES256, ES384, ES256K:
+		key := publicKey.(*ecdsa.PublicKey)
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) == 2*size {
+			r := new(big.Int).SetBytes(signature[:size])
+			s := new(big.Int).SetBytes(signature[size:])
+			valid = ecdsa.Verify(key, digest(hashFunction(algorithm), data), r, s)
+		}
+	case PS256:
+		hash := hashFunction(algorithm)
+		valid = rsa.VerifyPSS(publicKey.(*rsa.PublicKey), hash, digest(hash, data), signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}) == nil
+	case RS256:
+		hash := hashFunction(algorithm)
+		valid = rsa.VerifyPKCS1v15(publicKey.(*rsa.PublicKey), hash, digest(hash, data), signature) == nil
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// checkKey checks whether the algorithm can be used with the given public key.
+func checkKey(publicKey crypto.PublicKey, algorithm Algorithm) error {
+	expected, err := AlgorithmForKey(publicKey)
+	if err != nil {
+		return err
+	}
+	if expected == PS256 && algorithm == RS256 {
+		expected = RS256
+	}
+	if expected != algorithm {
+		return fmt.Errorf("%w: %s can't be used with %T", ErrUnsupportedAlgorithm, algorithm, publicKey)
+	}
+	if key, ok := publicKey.(*rsa.PublicKey); ok && key.Size()*8 < minRSAKeySize {
+		return fmt.Errorf("%w: RSA keys must be at least %d bits", ErrUnsupportedAlgorithm, minRSAKeySize)
+	}
+	return nil
+}
+
+func hashFunction(algorithm Algorithm) crypto.Hash {
+	if algorithm == ES384 {
+		return crypto.SHA384
+	}
+	return crypto.SHA256
+}
+
+func digest(hash crypto.Hash, data []byte) []byte {
+	h := hash.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSigners(t *testing.T) map[Algorithm]crypto.Signer {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	k1Key, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return map[Algorithm]crypto.Signer{
+		EdDSA:  edKey,
+		ES256:  p256Key,
+		ES384:  p384Key,
+		ES256K: k1Key,
+		PS256:  rsaKey,
+	}
+}
+
+func TestSign(t *testing.T) {
+	data := []byte("hello, world")
+	for algorithm, signer := range testSigners(t) {
+		t.Run(string(algorithm), func(t *testing.T) {
+			actualAlgorithm, err := AlgorithmForKey(signer.Public())
+			require.NoError(t, err)
+			assert.Equal(t, algorithm, actualAlgorithm)
+
+			sig, err := Sign(signer, algorithm, data)
+			require.NoError(t, err)
+
+			assert.NoError(t, Verify(signer.Public(), algorithm, data, sig))
+			assert.ErrorIs(t, Verify(signer.Public(), algorithm, []byte("other"), sig), ErrInvalidSignature)
+		})
+	}
+	t.Run("RS256", func(t *testing.T) {
+		signer := testSigners(t)[PS256]
+
+		sig, err := Sign(signer, RS256, data)
+		require.NoError(t, err)
+
+		assert.NoError(t, Verify(signer.Public(), RS256, data, sig))
+		assert.ErrorIs(t, Verify(signer.Public(), PS256, data, sig), ErrInvalidSignature)
+	})
+	t.Run("algorithm doesn't match key", func(t *testing.T) {
+		_, key, _ := ed25519.GenerateKey(rand.Reader)
+
+		_, err := Sign(key, ES256, data)
+
+		assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+	})
+	t.Run("RSA key too small", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+
+		_, err = Sign(key, RS256, data)
+
+		assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+	})
+}
+
+func TestSignDetachedJWS(t *testing.T) {
+	payload := []byte(`{"hello": "world"}`)
+	for algorithm, signer := range testSigners(t) {
+		t.Run(string(algorithm), func(t *testing.T) {
+			jws, err := SignDetachedJWS(signer, payload, map[string]interface{}{"kid": "key-1"})
+			require.NoError(t, err)
+
+			headers, err := VerifyDetachedJWS(jws, payload, signer.Public())
+
+			require.NoError(t, err)
+			assert.Equal(t, string(algorithm), headers["alg"])
+			assert.Equal(t, false, headers["b64"])
+			assert.Equal(t, "key-1", headers["kid"])
+			_, err = VerifyDetachedJWS(jws, []byte("other"), signer.Public())
+			assert.ErrorIs(t, err, ErrInvalidSignature)
+		})
+	}
+}
+
+func TestVerifyDetachedJWS(t *testing.T) {
+	signer := testSigners(t)[ES256]
+	payload := []byte(`{"hello": "world"}`)
+	// signDetached creates a JWS with detached, unencoded payload with the given headers, without adding b64 and crit
+	signDetached := func(t *testing.T, headers map[string]interface{}) string {
+		headers["alg"] = ES256
+		data, _ := json.Marshal(headers)
+		encodedHeaders := base64.RawURLEncoding.EncodeToString(data)
+		sig, err := Sign(signer, ES256, append([]byte(encodedHeaders+"."), payload...))
+		require.NoError(t, err)
+		return encodedHeaders + ".." + base64.RawURLEncoding.EncodeToString(sig)
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		jws := signDetached(t, map[string]interface{}{"b64": false, "crit": []string{"b64"}})
+
+		_, err := VerifyDetachedJWS(jws, payload, signer.Public())
+
+		assert.NoError(t, err)
+	})
+	t.Run("b64 not listed in crit", func(t *testing.T) {
+		jws := signDetached(t, map[string]interface{}{"b64": false})
+
+		_, err := VerifyDetachedJWS(jws, payload, signer.Public())
+
+		assert.EqualError(t, err, "invalid JWS: b64 header must be listed in crit header")
+	})
+	t.Run("unsupported critical header", func(t *testing.T) {
+		jws := signDetached(t, map[string]interface{}{"b64": false, "crit": []string{"b64", "exp"}, "exp": 1})
+
+		_, err := VerifyDetachedJWS(jws, payload, signer.Public())
+
+		assert.EqualError(t, err, "invalid JWS: unsupported critical header: exp")
+	})
+	t.Run("critical header missing", func(t *testing.T) {
+		jws := signDetached(t, map[string]interface{}{"crit": []string{"b64"}})
+
+		_, err := VerifyDetachedJWS(jws, payload, signer.Public())
+
+		assert.EqualError(t, err, "invalid JWS: critical header is missing: b64")
+	})
+	t.Run("empty crit", func(t *testing.T) {
+		jws := signDetached(t, map[string]interface{}{"b64": false, "crit": []string{}})
+
+		_, err := VerifyDetachedJWS(jws, payload, signer.Public())
+
+		assert.EqualError(t, err, "invalid JWS: crit header must be a non-empty list")
+	})
+}
+
+func TestSignJWS(t *testing.T) {
+	signer := testSigners(t)[ES256]
+	jws, err := SignJWS(signer, []byte("payload"), map[string]interface{}{"typ": "JWT"})
+	require.NoError(t, err)
+
+	headers, payload, err := VerifyJWS(jws, signer.Public())
+
+	require.NoError(t, err)
+	assert.Equal(t, "payload", string(payload))
+	assert.Equal(t, "JWT", headers["typ"])
+	_, _, err = VerifyJWS(jws, testSigners(t)[ES256].Public())
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifyJWS(t *testing.T) {
+	signer := testSigners(t)[ES256]
+
+	t.Run("unsupported critical header", func(t *testing.T) {
+		jws, err := SignJWS(signer, []byte("payload"), map[string]interface{}{"crit": []string{"exp"}, "exp": 1})
+		require.NoError(t, err)
+
+		_, _, err = VerifyJWS(jws, signer.Public())
+
+		assert.EqualError(t, err, "invalid JWS: unsupported critical header: exp")
+	})
+	t.Run("unencoded payload", func(t *testing.T) {
+		jws, err := SignJWS(signer, []byte("payload"), map[string]interface{}{"b64": false, "crit": []string{"b64"}})
+		require.NoError(t, err)
+
+		_, _, err = VerifyJWS(jws, signer.Public())
+
+		assert.EqualError(t, err, "invalid JWS: unencoded payload must be detached")
+	})
+}
@@ -27,20 +27,69 @@ const JsonWebKey2020 = KeyType("JsonWebKey2020")
 // https://w3c-ccg.github.io/lds-ed25519-2018/
 const ED25519VerificationKey2018 = KeyType("Ed25519VerificationKey2018")
 
+// ED25519VerificationKey2020 is the Ed25519VerificationKey2020 verification key type as specified here:
+// https://w3c-ccg.github.io/di-eddsa-2020/#ed25519verificationkey2020
+const ED25519VerificationKey2020 = KeyType("Ed25519VerificationKey2020")
+
+// Multikey is the Multikey verification method type, which holds a multicodec prefixed key of any key type as
+// publicKeyMultibase: https://www.w3.org/TR/controller-document/#multikey
+const Multikey = KeyType("Multikey")
+
 // ECDSASECP256K1VerificationKey2019 is the EcdsaSecp256k1VerificationKey2019 verification key type as specified here:
 // https://w3c-ccg.github.io/lds-ecdsa-secp256k1-2019/
 const ECDSASECP256K1VerificationKey2019 = KeyType("EcdsaSecp256k1VerificationKey2019")
 
+// ECDSASECP256R1VerificationKey2019 is the EcdsaSecp256r1VerificationKey2019 verification key type for P-256 keys as
+// registered here: https://www.w3.org/TR/did-spec-registries/#ecdsasecp256r1verificationkey2019
+const ECDSASECP256R1VerificationKey2019 = KeyType("EcdsaSecp256r1VerificationKey2019")
+
 // RSAVerificationKey2018 is the RsaVerificationKey2018 verification key type as specified here:
 // https://w3c-ccg.github.io/lds-rsa2018/
 const RSAVerificationKey2018 = KeyType("RsaVerificationKey2018")
 
+// X25519KeyAgreementKey2019 is the X25519KeyAgreementKey2019 key agreement key type as specified here:
+// https://w3c-ccg.github.io/lds-x25519-2019/
+const X25519KeyAgreementKey2019 = KeyType("X25519KeyAgreementKey2019")
+
+// X25519KeyAgreementKey2020 is the X25519KeyAgreementKey2020 key agreement key type as specified here:
+// https://w3c-ccg.github.io/di-eddsa-2020/#x25519keyagreementkey2020
+const X25519KeyAgreementKey2020 = KeyType("X25519KeyAgreementKey2020")
+
+// Bls12381G2Key2020 is the Bls12381G2Key2020 verification key type for BBS signatures as specified here:
+// https://w3c-ccg.github.io/ldp-bbs2020/#bls12381g2key2020
+const Bls12381G2Key2020 = KeyType("Bls12381G2Key2020")
+
 type ProofType string
 
 // JsonWebSignature2020 is a Proof type.
 // https://w3c-ccg.github.io/lds-jws2020
 const JsonWebSignature2020 = ProofType("JsonWebSignature2020")
 
+// Ed25519Signature2020 is a Proof type.
+// https://w3c-ccg.github.io/di-eddsa-2020/
+const Ed25519Signature2020 = ProofType("Ed25519Signature2020")
+
+// EcdsaSecp256k1Signature2019 is a Proof type.
+// https://w3c-ccg.github.io/lds-ecdsa-secp256k1-2019/
+const EcdsaSecp256k1Signature2019 = ProofType("EcdsaSecp256k1Signature2019")
+
+// DataIntegrityProof is a Proof type, of which the cryptographic suite is specified by the proof's cryptosuite.
+// https://www.w3.org/TR/vc-data-integrity/
+const DataIntegrityProof = ProofType("DataIntegrityProof")
+
+// JwtProof2020 is a Proof type, indicating the credential or presentation was secured as a JWT (VC-JWT).
+// https://w3c-ccg.github.io/vc-extension-registry/#proof-methods
+const JwtProof2020 = ProofType("JwtProof2020")
+
+// BbsBlsSignature2020 is a Proof type, holding a BBS signature over all statements of a document.
+// https://w3c-ccg.github.io/ldp-bbs2020/#the-bbs-signature-suite-2020
+const BbsBlsSignature2020 = ProofType("BbsBlsSignature2020")
+
+// BbsBlsSignatureProof2020 is a Proof type, holding a zero-knowledge proof of a BbsBlsSignature2020 signature that
+// discloses only some of the signed statements.
+// https://w3c-ccg.github.io/ldp-bbs2020/#the-bbs-signature-proof-suite-2020
+const BbsBlsSignatureProof2020 = ProofType("BbsBlsSignatureProof2020")
+
 type SchemaType string
 
 const JsonSchemaValidator2018 = SchemaType("JsonSchemaValidator2018")
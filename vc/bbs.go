@@ -0,0 +1,377 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/bbs"
+	"github.com/ugradid/ugradid-common/jcs"
+	"github.com/ugradid/ugradid-common/multiformat"
+)
+
+const nonceKey = "nonce"
+
+// bbsMandatoryKeys are the members of a credential that are always disclosed by derived credentials.
+var bbsMandatoryKeys = map[string]bool{
+	contextKey:        true,
+	typeKey:           true,
+	issuerKey:         true,
+	issuanceDateKey:   true,
+	expirationDateKey: true,
+	validFromKey:      true,
+	validUntilKey:     true,
+}
+
+// BbsBlsSignature2020Suite creates BbsBlsSignature2020 proofs (https://w3c-ccg.github.io/ldp-bbs2020/): a BBS
+// signature over the statements of the document, as standard base64 proofValue. Proofs are created with a
+// *bbs.PrivateKey and verified with the Bls12381G2Key2020 public key of the issuer.
+//
+// Instead of RDF dataset canonicalization, every leaf value of the document (without proof) is a statement: the JCS
+// canonical form of the JSON array holding the value's JSON pointer and the value itself. Arrays and empty objects are
+// leaves, null values aren't statements. The statements are signed as BBS messages in order of their JSON pointers,
+// with the JCS canonical proof configuration (the proof without proofValue and nonce) as BBS header.
+// Holders derive credentials disclosing only some of the statements using DeriveCredential.
+type BbsBlsSignature2020Suite struct{}
+
+// ProofType returns BbsBlsSignature2020.
+func (s BbsBlsSignature2020Suite) ProofType() ssi.ProofType {
+	return ssi.BbsBlsSignature2020
+}
+
+// Cryptosuite returns an empty string, since BbsBlsSignature2020 isn't a DataIntegrityProof.
+func (s BbsBlsSignature2020Suite) Cryptosuite() string {
+	return ""
+}
+
+// Sign signs the statements of the document with a *bbs.PrivateKey and returns the Proof.
+func (s BbsBlsSignature2020Suite) Sign(document interface{}, proof Proof, signer crypto.Signer) (interface{}, error) {
+	privateKey, ok := signer.(*bbs.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s requires a BBS private key", s.ProofType())
+	}
+	proof.Type = s.ProofType()
+	proof.Cryptosuite = ""
+	proof.Nonce = nil
+	proof.ProofValue = ""
+	statements, err := bbsStatements(document)
+	if err != nil {
+		return nil, err
+	}
+	header, err := bbsHeader(proof)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := privateKey.SignMessages(header, statements.messages())
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign document: %w", err)
+	}
+	proof.ProofValue = base64.StdEncoding.EncodeToString(sig)
+	return proof, nil
+}
+
+// Verify verifies the BBS signature over all statements of the document using a Bls12381G2Key2020 public key.
+func (s BbsBlsSignature2020Suite) Verify(document interface{}, proof json.RawMessage, publicKey crypto.PublicKey) error {
+	blsKey, ok := publicKey.(multiformat.BLS12381G2PublicKey)
+	if !ok {
+		return fmt.Errorf("%s requires a BLS12-381 G2 public key", s.ProofType())
+	}
+	sig, header, err := parseBbsProof(proof)
+	if err != nil {
+		return err
+	}
+	statements, err := bbsStatements(document)
+	if err != nil {
+		return err
+	}
+	return bbs.Verify(blsKey, sig, header, statements.messages())
+}
+
+// BbsBlsSignatureProof2020Suite verifies BbsBlsSignatureProof2020 proofs of credentials derived by DeriveCredential:
+// a BBS proof of a BbsBlsSignature2020 signature, disclosing only the statements of the derived credential.
+// The proofValue is the standard base64 encoding of the total number of signed statements (2 bytes, big-endian),
+// a bit vector marking the disclosed statements (most significant bit first) and the BBS proof.
+// The proof's nonce is the BBS presentation header.
+type BbsBlsSignatureProof2020Suite struct{}
+
+// ProofType returns BbsBlsSignatureProof2020.
+func (s BbsBlsSignatureProof2020Suite) ProofType() ssi.ProofType {
+	return ssi.BbsBlsSignatureProof2020
+}
+
+// Cryptosuite returns an empty string, since BbsBlsSignatureProof2020 isn't a DataIntegrityProof.
+func (s BbsBlsSignatureProof2020Suite) Cryptosuite() string {
+	return ""
+}
+
+// Sign returns an error, since BbsBlsSignatureProof2020 proofs are derived from a signature using DeriveCredential.
+func (s BbsBlsSignatureProof2020Suite) Sign(_ interface{}, _ Proof, _ crypto.Signer) (interface{}, error) {
+	return nil, fmt.Errorf("%s proofs can't be signed, they are derived using DeriveCredential", s.ProofType())
+}
+
+// Verify verifies the BBS proof over the statements of the (derived) document using a Bls12381G2Key2020 public key.
+func (s BbsBlsSignatureProof2020Suite) Verify(document interface{}, proof json.RawMessage, publicKey crypto.PublicKey) error {
+	blsKey, ok := publicKey.(multiformat.BLS12381G2PublicKey)
+	if !ok {
+		return fmt.Errorf("%s requires a BLS12-381 G2 public key", s.ProofType())
+	}
+	proofValue, header, err := parseBbsProof(proof)
+	if err != nil {
+		return err
+	}
+	var nonce string
+	var members map[string]json.RawMessage
+	_ = json.Unmarshal(proof, &members)
+	if err := json.Unmarshal(members[nonceKey], &nonce); err != nil || nonce == "" {
+		return errors.New("proof has no nonce")
+	}
+	if len(proofValue) < 2 {
+		return errors.New("invalid proofValue: too short")
+	}
+	count := int(binary.BigEndian.Uint16(proofValue))
+	bitVectorSize := (count + 7) / 8
+	if len(proofValue) < 2+bitVectorSize {
+		return errors.New("invalid proofValue: too short")
+	}
+	bitVector, bbsProof := proofValue[2:2+bitVectorSize], proofValue[2+bitVectorSize:]
+
+	statements, err := bbsStatements(document)
+	if err != nil {
+		return err
+	}
+	disclosed := make(map[int][]byte, len(statements))
+	for index := 0; index < count; index++ {
+		if bitVector[index/8]&(0x80>>(index%8)) == 0 {
+			continue
+		}
+		if len(disclosed) == len(statements) {
+			return errors.New("proof discloses more statements than the document holds")
+		}
+		disclosed[index] = statements[len(disclosed)].message
+	}
+	if len(disclosed) != len(statements) {
+		return errors.New("document holds statements that aren't disclosed by the proof")
+	}
+	return bbs.VerifyProof(blsKey, bbsProof, header, []byte(nonce), count, disclosed)
+}
+
+// DeriveCredential derives a credential from a credential with a BbsBlsSignature2020 proof, which discloses only the
+// statements selected by the frame and holds a BbsBlsSignatureProof2020 proof instead. The public key is the
+// Bls12381G2Key2020 key of the proof's verification method. The nonce binds the proof to its verifier; when empty,
+// a random nonce is generated so derived credentials can't be linked to each other.
+//
+// The frame mirrors the credential's JSON structure: a member holding an object selects members of the credential's
+// object by name, any other value but false discloses the member entirely. For example,
+// {"credentialSubject": {"id": true, "degree": {"type": true}}} discloses the subject's ID and the type of its degree.
+// The @context, type, issuer, issuanceDate, expirationDate, validFrom and validUntil are always disclosed.
+func DeriveCredential(credential VerifiableCredential, frame map[string]interface{}, publicKey crypto.PublicKey, nonce string) (*VerifiableCredential, error) {
+	blsKey, ok := publicKey.(multiformat.BLS12381G2PublicKey)
+	if !ok {
+		return nil, errors.New("deriving a credential requires a BLS12-381 G2 public key")
+	}
+	proofs, err := rawProofs(credential.Proof)
+	if err != nil {
+		return nil, err
+	}
+	var proof Proof
+	var rawProof json.RawMessage
+	for _, curr := range proofs {
+		if err := json.Unmarshal(curr, &proof); err == nil && proof.Type == ssi.BbsBlsSignature2020 {
+			rawProof = curr
+			break
+		}
+	}
+	if rawProof == nil {
+		return nil, fmt.Errorf("credential has no %s proof", ssi.BbsBlsSignature2020)
+	}
+	sig, header, err := parseBbsProof(rawProof)
+	if err != nil {
+		return nil, err
+	}
+	if nonce == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		nonce = base64.StdEncoding.EncodeToString(random)
+	}
+
+	credential.Proof = nil
+	statements, err := bbsStatements(credential)
+	if err != nil {
+		return nil, err
+	}
+	var disclosed []int
+	bitVector := make([]byte, (len(statements)+7)/8)
+	derived := make(map[string]interface{})
+	for index, statement := range statements {
+		if bbsMandatoryKeys[statement.path[0]] || framed(frame, statement.path) {
+			disclosed = append(disclosed, index)
+			bitVector[index/8] |= 0x80 >> (index % 8)
+			setPath(derived, statement.path, statement.value)
+		}
+	}
+	bbsProof, err := bbs.CreateProof(blsKey, sig, header, []byte(nonce), statements.messages(), disclosed)
+	if err != nil {
+		return nil, fmt.Errorf("unable to derive proof: %w", err)
+	}
+
+	proofValue := make([]byte, 2, 2+len(bitVector)+len(bbsProof))
+	binary.BigEndian.PutUint16(proofValue, uint16(len(statements)))
+	proofValue = append(append(proofValue, bitVector...), bbsProof...)
+	proof.Type = ssi.BbsBlsSignatureProof2020
+	proof.Nonce = &nonce
+	proof.ProofValue = base64.StdEncoding.EncodeToString(proofValue)
+
+	var result VerifiableCredential
+	if err := remarshal(derived, &result); err != nil {
+		return nil, err
+	}
+	result.Proof = []interface{}{proof}
+	return &result, nil
+}
+
+// bbsStatement is a leaf value of a document signed as BBS message.
+type bbsStatement struct {
+	pointer string
+	path    []string
+	value   interface{}
+	message []byte
+}
+
+type bbsStatementList []bbsStatement
+
+func (l bbsStatementList) messages() [][]byte {
+	result := make([][]byte, len(l))
+	for i, statement := range l {
+		result[i] = statement.message
+	}
+	return result
+}
+
+// bbsStatements returns the statements of the document without its proof, in order of their JSON pointers.
+func bbsStatements(document interface{}) (bbsStatementList, error) {
+	var members map[string]interface{}
+	if err := remarshal(document, &members); err != nil {
+		return nil, fmt.Errorf("not a JSON object: %w", err)
+	}
+	delete(members, proofKey)
+	var result bbsStatementList
+	if err := collectStatements(nil, members, &result); err != nil {
+		return nil, err
+	}
+	if len(result) > 0xffff {
+		return nil, errors.New("document holds too many statements")
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].pointer < result[j].pointer
+	})
+	return result, nil
+}
+
+func collectStatements(path []string, value interface{}, result *bbsStatementList) error {
+	if value == nil {
+		return nil
+	}
+	if object, ok := value.(map[string]interface{}); ok && len(object) > 0 {
+		for name, member := range object {
+			memberPath := append(append([]string{}, path...), name)
+			if err := collectStatements(memberPath, member, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	pointer := ""
+	for _, name := range path {
+		pointer += "/" + escapePointer(name)
+	}
+	message, err := jcs.Marshal([]interface{}{pointer, value})
+	if err != nil {
+		return err
+	}
+	*result = append(*result, bbsStatement{pointer: pointer, path: path, value: value, message: message})
+	return nil
+}
+
+// framed returns whether the frame discloses the member at the given path.
+func framed(frame map[string]interface{}, path []string) bool {
+	value, ok := frame[path[0]]
+	if !ok || value == false {
+		return false
+	}
+	if nested, ok := value.(map[string]interface{}); ok && len(path) > 1 {
+		return framed(nested, path[1:])
+	}
+	return true
+}
+
+// setPath sets the member at the given path, creating the objects holding it.
+func setPath(object map[string]interface{}, path []string, value interface{}) {
+	for _, name := range path[:len(path)-1] {
+		nested, ok := object[name].(map[string]interface{})
+		if !ok {
+			nested = make(map[string]interface{})
+			object[name] = nested
+		}
+		object = nested
+	}
+	object[path[len(path)-1]] = value
+}
+
+// parseBbsProof returns the decoded proofValue of the BBS proof, given as JSON, and the BBS header: the JCS canonical
+// proof configuration of the BbsBlsSignature2020 proof it is or is derived from.
+func parseBbsProof(rawProof json.RawMessage) ([]byte, []byte, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(rawProof, &members); err != nil {
+		return nil, nil, err
+	}
+	var proofValue string
+	if err := json.Unmarshal(members[proofValueKey], &proofValue); err != nil || proofValue == "" {
+		return nil, nil, errors.New("proof has no proofValue")
+	}
+	value, err := base64.StdEncoding.DecodeString(proofValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid proofValue: %w", err)
+	}
+	members[typeKey], _ = json.Marshal(ssi.BbsBlsSignature2020)
+	header, err := bbsHeader(members)
+	if err != nil {
+		return nil, nil, err
+	}
+	return value, header, nil
+}
+
+// bbsHeader returns the JCS canonical form of the proof configuration without proofValue and nonce.
+func bbsHeader(proofConfig interface{}) ([]byte, error) {
+	members, err := jsonMembers(proofConfig)
+	if err != nil {
+		return nil, err
+	}
+	delete(members, proofValueKey)
+	delete(members, nonceKey)
+	return jcs.Marshal(members)
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/bbs"
+	"github.com/ugradid/ugradid-common/did"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBbsBlsSignature2020Suite(t *testing.T) {
+	ctx := context.Background()
+	store := did.NewMemoryStore()
+	issuer := newTestIssuer(t, "did:example:issuer", (*did.Document).AddAssertionMethod)
+	key, err := bbs.GenerateKey(nil)
+	require.NoError(t, err)
+	vmID, _ := did.ParseDIDURL("did:example:issuer#bbs-1")
+	vm, err := did.NewVerificationMethod(*vmID, ssi.Bls12381G2Key2020, issuer.document.ID, key.Public())
+	require.NoError(t, err)
+	issuer.document.VerificationMethod.Add(vm)
+	issuer.document.AddAssertionMethod(vm)
+	_, err = store.Put(issuer.document, time.Now())
+	require.NoError(t, err)
+	verifier := NewVerifier(store)
+
+	credential := testIdentityCredential(issuer)
+	err = SignCredential(&credential, key, vmID.URI(), ProofOptions{Suite: BbsBlsSignature2020Suite{}})
+	require.NoError(t, err)
+
+	t.Run("verify after JSON round trip", func(t *testing.T) {
+		data, _ := json.Marshal(credential)
+		var actual VerifiableCredential
+		require.NoError(t, json.Unmarshal(data, &actual))
+
+		result, err := verifier.Verify(ctx, actual, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+	})
+	t.Run("tampered credential", func(t *testing.T) {
+		tampered := testIdentityCredential(issuer)
+		tampered.CredentialSubject["given_name"] = "Bob"
+		tampered.Proof = credential.Proof
+
+		result, err := verifier.Verify(ctx, tampered, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, SignatureCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("non-BBS key", func(t *testing.T) {
+		credential := testIdentityCredential(issuer)
+
+		err := SignCredential(&credential, issuer.key, issuer.keyID, ProofOptions{Suite: BbsBlsSignature2020Suite{}})
+
+		assert.EqualError(t, err, "BbsBlsSignature2020 requires a BBS private key")
+	})
+
+	t.Run("derive credential", func(t *testing.T) {
+		frame := map[string]interface{}{
+			"credentialSubject": map[string]interface{}{
+				"given_name": true,
+				"address":    map[string]interface{}{"locality": true},
+				"id":         false,
+			},
+		}
+
+		derived, err := DeriveCredential(credential, frame, key.Public(), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, credential.Type, derived.Type)
+		assert.Equal(t, credential.Issuer, derived.Issuer)
+		assert.Equal(t, credential.IssuanceDate, derived.IssuanceDate)
+		assert.Equal(t, map[string]interface{}{
+			"given_name": "Alice",
+			"address":    map[string]interface{}{"locality": "Anytown"},
+		}, derived.CredentialSubject)
+		proofs, err := derived.Proofs()
+		require.NoError(t, err)
+		require.Len(t, proofs, 1)
+		assert.Equal(t, ssi.BbsBlsSignatureProof2020, proofs[0].Type)
+		assert.Equal(t, vmID.URI(), proofs[0].VerificationMethod)
+		require.NotNil(t, proofs[0].Nonce)
+
+		t.Run("verify after JSON round trip", func(t *testing.T) {
+			data, _ := json.Marshal(derived)
+			var actual VerifiableCredential
+			require.NoError(t, json.Unmarshal(data, &actual))
+
+			result, err := verifier.Verify(ctx, actual, VerificationOptions{})
+
+			require.NoError(t, err)
+			assert.NoError(t, result.Err())
+		})
+		t.Run("unlinkable", func(t *testing.T) {
+			other, err := DeriveCredential(credential, frame, key.Public(), *proofs[0].Nonce)
+
+			require.NoError(t, err)
+			otherProofs, _ := other.Proofs()
+			assert.NotEqual(t, proofs[0].ProofValue, otherProofs[0].ProofValue)
+			result, err := verifier.Verify(ctx, *other, VerificationOptions{})
+			require.NoError(t, err)
+			assert.NoError(t, result.Err())
+		})
+		t.Run("tampered disclosed claim", func(t *testing.T) {
+			tampered := *derived
+			tampered.CredentialSubject = map[string]interface{}{
+				"given_name": "Bob",
+				"address":    map[string]interface{}{"locality": "Anytown"},
+			}
+
+			result, err := verifier.Verify(ctx, tampered, VerificationOptions{})
+
+			require.NoError(t, err)
+			assert.Equal(t, SignatureCheck, failedCheck(result.Proofs[0]))
+		})
+		t.Run("added claim", func(t *testing.T) {
+			tampered := *derived
+			tampered.CredentialSubject = map[string]interface{}{
+				"given_name":  "Alice",
+				"family_name": "Smith",
+				"address":     map[string]interface{}{"locality": "Anytown"},
+			}
+
+			result, err := verifier.Verify(ctx, tampered, VerificationOptions{})
+
+			require.NoError(t, err)
+			assert.Equal(t, SignatureCheck, failedCheck(result.Proofs[0]))
+		})
+		t.Run("other nonce", func(t *testing.T) {
+			tampered := *derived
+			proof := proofs[0]
+			nonce := "other"
+			proof.Nonce = &nonce
+			tampered.Proof = []interface{}{proof}
+
+			result, err := verifier.Verify(ctx, tampered, VerificationOptions{})
+
+			require.NoError(t, err)
+			assert.Equal(t, SignatureCheck, failedCheck(result.Proofs[0]))
+		})
+	})
+	t.Run("derive 2.0 credential", func(t *testing.T) {
+		validFrom := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		validUntil := time.Now().AddDate(1, 0, 0).UTC().Truncate(time.Second)
+		credential := testIdentityCredential(issuer)
+		credential.Context = []ssi.URI{VCContextV2URI()}
+		credential.IssuanceDate = time.Time{}
+		credential.ValidFrom = &validFrom
+		credential.ValidUntil = &validUntil
+		require.NoError(t, SignCredential(&credential, key, vmID.URI(), ProofOptions{Suite: BbsBlsSignature2020Suite{}}))
+		frame := map[string]interface{}{"credentialSubject": map[string]interface{}{"given_name": true}}
+
+		derived, err := DeriveCredential(credential, frame, key.Public(), "")
+
+		require.NoError(t, err)
+		require.NotNil(t, derived.ValidFrom)
+		require.NotNil(t, derived.ValidUntil)
+		assert.True(t, validFrom.Equal(*derived.ValidFrom))
+		assert.True(t, validUntil.Equal(*derived.ValidUntil))
+		result, err := verifier.Verify(ctx, *derived, VerificationOptions{})
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+	})
+	t.Run("derive credential without BBS proof", func(t *testing.T) {
+		_, err := DeriveCredential(issuer.credential(t), nil, key.Public(), "")
+
+		assert.EqualError(t, err, "credential has no BbsBlsSignature2020 proof")
+	})
+	t.Run("derived proofs can't be signed", func(t *testing.T) {
+		credential := testIdentityCredential(issuer)
+
+		err := SignCredential(&credential, key, vmID.URI(), ProofOptions{Suite: BbsBlsSignatureProof2020Suite{}})
+
+		assert.Error(t, err)
+	})
+}
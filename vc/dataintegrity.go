@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/jcs"
+	"github.com/ugradid/ugradid-common/multiformat"
+	"github.com/ugradid/ugradid-common/signature"
+)
+
+// EdDSAJCS2022Cryptosuite is the eddsa-jcs-2022 cryptosuite for DataIntegrityProof.
+// https://www.w3.org/TR/vc-di-eddsa/#eddsa-jcs-2022
+const EdDSAJCS2022Cryptosuite = "eddsa-jcs-2022"
+
+const proofValueKey = "proofValue"
+
+// Ed25519Signature2020Suite creates Ed25519Signature2020 proofs (https://w3c-ccg.github.io/di-eddsa-2020/) with an
+// Ed25519 signature as base58btc multibase proofValue. Like JSONWebSignature2020Suite, documents and proof options
+// are canonicalized using JCS instead of RDF dataset canonicalization.
+type Ed25519Signature2020Suite struct{}
+
+// ProofType returns Ed25519Signature2020.
+func (s Ed25519Signature2020Suite) ProofType() ssi.ProofType {
+	return ssi.Ed25519Signature2020
+}
+
+// Cryptosuite returns an empty string, since Ed25519Signature2020 isn't a DataIntegrityProof.
+func (s Ed25519Signature2020Suite) Cryptosuite() string {
+	return ""
+}
+
+// Sign signs the document with an Ed25519 key and returns the Proof.
+func (s Ed25519Signature2020Suite) Sign(document interface{}, proof Proof, signer crypto.Signer) (interface{}, error) {
+	proof.Type = s.ProofType()
+	proof.Cryptosuite = ""
+	return createMultibaseProof(document, proof, signer, signature.EdDSA)
+}
+
+// Verify verifies the proof using an Ed25519 public key.
+func (s Ed25519Signature2020Suite) Verify(document interface{}, proof json.RawMessage, publicKey crypto.PublicKey) error {
+	return verifyMultibaseProof(document, proof, publicKey, signature.EdDSA)
+}
+
+// EdDSAJCS2022Suite creates DataIntegrityProof proofs using the eddsa-jcs-2022 cryptosuite
+// (https://www.w3.org/TR/vc-di-eddsa/#eddsa-jcs-2022) with an Ed25519 signature as base58btc multibase proofValue.
+type EdDSAJCS2022Suite struct{}
+
+// ProofType returns DataIntegrityProof.
+func (s EdDSAJCS2022Suite) ProofType() ssi.ProofType {
+	return ssi.DataIntegrityProof
+}
+
+// Cryptosuite returns eddsa-jcs-2022.
+func (s EdDSAJCS2022Suite) Cryptosuite() string {
+	return EdDSAJCS2022Cryptosuite
+}
+
+// Sign signs the document with an Ed25519 key and returns the Proof.
+func (s EdDSAJCS2022Suite) Sign(document interface{}, proof Proof, signer crypto.Signer) (interface{}, error) {
+	proof.Type = s.ProofType()
+	proof.Cryptosuite = s.Cryptosuite()
+	return createMultibaseProof(document, proof, signer, signature.EdDSA)
+}
+
+// Verify verifies the proof using an Ed25519 public key.
+func (s EdDSAJCS2022Suite) Verify(document interface{}, proof json.RawMessage, publicKey crypto.PublicKey) error {
+	return verifyMultibaseProof(document, proof, publicKey, signature.EdDSA)
+}
+
+// createMultibaseProof signs the hash data of the document and proof with the given algorithm,
+// and returns the proof with the signature as base58btc multibase proofValue.
+func createMultibaseProof(document interface{}, proof Proof, signer crypto.Signer, algorithm signature.Algorithm) (interface{}, error) {
+	proof.ProofValue = ""
+	data, err := hashData(document, proof, algorithm)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signature.Sign(signer, algorithm, data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign document: %w", err)
+	}
+	if proof.ProofValue, err = multiformat.EncodeMultibase(multiformat.Base58BTC, sig); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// verifyMultibaseProof verifies the multibase proofValue of the proof, given as JSON, with the given algorithm.
+func verifyMultibaseProof(document interface{}, rawProof json.RawMessage, publicKey crypto.PublicKey, algorithm signature.Algorithm) error {
+	var proofConfig map[string]json.RawMessage
+	if err := json.Unmarshal(rawProof, &proofConfig); err != nil {
+		return err
+	}
+	var proofValue string
+	if err := json.Unmarshal(proofConfig[proofValueKey], &proofValue); err != nil || proofValue == "" {
+		return errors.New("proof has no proofValue")
+	}
+	delete(proofConfig, proofValueKey)
+	encoding, sig, err := multiformat.DecodeMultibase(proofValue)
+	if err != nil {
+		return fmt.Errorf("invalid proofValue: %w", err)
+	}
+	if encoding != multiformat.Base58BTC {
+		return errors.New("invalid proofValue: must be base58btc encoded")
+	}
+	data, err := hashData(document, proofConfig, algorithm)
+	if err != nil {
+		return err
+	}
+	return signature.Verify(publicKey, algorithm, data, sig)
+}
+
+// hashData returns the data to sign for a proof: the hash of the canonicalized proof configuration (the proof without
+// proofValue, with the document's @context) followed by the hash of the canonicalized document without proof.
+// The hash function is the one of the signature algorithm (e.g. SHA-384 for ES384), or SHA-256 for EdDSA.
+func hashData(document interface{}, proofConfig interface{}, algorithm signature.Algorithm) ([]byte, error) {
+	documentMembers, err := jsonMembers(document)
+	if err != nil {
+		return nil, err
+	}
+	delete(documentMembers, proofKey)
+	configMembers, err := jsonMembers(proofConfig)
+	if err != nil {
+		return nil, err
+	}
+	delete(configMembers, proofValueKey)
+	delete(configMembers, contextKey)
+	if context, ok := documentMembers[contextKey]; ok {
+		configMembers[contextKey] = context
+	}
+
+	newHash := sha256.New
+	if algorithm == signature.ES384 {
+		newHash = sha512.New384
+	}
+	var result []byte
+	for _, members := range []map[string]json.RawMessage{configMembers, documentMembers} {
+		canonical, err := jcs.Marshal(members)
+		if err != nil {
+			return nil, err
+		}
+		digest := newHash()
+		digest.Write(canonical)
+		result = digest.Sum(result)
+	}
+	return result, nil
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/did"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEd25519Suites(t *testing.T) {
+	ctx := context.Background()
+	store := did.NewMemoryStore()
+	issuer := newTestIssuer(t, "did:example:issuer", (*did.Document).AddAssertionMethod)
+	_, err := store.Put(issuer.document, time.Now())
+	require.NoError(t, err)
+	verifier := NewVerifier(store)
+
+	for _, suite := range []ProofSuite{Ed25519Signature2020Suite{}, EdDSAJCS2022Suite{}} {
+		t.Run(string(suite.ProofType())+" "+suite.Cryptosuite(), func(t *testing.T) {
+			credential := testCredential()
+			credential.Issuer = issuer.document.ID.URI()
+
+			err := SignCredential(&credential, issuer.key, issuer.keyID, ProofOptions{Suite: suite})
+
+			require.NoError(t, err)
+			proofs, err := credential.Proofs()
+			require.NoError(t, err)
+			require.Len(t, proofs, 1)
+			assert.Equal(t, suite.ProofType(), proofs[0].Type)
+			assert.Equal(t, suite.Cryptosuite(), proofs[0].Cryptosuite)
+			assert.True(t, strings.HasPrefix(proofs[0].ProofValue, "z"))
+
+			t.Run("verify after JSON round trip", func(t *testing.T) {
+				data, _ := json.Marshal(credential)
+				var actual VerifiableCredential
+				require.NoError(t, json.Unmarshal(data, &actual))
+
+				result, err := verifier.Verify(ctx, actual, VerificationOptions{})
+
+				require.NoError(t, err)
+				assert.NoError(t, result.Err())
+			})
+			t.Run("tampered credential", func(t *testing.T) {
+				tampered := credential
+				tampered.IssuanceDate = tampered.IssuanceDate.Add(time.Hour)
+
+				result, err := verifier.Verify(ctx, tampered, VerificationOptions{})
+
+				require.NoError(t, err)
+				assert.Equal(t, SignatureCheck, failedCheck(result.Proofs[0]))
+			})
+			t.Run("tampered proof", func(t *testing.T) {
+				tampered := credential
+				proof := proofs[0]
+				proof.Created = proof.Created.Add(time.Hour)
+				tampered.Proof = []interface{}{proof}
+
+				result, err := verifier.Verify(ctx, tampered, VerificationOptions{})
+
+				require.NoError(t, err)
+				assert.Equal(t, SignatureCheck, failedCheck(result.Proofs[0]))
+			})
+			t.Run("non-Ed25519 key", func(t *testing.T) {
+				key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				credential := testCredential()
+
+				err := SignCredential(&credential, key, issuer.keyID, ProofOptions{Suite: suite})
+
+				assert.Error(t, err)
+			})
+		})
+	}
+	t.Run("unknown cryptosuite", func(t *testing.T) {
+		credential := testCredential()
+		credential.Issuer = issuer.document.ID.URI()
+		require.NoError(t, SignCredential(&credential, issuer.key, issuer.keyID, ProofOptions{Suite: EdDSAJCS2022Suite{}}))
+		proofs, _ := credential.Proofs()
+		proofs[0].Cryptosuite = "eddsa-rdfc-2022"
+		credential.Proof = []interface{}{proofs[0]}
+
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, ProofTypeCheck, failedCheck(result.Proofs[0]))
+	})
+}
+
+func TestFindProofSuite(t *testing.T) {
+	assert.IsType(t, JSONWebSignature2020Suite{}, FindProofSuite(ssi.JsonWebSignature2020, ""))
+	assert.IsType(t, EdDSAJCS2022Suite{}, FindProofSuite(ssi.DataIntegrityProof, EdDSAJCS2022Cryptosuite))
+	assert.Nil(t, FindProofSuite(ssi.DataIntegrityProof, ""))
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/signature"
+)
+
+// EcdsaJCS2019Cryptosuite is the ecdsa-jcs-2019 cryptosuite for DataIntegrityProof.
+// https://www.w3.org/TR/vc-di-ecdsa/#ecdsa-jcs-2019
+const EcdsaJCS2019Cryptosuite = "ecdsa-jcs-2019"
+
+// EcdsaRDFC2019Cryptosuite is the ecdsa-rdfc-2019 cryptosuite for DataIntegrityProof.
+// https://www.w3.org/TR/vc-di-ecdsa/#ecdsa-rdfc-2019
+// No suite is registered for it yet: it requires JSON-LD expansion and RDF dataset canonicalization (RDFC-1.0),
+// for which this module has no implementation. Proofs using it fail verification as unsupported proof type.
+const EcdsaRDFC2019Cryptosuite = "ecdsa-rdfc-2019"
+
+// EcdsaSecp256k1Signature2019Proof is a VC proof with a signature according to EcdsaSecp256k1Signature2019
+type EcdsaSecp256k1Signature2019Proof JSONWebSignature2020Proof
+
+// EcdsaSecp256k1Signature2019Suite creates EcdsaSecp256k1Signature2019 proofs
+// (https://w3c-ccg.github.io/lds-ecdsa-secp256k1-2019/): a JWS with detached, unencoded payload and alg ES256K.
+// Like JSONWebSignature2020Suite, documents and proof options are canonicalized using JCS.
+type EcdsaSecp256k1Signature2019Suite struct{}
+
+// ProofType returns EcdsaSecp256k1Signature2019.
+func (s EcdsaSecp256k1Signature2019Suite) ProofType() ssi.ProofType {
+	return ssi.EcdsaSecp256k1Signature2019
+}
+
+// Cryptosuite returns an empty string, since EcdsaSecp256k1Signature2019 isn't a DataIntegrityProof.
+func (s EcdsaSecp256k1Signature2019Suite) Cryptosuite() string {
+	return ""
+}
+
+// Sign signs the document with a secp256k1 key and returns an EcdsaSecp256k1Signature2019Proof.
+func (s EcdsaSecp256k1Signature2019Suite) Sign(document interface{}, proof Proof, signer crypto.Signer) (interface{}, error) {
+	if err := checkAlgorithm(signer.Public(), signature.ES256K); err != nil {
+		return nil, err
+	}
+	proof.Type = s.ProofType()
+	proof.Cryptosuite = ""
+	jws, err := createJWS(document, proof, signer, signature.ES256K)
+	if err != nil {
+		return nil, err
+	}
+	return EcdsaSecp256k1Signature2019Proof{Proof: proof, Jws: jws}, nil
+}
+
+// Verify verifies the proof using a secp256k1 public key.
+func (s EcdsaSecp256k1Signature2019Suite) Verify(document interface{}, proof json.RawMessage, publicKey crypto.PublicKey) error {
+	if err := checkAlgorithm(publicKey, signature.ES256K); err != nil {
+		return err
+	}
+	var target EcdsaSecp256k1Signature2019Proof
+	if err := json.Unmarshal(proof, &target); err != nil {
+		return err
+	}
+	return verifyJWS(document, target.Proof, target.Jws, publicKey)
+}
+
+// EcdsaJCS2019Suite creates DataIntegrityProof proofs using the ecdsa-jcs-2019 cryptosuite with a P-256 (SHA-256)
+// or P-384 (SHA-384) signature as base58btc multibase proofValue.
+type EcdsaJCS2019Suite struct{}
+
+// ProofType returns DataIntegrityProof.
+func (s EcdsaJCS2019Suite) ProofType() ssi.ProofType {
+	return ssi.DataIntegrityProof
+}
+
+// Cryptosuite returns ecdsa-jcs-2019.
+func (s EcdsaJCS2019Suite) Cryptosuite() string {
+	return EcdsaJCS2019Cryptosuite
+}
+
+// Sign signs the document with a P-256 or P-384 key and returns the Proof.
+func (s EcdsaJCS2019Suite) Sign(document interface{}, proof Proof, signer crypto.Signer) (interface{}, error) {
+	algorithm, err := s.algorithm(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	proof.Type = s.ProofType()
+	proof.Cryptosuite = s.Cryptosuite()
+	return createMultibaseProof(document, proof, signer, algorithm)
+}
+
+// Verify verifies the proof using a P-256 or P-384 public key.
+func (s EcdsaJCS2019Suite) Verify(document interface{}, proof json.RawMessage, publicKey crypto.PublicKey) error {
+	algorithm, err := s.algorithm(publicKey)
+	if err != nil {
+		return err
+	}
+	return verifyMultibaseProof(document, proof, publicKey, algorithm)
+}
+
+func (s EcdsaJCS2019Suite) algorithm(publicKey crypto.PublicKey) (signature.Algorithm, error) {
+	algorithm, err := signature.AlgorithmForKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	if algorithm != signature.ES256 && algorithm != signature.ES384 {
+		return "", fmt.Errorf("%w: %s requires a P-256 or P-384 key", signature.ErrUnsupportedAlgorithm, s.Cryptosuite())
+	}
+	return algorithm, nil
+}
+
+// checkAlgorithm checks the given key is meant for the given algorithm.
+func checkAlgorithm(publicKey crypto.PublicKey, algorithm signature.Algorithm) error {
+	actual, err := signature.AlgorithmForKey(publicKey)
+	if err != nil {
+		return err
+	}
+	if actual != algorithm {
+		return fmt.Errorf("%w: key is not meant for %s", signature.ErrUnsupportedAlgorithm, algorithm)
+	}
+	return nil
+}
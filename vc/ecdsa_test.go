@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/did"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestECDSASuites(t *testing.T) {
+	ctx := context.Background()
+	store := did.NewMemoryStore()
+	verifier := NewVerifier(store)
+
+	// newIssuer registers a DID document with the given key as assertion method
+	newIssuer := func(t *testing.T, id string, keyType ssi.KeyType, key *ecdsa.PrivateKey) ssi.URI {
+		subject, _ := did.ParseDID(id)
+		vmID, _ := did.ParseDIDURL(id + "#key-1")
+		vm, err := did.NewVerificationMethod(*vmID, keyType, *subject, &key.PublicKey)
+		require.NoError(t, err)
+		document := did.Document{Context: []ssi.URI{did.DIDContextV1URI()}, ID: *subject}
+		document.AddAssertionMethod(vm)
+		_, err = store.Put(document, time.Now())
+		require.NoError(t, err)
+		return vmID.URI()
+	}
+	secp256k1Key, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	testCases := []struct {
+		name    string
+		suite   ProofSuite
+		issuer  string
+		keyType ssi.KeyType
+		key     *ecdsa.PrivateKey
+	}{
+		{"EcdsaSecp256k1Signature2019", EcdsaSecp256k1Signature2019Suite{}, "did:example:secp256k1", ssi.ECDSASECP256K1VerificationKey2019, secp256k1Key},
+		{"ecdsa-jcs-2019 P-256", EcdsaJCS2019Suite{}, "did:example:p256", ssi.ECDSASECP256R1VerificationKey2019, p256Key},
+		{"ecdsa-jcs-2019 P-384", EcdsaJCS2019Suite{}, "did:example:p384", ssi.JsonWebKey2020, p384Key},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			keyID := newIssuer(t, testCase.issuer, testCase.keyType, testCase.key)
+			credential := testCredential()
+			issuer, _ := ssi.ParseURI(testCase.issuer)
+			credential.Issuer = *issuer
+
+			require.NoError(t, SignCredential(&credential, testCase.key, keyID, ProofOptions{Suite: testCase.suite}))
+
+			data, _ := json.Marshal(credential)
+			var actual VerifiableCredential
+			require.NoError(t, json.Unmarshal(data, &actual))
+			result, err := verifier.Verify(ctx, actual, VerificationOptions{})
+			require.NoError(t, err)
+			assert.NoError(t, result.Err())
+			proofs, _ := actual.Proofs()
+			assert.Equal(t, testCase.suite.ProofType(), proofs[0].Type)
+			assert.Equal(t, testCase.suite.Cryptosuite(), proofs[0].Cryptosuite)
+
+			actual.CredentialSubject["name"] = "Mallory"
+			result, err = verifier.Verify(ctx, actual, VerificationOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, SignatureCheck, failedCheck(result.Proofs[0]))
+		})
+	}
+	t.Run("ecdsa-rdfc-2019 is unsupported", func(t *testing.T) {
+		keyID := newIssuer(t, "did:example:rdfc", ssi.ECDSASECP256R1VerificationKey2019, p256Key)
+		credential := testCredential()
+		issuer, _ := ssi.ParseURI("did:example:rdfc")
+		credential.Issuer = *issuer
+		require.NoError(t, SignCredential(&credential, p256Key, keyID, ProofOptions{Suite: EcdsaJCS2019Suite{}}))
+		proofs, _ := credential.Proofs()
+		proofs[0].Cryptosuite = EcdsaRDFC2019Cryptosuite
+		credential.Proof = []interface{}{proofs[0]}
+
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.Nil(t, FindProofSuite(ssi.DataIntegrityProof, EcdsaRDFC2019Cryptosuite))
+		assert.Equal(t, ProofTypeCheck, failedCheck(result.Proofs[0]))
+		assert.EqualError(t, result.Proofs[0].Checks[0].Error, "unsupported proof type: DataIntegrityProof (ecdsa-rdfc-2019)")
+	})
+	t.Run("wrong key types", func(t *testing.T) {
+		keyID, _ := ssi.ParseURI("did:example:issuer#key-1")
+		_, ed25519Key, _ := ed25519.GenerateKey(rand.Reader)
+		for suite, key := range map[ProofSuite]crypto.Signer{
+			EcdsaSecp256k1Signature2019Suite{}: p256Key,
+			EcdsaJCS2019Suite{}:                secp256k1Key,
+		} {
+			credential := testCredential()
+			assert.Error(t, SignCredential(&credential, key, *keyID, ProofOptions{Suite: suite}))
+			assert.Error(t, SignCredential(&credential, ed25519Key, *keyID, ProofOptions{Suite: suite}))
+		}
+	})
+}
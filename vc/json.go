@@ -22,6 +22,13 @@ import "github.com/ugradid/ugradid-common/marshal"
 const contextKey = "@context"
 const typeKey = "type"
 const credentialSubjectKey = "credentialSubject"
+const credentialSchemaKey = "credentialSchema"
+const issuerKey = "issuer"
+const issuanceDateKey = "issuanceDate"
+const expirationDateKey = "expirationDate"
+const validFromKey = "validFrom"
+const validUntilKey = "validUntil"
 const proofKey = "proof"
+const verifiableCredentialKey = "verifiableCredential"
 
 var pluralContext = marshal.Plural(contextKey)
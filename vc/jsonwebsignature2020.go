@@ -0,0 +1,161 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/jcs"
+	"github.com/ugradid/ugradid-common/signature"
+)
+
+// JSONWebSignature2020Suite creates JsonWebSignature2020 proofs (https://w3c-ccg.github.io/lds-jws2020/).
+// Documents and proof options are canonicalized using JCS (RFC 8785) rather than RDF dataset canonicalization,
+// so proofs can be created and verified without JSON-LD processing. The signature is a JWS with detached,
+// unencoded payload (RFC 7797) with an alg according to the signing key: EdDSA, ES256, ES384, ES256K, or PS256 or
+// RS256 for RSA keys.
+type JSONWebSignature2020Suite struct {
+	// Algorithm overrides the JWS algorithm used for signing, e.g. RS256 instead of the default PS256 for RSA keys.
+	// Verification accepts any algorithm matching the verification method's key.
+	Algorithm signature.Algorithm
+}
+
+// ProofType returns JsonWebSignature2020.
+func (s JSONWebSignature2020Suite) ProofType() ssi.ProofType {
+	return ssi.JsonWebSignature2020
+}
+
+// Cryptosuite returns an empty string, since JsonWebSignature2020 isn't a DataIntegrityProof.
+func (s JSONWebSignature2020Suite) Cryptosuite() string {
+	return ""
+}
+
+// Sign signs the document and returns a JSONWebSignature2020Proof. See CreateProof.
+func (s JSONWebSignature2020Suite) Sign(document interface{}, proof Proof, signer crypto.Signer) (interface{}, error) {
+	result, err := s.CreateProof(document, proof, signer)
+	if err != nil {
+		return nil, err
+	}
+	return *result, nil
+}
+
+// Verify verifies the JSONWebSignature2020Proof given as JSON. See VerifyProof.
+func (s JSONWebSignature2020Suite) Verify(document interface{}, proof json.RawMessage, publicKey crypto.PublicKey) error {
+	var target JSONWebSignature2020Proof
+	if err := json.Unmarshal(proof, &target); err != nil {
+		return err
+	}
+	return s.VerifyProof(document, target, publicKey)
+}
+
+// CreateProof signs the given document (without its proof) and returns the proof, which is the given proof options
+// completed with the JWS.
+func (s JSONWebSignature2020Suite) CreateProof(document interface{}, options Proof, signer crypto.Signer) (*JSONWebSignature2020Proof, error) {
+	options.Type = ssi.JsonWebSignature2020
+	options.Cryptosuite = ""
+	jws, err := createJWS(document, options, signer, s.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONWebSignature2020Proof{Proof: options, Jws: jws}, nil
+}
+
+// VerifyProof verifies the proof's JWS over the given document (without its proof) using the given public key.
+func (s JSONWebSignature2020Suite) VerifyProof(document interface{}, proof JSONWebSignature2020Proof, publicKey crypto.PublicKey) error {
+	return verifyJWS(document, proof.Proof, proof.Jws, publicKey)
+}
+
+// verifyData returns the data that is signed by JsonWebSignature2020 proofs.
+func (s JSONWebSignature2020Suite) verifyData(document interface{}, options Proof) ([]byte, error) {
+	return jwsVerifyData(document, options)
+}
+
+// createJWS returns a JWS with detached, unencoded payload over the verify data of the document and proof options.
+// When no algorithm is given, it is derived from the signer's key.
+func createJWS(document interface{}, options Proof, signer crypto.Signer, algorithm signature.Algorithm) (string, error) {
+	verifyData, err := jwsVerifyData(document, options)
+	if err != nil {
+		return "", err
+	}
+	var headers map[string]interface{}
+	if algorithm != "" {
+		headers = map[string]interface{}{"alg": algorithm}
+	}
+	jws, err := signature.SignDetachedJWS(signer, verifyData, headers)
+	if err != nil {
+		return "", fmt.Errorf("unable to sign document: %w", err)
+	}
+	return jws, nil
+}
+
+// verifyJWS verifies the JWS with detached payload over the verify data of the document and proof options.
+func verifyJWS(document interface{}, options Proof, jws string, publicKey crypto.PublicKey) error {
+	if jws == "" {
+		return errors.New("proof has no jws")
+	}
+	verifyData, err := jwsVerifyData(document, options)
+	if err != nil {
+		return err
+	}
+	_, err = signature.VerifyDetachedJWS(jws, verifyData, publicKey)
+	return err
+}
+
+// jwsVerifyData returns the data that is signed: the SHA-256 hash of the canonicalized proof options followed by
+// the SHA-256 hash of the canonicalized document without proof.
+func jwsVerifyData(document interface{}, options Proof) ([]byte, error) {
+	canonicalOptions, err := jcs.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+	canonicalDocument, err := canonicalizeWithoutProof(document)
+	if err != nil {
+		return nil, err
+	}
+	optionsHash := sha256.Sum256(canonicalOptions)
+	documentHash := sha256.Sum256(canonicalDocument)
+	return append(optionsHash[:], documentHash[:]...), nil
+}
+
+// canonicalizeWithoutProof returns the JCS canonical form of the given document with its proof removed.
+func canonicalizeWithoutProof(document interface{}) ([]byte, error) {
+	members, err := jsonMembers(document)
+	if err != nil {
+		return nil, err
+	}
+	delete(members, proofKey)
+	return jcs.Marshal(members)
+}
+
+// jsonMembers returns the members of the JSON object the given value marshals to.
+func jsonMembers(value interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, fmt.Errorf("not a JSON object: %w", err)
+	}
+	return members, nil
+}
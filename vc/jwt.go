@@ -0,0 +1,491 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwt"
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/did"
+	"github.com/ugradid/ugradid-common/signature"
+)
+
+const (
+	vcClaim    = "vc"
+	vpClaim    = "vp"
+	nonceClaim = "nonce"
+	algHeader  = "alg"
+	kidHeader  = "kid"
+	typHeader  = "typ"
+	jwtType    = "JWT"
+)
+
+// SignCredentialJWT encodes the credential as a JWT according to VC-JWT (https://www.w3.org/TR/vc-data-model/#json-web-token)
+// and signs it with the given signer, which must hold the private key of the given verification method of the issuer.
+// The credential's issuer, issuanceDate (or validFrom), expirationDate (or validUntil), id and credentialSubject id are
+// mapped to the iss, nbf, exp, jti and sub claims, where sub is only set for credentials with a single subject.
+// The credential itself, without its proofs, is the vc claim.
+func SignCredentialJWT(credential VerifiableCredential, signer crypto.Signer, verificationMethod ssi.URI) (string, error) {
+	members, err := jsonMembers(credential)
+	if err != nil {
+		return "", err
+	}
+	delete(members, proofKey)
+	claims := map[string]interface{}{
+		jwt.IssuerKey: credential.Issuer.String(),
+		vcClaim:       members,
+	}
+	validFrom, validUntil := credential.Validity()
+	if !validFrom.IsZero() {
+		claims[jwt.NotBeforeKey] = validFrom
+	}
+	if validUntil != nil {
+		claims[jwt.ExpirationKey] = *validUntil
+	}
+	if credential.ID != nil {
+		claims[jwt.JwtIDKey] = credential.ID.String()
+	}
+	if subject, ok := credential.CredentialSubject["id"].(string); ok && len(credential.AdditionalSubjects) == 0 {
+		claims[jwt.SubjectKey] = subject
+	}
+	return signJWT(claims, signer, jwtHeaders(verificationMethod))
+}
+
+// SignPresentationJWT encodes the presentation as a JWT according to VC-JWT and signs it with the given signer, which
+// must hold the private key of the given verification method of the holder. The presentation's holder and id are
+// mapped to the iss and jti claims. The presentation itself, without its proofs, is the vp claim.
+// To bind the presentation to a verifier, the verifier's domain and challenge should be passed in the options, which
+// are mapped to the aud and nonce claims. The options' created and expires are mapped to the iat, nbf and exp claims,
+// other options are ignored.
+func SignPresentationJWT(presentation VerifiablePresentation, signer crypto.Signer, verificationMethod ssi.URI, options ProofOptions) (string, error) {
+	if presentation.Holder == nil {
+		return "", errors.New("presentation has no holder")
+	}
+	members, err := jsonMembers(presentation)
+	if err != nil {
+		return "", err
+	}
+	delete(members, proofKey)
+	proof := options.proof(verificationMethod, AuthenticationProofPurpose)
+	claims := map[string]interface{}{
+		jwt.IssuerKey:    presentation.Holder.String(),
+		jwt.IssuedAtKey:  proof.Created,
+		jwt.NotBeforeKey: proof.Created,
+		vpClaim:          members,
+	}
+	if presentation.ID != nil {
+		claims[jwt.JwtIDKey] = presentation.ID.String()
+	}
+	if proof.Expires != nil {
+		claims[jwt.ExpirationKey] = *proof.Expires
+	}
+	if proof.Domain != nil {
+		claims[jwt.AudienceKey] = *proof.Domain
+	}
+	if proof.Challenge != nil {
+		claims[nonceClaim] = *proof.Challenge
+	}
+	return signJWT(claims, signer, jwtHeaders(verificationMethod))
+}
+
+// ParseCredentialJWT decodes a JWT-encoded credential (VC-JWT) without verifying it. Properties absent from the vc
+// claim are taken from the iss, nbf, exp, jti and sub claims. Use Verifier.VerifyCredentialJWT to verify the JWT.
+func ParseCredentialJWT(token string) (*VerifiableCredential, error) {
+	_, claims, err := parseJWT(token)
+	if err != nil {
+		return nil, err
+	}
+	return credentialFromClaims(claims)
+}
+
+// ParsePresentationJWT decodes a JWT-encoded presentation (VC-JWT) without verifying it. Properties absent from the vp
+// claim are taken from the iss and jti claims. Presented credentials may be embedded as JSON or as JWT, the latter
+// are decoded using ParseCredentialJWT. Use Verifier.VerifyPresentationJWT to verify the JWT.
+func ParsePresentationJWT(token string) (*VerifiablePresentation, error) {
+	_, claims, err := parseJWT(token)
+	if err != nil {
+		return nil, err
+	}
+	presentation, _, err := presentationFromClaims(claims)
+	return presentation, err
+}
+
+// VerifyCredentialJWT verifies a JWT-encoded credential (VC-JWT) and returns the decoded credential.
+// The JWT must be signed by an assertionMethod of the issuer, identified by the kid header, and be valid according to
+// its nbf and exp claims at the time given in the options, and its status is checked like Verify does. Failed checks
+// are reported in the result, in which the JWT is the credential's single proof. An error is only returned when the JWT can't be decoded.
+func (v Verifier) VerifyCredentialJWT(ctx context.Context, token string, options VerificationOptions) (*VerifiableCredential, *VerificationResult, error) {
+	headers, claims, err := parseJWT(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	credential, err := credentialFromClaims(claims)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := v.verifyJWT(ctx, token, headers, claims, proofRequirements{
+		controller:   &credential.Issuer,
+		proofPurpose: AssertionMethodProofPurpose,
+		challenge:    options.Challenge,
+		domain:       options.Domain,
+		validAt:      options.validAt(),
+	})
+	checkValidity(*credential, options, result)
+	checkStatus(ctx, *credential, options, result)
+	return credential, result, nil
+}
+
+// VerifyPresentationJWT verifies a JWT-encoded presentation (VC-JWT) and every presented credential, and returns the
+// decoded presentation. The JWT must be signed by an authentication method of the holder, identified by the kid header.
+// When the options hold a challenge or domain, the JWT's nonce and aud claims must match them.
+// Failed checks are reported in the result; an error is only returned when the JWT can't be decoded.
+func (v Verifier) VerifyPresentationJWT(ctx context.Context, token string, options VerificationOptions) (*VerifiablePresentation, *VerificationResult, error) {
+	headers, claims, err := parseJWT(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	presentation, credentials, err := presentationFromClaims(claims)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := v.verifyJWT(ctx, token, headers, claims, proofRequirements{
+		controller:   presentation.Holder,
+		proofPurpose: AuthenticationProofPurpose,
+		challenge:    options.Challenge,
+		domain:       options.Domain,
+		validAt:      options.validAt(),
+	})
+	for _, credential := range credentials {
+		// The challenge and domain bind the presentation, not the credentials issued before it
+		credentialOptions := VerificationOptions{ValidAt: options.ValidAt, SkipStatus: options.SkipStatus}
+		var credentialResult *VerificationResult
+		if credential.jwt != "" {
+			_, credentialResult, err = v.VerifyCredentialJWT(ctx, credential.jwt, credentialOptions)
+		} else {
+			credentialResult, err = v.Verify(ctx, credential.credential, credentialOptions)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		result.Credentials = append(result.Credentials, *credentialResult)
+	}
+	return presentation, result, nil
+}
+
+// verifyJWT verifies the JWT's signature and claims against the requirements. The JWT is reported as the single proof
+// of the document, of type JwtProof2020.
+func (v Verifier) verifyJWT(ctx context.Context, token string, headers map[string]interface{}, claims jwt.Token, requirements proofRequirements) *VerificationResult {
+	return &VerificationResult{
+		Checks: []CheckResult{{Check: ProofCheck}},
+		Proofs: []ProofResult{v.verifyJWTProof(ctx, token, headers, claims, requirements)},
+	}
+}
+
+func (v Verifier) verifyJWTProof(ctx context.Context, token string, headers map[string]interface{}, claims jwt.Token, requirements proofRequirements) ProofResult {
+	proof, vmErr := jwtProof(headers, claims, requirements.proofPurpose)
+	result := ProofResult{Proof: proof}
+	check := func(name Check, err error) bool {
+		result.Checks = append(result.Checks, CheckResult{Check: name, Error: err})
+		return err == nil
+	}
+
+	var err error
+	if alg, _ := headers[algHeader].(string); alg == "" || strings.EqualFold(alg, "none") {
+		err = errors.New("JWT must be signed")
+	}
+	if !check(ProofTypeCheck, err) {
+		return result
+	}
+	if proof.Expires != nil && !check(ExpiresCheck, checkExpiry(*proof.Expires, requirements.validAt)) {
+		return result
+	}
+	if notBefore := claims.NotBefore(); !notBefore.IsZero() && !check(NotBeforeCheck, checkNotBefore(notBefore, requirements.validAt)) {
+		return result
+	}
+	if requirements.challenge != "" && !check(ChallengeCheck, checkBinding("challenge", proof.Challenge, requirements.challenge)) {
+		return result
+	}
+	if requirements.domain != "" && !check(DomainCheck, checkAudience(claims.Audience(), requirements.domain)) {
+		return result
+	}
+	if vmErr != nil {
+		check(VerificationMethodCheck, vmErr)
+		return result
+	}
+	vmID, vmDocument, vm, err := v.resolveVerificationMethod(ctx, proof.VerificationMethod, make(map[string]*did.Document))
+	if !check(VerificationMethodCheck, err) {
+		return result
+	}
+	if !check(ControllerCheck, checkController(vmID, vmDocument, vm, requirements.controller)) {
+		return result
+	}
+	if !check(ProofPurposeCheck, checkProofPurpose(vmID, *vmDocument, proof.ProofPurpose)) {
+		return result
+	}
+	publicKey, err := vm.PublicKey()
+	if err == nil {
+		_, _, err = signature.VerifyJWS(token, publicKey)
+	}
+	check(SignatureCheck, err)
+	return result
+}
+
+// jwtProof returns the generic proof fields of a JWT. The verification method is taken from the kid header, which may
+// be relative to the DID in the iss claim. An error is returned when the kid is missing or invalid.
+func jwtProof(headers map[string]interface{}, claims jwt.Token, proofPurpose string) (Proof, error) {
+	proof := Proof{Type: ssi.JwtProof2020, ProofPurpose: proofPurpose, Created: claims.IssuedAt()}
+	if expires := claims.Expiration(); !expires.IsZero() {
+		proof.Expires = &expires
+	}
+	if audience := claims.Audience(); len(audience) == 1 {
+		proof.Domain = &audience[0]
+	}
+	if nonce, ok := claims.Get(nonceClaim); ok {
+		if challenge, ok := nonce.(string); ok {
+			proof.Challenge = &challenge
+		}
+	}
+	kid, _ := headers[kidHeader].(string)
+	if kid == "" {
+		return proof, errors.New("JWT has no kid header")
+	}
+	if strings.HasPrefix(kid, "#") {
+		kid = claims.Issuer() + kid
+	}
+	verificationMethod, err := ssi.ParseURI(kid)
+	if err != nil {
+		return proof, fmt.Errorf("invalid kid header: %w", err)
+	}
+	proof.VerificationMethod = *verificationMethod
+	return proof, nil
+}
+
+// checkNotBefore checks the JWT is valid at the given time according to its nbf claim.
+func checkNotBefore(notBefore time.Time, validAt time.Time) error {
+	if validAt.Before(notBefore) {
+		return fmt.Errorf("JWT is not valid before %s", notBefore.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkAudience checks the JWT's aud claim contains the expected domain.
+func checkAudience(audience []string, domain string) error {
+	for _, value := range audience {
+		if value == domain {
+			return nil
+		}
+	}
+	return errors.New("JWT audience does not contain expected domain")
+}
+
+func jwtHeaders(verificationMethod ssi.URI) map[string]interface{} {
+	return map[string]interface{}{typHeader: jwtType, kidHeader: verificationMethod.String()}
+}
+
+// signJWT builds the JWT claims using jwx and signs them using the signature package, which also creates the JWS of
+// Linked Data proofs. The jwx version in use supports neither EdDSA nor ES256K and only signs with in-memory ECDSA and
+// RSA private keys, while issuers may use Ed25519 and secp256k1 keys or any crypto.Signer (e.g. backed by an HSM).
+// For the same reason, JWT signatures are verified using signature.VerifyJWS.
+func signJWT(claims map[string]interface{}, signer crypto.Signer, headers map[string]interface{}) (string, error) {
+	token := jwt.New()
+	for name, value := range claims {
+		if err := token.Set(name, value); err != nil {
+			return "", err
+		}
+	}
+	payload, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	result, err := signature.SignJWS(signer, payload, headers)
+	if err != nil {
+		return "", fmt.Errorf("unable to sign JWT: %w", err)
+	}
+	return result, nil
+}
+
+func parseJWT(token string) (map[string]interface{}, jwt.Token, error) {
+	headers, payload, err := signature.ParseJWS(token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid JWT: %w", err)
+	}
+	claims := jwt.New()
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, nil, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+	return headers, claims, nil
+}
+
+func credentialFromClaims(claims jwt.Token) (*VerifiableCredential, error) {
+	var credential VerifiableCredential
+	if err := unmarshalClaim(claims, vcClaim, &credential); err != nil {
+		return nil, err
+	}
+	if issuer := claims.Issuer(); issuer != "" {
+		if err := mapURIClaim(jwt.IssuerKey, issuer, &credential.Issuer); err != nil {
+			return nil, err
+		}
+	}
+	if credential.Version() == 2 {
+		if notBefore := claims.NotBefore(); !notBefore.IsZero() && credential.ValidFrom == nil {
+			credential.ValidFrom = &notBefore
+		}
+		if expires := claims.Expiration(); !expires.IsZero() && credential.ValidUntil == nil {
+			credential.ValidUntil = &expires
+		}
+	} else {
+		if notBefore := claims.NotBefore(); !notBefore.IsZero() && credential.IssuanceDate.IsZero() {
+			credential.IssuanceDate = notBefore
+		}
+		if expires := claims.Expiration(); !expires.IsZero() && credential.ExpirationDate == nil {
+			credential.ExpirationDate = &expires
+		}
+	}
+	if id := claims.JwtID(); id != "" {
+		if credential.ID == nil {
+			credential.ID = &ssi.URI{}
+		}
+		if err := mapURIClaim(jwt.JwtIDKey, id, credential.ID); err != nil {
+			return nil, err
+		}
+	}
+	if subject := claims.Subject(); subject != "" {
+		if credential.CredentialSubject == nil {
+			credential.CredentialSubject = make(map[string]interface{})
+		}
+		if _, ok := credential.CredentialSubject["id"]; !ok {
+			credential.CredentialSubject["id"] = subject
+		}
+	}
+	return &credential, nil
+}
+
+// presentedCredential is a credential in a JWT-encoded presentation. It's either embedded as JSON or as JWT, in which
+// case the credential is decoded from the JWT.
+type presentedCredential struct {
+	credential VerifiableCredential
+	jwt        string
+}
+
+func presentationFromClaims(claims jwt.Token) (*VerifiablePresentation, []presentedCredential, error) {
+	var members map[string]json.RawMessage
+	if err := unmarshalClaim(claims, vpClaim, &members); err != nil {
+		return nil, nil, err
+	}
+	credentials, err := presentedCredentials(members[verifiableCredentialKey])
+	if err != nil {
+		return nil, nil, err
+	}
+	delete(members, verifiableCredentialKey)
+	data, err := json.Marshal(members)
+	if err != nil {
+		return nil, nil, err
+	}
+	var presentation VerifiablePresentation
+	if err := json.Unmarshal(data, &presentation); err != nil {
+		return nil, nil, fmt.Errorf("invalid %s claim: %w", vpClaim, err)
+	}
+	for _, credential := range credentials {
+		presentation.VerifiableCredential = append(presentation.VerifiableCredential, credential.credential)
+	}
+	if holder := claims.Issuer(); holder != "" {
+		if presentation.Holder == nil {
+			presentation.Holder = &ssi.URI{}
+		}
+		if err := mapURIClaim(jwt.IssuerKey, holder, presentation.Holder); err != nil {
+			return nil, nil, err
+		}
+	}
+	if id := claims.JwtID(); id != "" {
+		if presentation.ID == nil {
+			presentation.ID = &ssi.URI{}
+		}
+		if err := mapURIClaim(jwt.JwtIDKey, id, presentation.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+	return &presentation, credentials, nil
+}
+
+// presentedCredentials decodes the credentials of a vp claim, which can be a single credential or a list.
+func presentedCredentials(data json.RawMessage) ([]presentedCredential, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		entries = []json.RawMessage{data}
+	}
+	var result []presentedCredential
+	for _, entry := range entries {
+		var token string
+		if err := json.Unmarshal(entry, &token); err == nil {
+			credential, err := ParseCredentialJWT(token)
+			if err != nil {
+				return nil, fmt.Errorf("invalid presented credential: %w", err)
+			}
+			result = append(result, presentedCredential{credential: *credential, jwt: token})
+			continue
+		}
+		var credential VerifiableCredential
+		if err := json.Unmarshal(entry, &credential); err != nil {
+			return nil, fmt.Errorf("invalid presented credential: %w", err)
+		}
+		result = append(result, presentedCredential{credential: credential})
+	}
+	return result, nil
+}
+
+// unmarshalClaim unmarshals the value of the given claim into the target.
+func unmarshalClaim(claims jwt.Token, name string, target interface{}) error {
+	value, ok := claims.Get(name)
+	if !ok {
+		return fmt.Errorf("invalid JWT: no %s claim", name)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("invalid %s claim: %w", name, err)
+	}
+	return nil
+}
+
+// mapURIClaim sets the target to the value of a JWT claim, unless the target is already set to a different value.
+func mapURIClaim(name string, value string, target *ssi.URI) error {
+	if current := target.String(); current != "" {
+		if current != value {
+			return fmt.Errorf("invalid JWT: %s claim does not match %s", name, current)
+		}
+		return nil
+	}
+	uri, err := ssi.ParseURI(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s claim: %w", name, err)
+	}
+	*target = *uri
+	return nil
+}
@@ -0,0 +1,320 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/did"
+	"github.com/ugradid/ugradid-common/signature"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignCredentialJWT(t *testing.T) {
+	issuer := newTestIssuer(t, "did:example:issuer", (*did.Document).AddAssertionMethod)
+	credential := testCredential()
+	id, _ := ssi.ParseURI("did:example:issuer#credential-1")
+	credential.ID = id
+	expires := time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC)
+	credential.ExpirationDate = &expires
+
+	token, err := SignCredentialJWT(credential, issuer.key, issuer.keyID)
+	require.NoError(t, err)
+
+	t.Run("claims", func(t *testing.T) {
+		headers, payload, err := signature.ParseJWS(token)
+		require.NoError(t, err)
+		assert.Equal(t, "JWT", headers["typ"])
+		assert.Equal(t, "EdDSA", headers["alg"])
+		assert.Equal(t, issuer.keyID.String(), headers["kid"])
+		var claims map[string]interface{}
+		require.NoError(t, json.Unmarshal(payload, &claims))
+		assert.Equal(t, "did:example:issuer", claims["iss"])
+		assert.Equal(t, "did:example:subject", claims["sub"])
+		assert.Equal(t, "did:example:issuer#credential-1", claims["jti"])
+		assert.Equal(t, float64(credential.IssuanceDate.Unix()), claims["nbf"])
+		assert.Equal(t, float64(expires.Unix()), claims["exp"])
+		assert.NotContains(t, claims["vc"], "proof")
+	})
+	t.Run("parse", func(t *testing.T) {
+		parsed, err := ParseCredentialJWT(token)
+
+		require.NoError(t, err)
+		assert.Equal(t, credential.Issuer, parsed.Issuer)
+		assert.Equal(t, credential.ID, parsed.ID)
+		assert.True(t, credential.IssuanceDate.Equal(parsed.IssuanceDate))
+		assert.True(t, expires.Equal(*parsed.ExpirationDate))
+		assert.Equal(t, credential.CredentialSubject, parsed.CredentialSubject)
+		assert.Empty(t, parsed.Proof)
+	})
+	t.Run("parse - properties only in claims", func(t *testing.T) {
+		token, err := signature.SignJWS(issuer.key, []byte(`{"iss":"did:example:issuer","sub":"did:example:subject","nbf":1609459200,"jti":"urn:uuid:1234",`+
+			`"vc":{"@context":["https://www.w3.org/2018/credentials/v1"],"type":["VerifiableCredential"],"credentialSubject":{"name":"Alice"}}}`), nil)
+		require.NoError(t, err)
+
+		parsed, err := ParseCredentialJWT(token)
+
+		require.NoError(t, err)
+		assert.Equal(t, "did:example:issuer", parsed.Issuer.String())
+		assert.Equal(t, "urn:uuid:1234", parsed.ID.String())
+		assert.True(t, credential.IssuanceDate.Equal(parsed.IssuanceDate))
+		assert.Equal(t, "did:example:subject", parsed.CredentialSubject["id"])
+	})
+	t.Run("parse - VC Data Model 2.0", func(t *testing.T) {
+		token, err := signature.SignJWS(issuer.key, []byte(`{"iss":"did:example:issuer","nbf":1609459200,"exp":1924992000,`+
+			`"vc":{"@context":["https://www.w3.org/ns/credentials/v2"],"type":["VerifiableCredential"],"credentialSubject":{"name":"Alice"}}}`), nil)
+		require.NoError(t, err)
+
+		parsed, err := ParseCredentialJWT(token)
+
+		require.NoError(t, err)
+		assert.True(t, parsed.IssuanceDate.IsZero())
+		require.NotNil(t, parsed.ValidFrom)
+		assert.True(t, credential.IssuanceDate.Equal(*parsed.ValidFrom))
+		require.NotNil(t, parsed.ValidUntil)
+		assert.Nil(t, parsed.ExpirationDate)
+	})
+	t.Run("parse - issuer does not match iss", func(t *testing.T) {
+		token, err := signature.SignJWS(issuer.key, []byte(`{"iss":"did:example:issuer","vc":{"issuer":"did:example:other"}}`), nil)
+		require.NoError(t, err)
+
+		_, err = ParseCredentialJWT(token)
+
+		assert.EqualError(t, err, "invalid JWT: iss claim does not match did:example:other")
+	})
+	t.Run("parse - no vc claim", func(t *testing.T) {
+		token, _ := signature.SignJWS(issuer.key, []byte(`{"iss":"did:example:issuer"}`), nil)
+
+		_, err := ParseCredentialJWT(token)
+
+		assert.EqualError(t, err, "invalid JWT: no vc claim")
+	})
+}
+
+func TestVerifier_VerifyCredentialJWT(t *testing.T) {
+	ctx := context.Background()
+	store := did.NewMemoryStore()
+	issuer := newTestIssuer(t, "did:example:issuer", (*did.Document).AddAssertionMethod)
+	_, err := store.Put(issuer.document, time.Now())
+	require.NoError(t, err)
+	verifier := NewVerifier(store)
+	credential := testCredential()
+
+	t.Run("ok", func(t *testing.T) {
+		token, err := SignCredentialJWT(credential, issuer.key, issuer.keyID)
+		require.NoError(t, err)
+
+		parsed, result, err := verifier.VerifyCredentialJWT(ctx, token, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+		assert.Equal(t, credential.CredentialSubject, parsed.CredentialSubject)
+		require.Len(t, result.Proofs, 1)
+		assert.Equal(t, ssi.JwtProof2020, result.Proofs[0].Proof.Type)
+		assert.Equal(t, issuer.keyID, result.Proofs[0].Proof.VerificationMethod)
+		assert.Equal(t, []Check{ProofTypeCheck, NotBeforeCheck, VerificationMethodCheck, ControllerCheck, ProofPurposeCheck, SignatureCheck},
+			checks(result.Proofs[0]))
+	})
+	t.Run("ok - relative kid", func(t *testing.T) {
+		_, payload, _ := signature.ParseJWS(mustSignCredentialJWT(t, credential, issuer))
+		token, err := signature.SignJWS(issuer.key, payload, map[string]interface{}{"kid": "#key-1"})
+		require.NoError(t, err)
+
+		_, result, err := verifier.VerifyCredentialJWT(ctx, token, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+	})
+	t.Run("tampered", func(t *testing.T) {
+		token := mustSignCredentialJWT(t, credential, issuer)
+		headers, payload, _ := signature.ParseJWS(token)
+		other, _ := signature.SignJWS(issuer.key, []byte(strings.Replace(string(payload), "Alice", "Mallory", 1)), headers)
+		parts := strings.Split(other, ".")
+		tampered := parts[0] + "." + parts[1] + "." + strings.Split(token, ".")[2]
+
+		_, result, err := verifier.VerifyCredentialJWT(ctx, tampered, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, SignatureCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("expired", func(t *testing.T) {
+		expired := credential
+		expires := time.Now().Add(-time.Hour)
+		expired.ExpirationDate = &expires
+
+		_, result, err := verifier.VerifyCredentialJWT(ctx, mustSignCredentialJWT(t, expired, issuer), VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, ExpiresCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("not yet valid", func(t *testing.T) {
+		_, result, err := verifier.VerifyCredentialJWT(ctx, mustSignCredentialJWT(t, credential, issuer), VerificationOptions{ValidAt: credential.IssuanceDate.Add(-time.Hour)})
+
+		require.NoError(t, err)
+		assert.Equal(t, NotBeforeCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("not signed by issuer", func(t *testing.T) {
+		other := credential
+		other.Issuer = issuer.document.ID.URI()
+		other.Issuer.Opaque = "example:other"
+
+		_, result, err := verifier.VerifyCredentialJWT(ctx, mustSignCredentialJWT(t, other, issuer), VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, ControllerCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("key is not an assertionMethod", func(t *testing.T) {
+		holder := newTestIssuer(t, "did:example:holder", (*did.Document).AddAuthenticationMethod)
+		_, err := store.Put(holder.document, time.Now())
+		require.NoError(t, err)
+		other := credential
+		other.Issuer = holder.document.ID.URI()
+
+		_, result, err := verifier.VerifyCredentialJWT(ctx, mustSignCredentialJWT(t, other, holder), VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, ProofPurposeCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("no kid", func(t *testing.T) {
+		_, payload, _ := signature.ParseJWS(mustSignCredentialJWT(t, credential, issuer))
+		token, _ := signature.SignJWS(issuer.key, payload, nil)
+
+		_, result, err := verifier.VerifyCredentialJWT(ctx, token, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, VerificationMethodCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("invalid JWT", func(t *testing.T) {
+		_, _, err := verifier.VerifyCredentialJWT(ctx, "not a JWT", VerificationOptions{})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestVerifier_VerifyPresentationJWT(t *testing.T) {
+	ctx := context.Background()
+	store := did.NewMemoryStore()
+	issuer := newTestIssuer(t, "did:example:issuer", (*did.Document).AddAssertionMethod)
+	holder := newTestIssuer(t, "did:example:holder", (*did.Document).AddAuthenticationMethod)
+	for _, document := range []did.Document{issuer.document, holder.document} {
+		_, err := store.Put(document, time.Now())
+		require.NoError(t, err)
+	}
+	verifier := NewVerifier(store)
+	challenge := "c0ae1c8e-c7e7-469f-b252-86e6a0e7387e"
+	domain := "verifier.example.com"
+	options := VerificationOptions{Challenge: challenge, Domain: domain}
+	holderID := holder.document.ID.URI()
+	presentation := VerifiablePresentation{
+		Context:              []ssi.URI{VCContextV1URI()},
+		Type:                 []ssi.URI{VerifiablePresentationTypeV1URI()},
+		Holder:               &holderID,
+		VerifiableCredential: []VerifiableCredential{issuer.credential(t)},
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		token, err := SignPresentationJWT(presentation, holder.key, holder.keyID, ProofOptions{Challenge: &challenge, Domain: &domain})
+		require.NoError(t, err)
+
+		parsed, result, err := verifier.VerifyPresentationJWT(ctx, token, options)
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+		assert.Equal(t, holderID, *parsed.Holder)
+		require.Len(t, result.Credentials, 1)
+		assert.True(t, result.Credentials[0].Verified())
+		proof := result.Proofs[0]
+		assert.Equal(t, AuthenticationProofPurpose, proof.Proof.ProofPurpose)
+		assert.Equal(t, challenge, *proof.Proof.Challenge)
+		assert.Equal(t, domain, *proof.Proof.Domain)
+		assert.Contains(t, checks(proof), ChallengeCheck)
+		assert.Contains(t, checks(proof), DomainCheck)
+	})
+	t.Run("ok - JWT credential", func(t *testing.T) {
+		credential := mustSignCredentialJWT(t, testCredential(), issuer)
+		_, payload, _ := signature.ParseJWS(mustSignPresentationJWT(t, presentation, holder, ProofOptions{Challenge: &challenge, Domain: &domain}))
+		var claims map[string]interface{}
+		require.NoError(t, json.Unmarshal(payload, &claims))
+		claims["vp"].(map[string]interface{})["verifiableCredential"] = credential
+		payload, _ = json.Marshal(claims)
+		token, err := signature.SignJWS(holder.key, payload, map[string]interface{}{"kid": holder.keyID.String()})
+		require.NoError(t, err)
+
+		parsed, result, err := verifier.VerifyPresentationJWT(ctx, token, options)
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+		require.Len(t, parsed.VerifiableCredential, 1)
+		assert.Equal(t, "Alice", parsed.VerifiableCredential[0].CredentialSubject["name"])
+		require.Len(t, result.Credentials, 1)
+		assert.Equal(t, ssi.JwtProof2020, result.Credentials[0].Proofs[0].Proof.Type)
+	})
+	t.Run("wrong challenge", func(t *testing.T) {
+		other := "other"
+		token := mustSignPresentationJWT(t, presentation, holder, ProofOptions{Challenge: &other, Domain: &domain})
+
+		_, result, err := verifier.VerifyPresentationJWT(ctx, token, options)
+
+		require.NoError(t, err)
+		assert.Equal(t, ChallengeCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("wrong audience", func(t *testing.T) {
+		other := "other.example.com"
+		token := mustSignPresentationJWT(t, presentation, holder, ProofOptions{Challenge: &challenge, Domain: &other})
+
+		_, result, err := verifier.VerifyPresentationJWT(ctx, token, options)
+
+		require.NoError(t, err)
+		assert.Equal(t, DomainCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("signed by issuer instead of holder", func(t *testing.T) {
+		token := mustSignPresentationJWT(t, presentation, issuer, ProofOptions{Challenge: &challenge, Domain: &domain})
+
+		_, result, err := verifier.VerifyPresentationJWT(ctx, token, options)
+
+		require.NoError(t, err)
+		assert.Equal(t, ControllerCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("no holder", func(t *testing.T) {
+		withoutHolder := presentation
+		withoutHolder.Holder = nil
+
+		_, err := SignPresentationJWT(withoutHolder, holder.key, holder.keyID, ProofOptions{})
+
+		assert.EqualError(t, err, "presentation has no holder")
+	})
+}
+
+func mustSignCredentialJWT(t *testing.T, credential VerifiableCredential, issuer testIssuer) string {
+	token, err := SignCredentialJWT(credential, issuer.key, issuer.keyID)
+	require.NoError(t, err)
+	return token
+}
+
+func mustSignPresentationJWT(t *testing.T, presentation VerifiablePresentation, holder testIssuer, options ProofOptions) string {
+	token, err := SignPresentationJWT(presentation, holder.key, holder.keyID, options)
+	require.NoError(t, err)
+	return token
+}
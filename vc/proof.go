@@ -18,17 +18,23 @@
 package vc
 
 import (
+	"encoding/json"
 	ssi "github.com/ugradid/ugradid-common"
 	"time"
 )
 
-// Proof represents a credential/presentation proof as defined by the Linked Data Proofs 1.0 specification (https://w3c-ccg.github.io/ld-proofs/).
+// Proof represents a credential/presentation proof as defined by the Linked Data Proofs 1.0 specification (https://w3c-ccg.github.io/ld-proofs/)
+// and its successor, Verifiable Credential Data Integrity 1.0 (https://www.w3.org/TR/vc-data-integrity/).
 // The proof value must be implemented in a custom type since the specification doesn't define the json object for this.
 // For example: a jws for detached JSON Web Signatures uses the 'jws' json field
 type Proof struct {
+	// ID identifies the proof, so it can be referenced by other proofs in a proof chain. It is optional.
+	ID *ssi.URI `json:"id,omitempty"`
 	// Type defines the specific proof type used.
 	// For example, an Ed25519Signature2018 type indicates that the proof includes a digital signature produced by an ed25519 cryptographic key.
 	Type ssi.ProofType `json:"type"`
+	// Cryptosuite identifies the cryptographic suite of a DataIntegrityProof, e.g. eddsa-jcs-2022.
+	Cryptosuite string `json:"cryptosuite,omitempty"`
 	// ProofPurpose defines the intent for the proof, the reason why an entity created it.
 	// Acts as a safeguard to prevent the proof from being misused for a purpose other than the one it was intended for.
 	// For example, a proof can be used for purposes of authentication, for asserting control of a Verifiable Credential (assertionMethod), and several others.
@@ -37,8 +43,43 @@ type Proof struct {
 	VerificationMethod ssi.URI `json:"verificationMethod"`
 	// Created notes when the proof was created using a iso8601 string
 	Created time.Time `json:"created"`
+	// Expires notes when the proof expires. Verification of an expired proof fails. It is optional.
+	Expires *time.Time `json:"expires,omitempty"`
 	// Domain specifies the restricted domain of the proof
 	Domain *string `json:"domain,omitempty"`
+	// Challenge is a value provided by the verifier, to prevent replay of the proof (e.g. of a presentation).
+	Challenge *string `json:"challenge,omitempty"`
+	// Nonce is a value provided by the proof's creator to increase privacy, by preventing the proof from being linked
+	// to other proofs over the same data. It is optional.
+	Nonce *string `json:"nonce,omitempty"`
+	// ProofValue holds the multibase encoded signature of proof types that don't use a JWS, e.g. DataIntegrityProof.
+	ProofValue string `json:"proofValue,omitempty"`
+	// PreviousProof holds the ID(s) of the proof(s) that must be verified before this proof, in case of a proof chain.
+	PreviousProof ProofIDs `json:"previousProof,omitempty"`
+}
+
+// ProofIDs is a list of proof IDs, which is marshalled as a single string when it holds one ID.
+type ProofIDs []string
+
+func (p ProofIDs) MarshalJSON() ([]byte, error) {
+	if len(p) == 1 {
+		return json.Marshal(p[0])
+	}
+	return json.Marshal([]string(p))
+}
+
+func (p *ProofIDs) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*p = ProofIDs{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(b, &multiple); err != nil {
+		return err
+	}
+	*p = multiple
+	return nil
 }
 
 // JSONWebSignature2020Proof is a VC proof with a signature according to JsonWebSignature2020
@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProof_UnmarshalJSON(t *testing.T) {
+	const input = `{
+  "id": "urn:uuid:26329423-bec9-4b2e-88cb-a7c7d9dc4544",
+  "type": "DataIntegrityProof",
+  "cryptosuite": "eddsa-jcs-2022",
+  "proofPurpose": "assertionMethod",
+  "verificationMethod": "did:example:issuer#key-1",
+  "created": "2021-01-01T00:00:00Z",
+  "expires": "2022-01-01T00:00:00Z",
+  "challenge": "abc",
+  "nonce": "123",
+  "previousProof": "urn:uuid:60102d04-b51e-11ed-acfe-2fcd717666a7",
+  "proofValue": "z58DAdFfa9SkqZMVPxAQpic7ndSayn1PzZs6ZjWp1CktyGesjuTSwRdoWhAfGFCF5bppETSTojQCrfFPP2oumHKtz"
+}`
+	var proof Proof
+
+	err := json.Unmarshal([]byte(input), &proof)
+
+	require.NoError(t, err)
+	assert.Equal(t, "urn:uuid:26329423-bec9-4b2e-88cb-a7c7d9dc4544", proof.ID.String())
+	assert.Equal(t, "eddsa-jcs-2022", proof.Cryptosuite)
+	assert.Equal(t, 2022, proof.Expires.Year())
+	assert.Equal(t, "123", *proof.Nonce)
+	assert.Equal(t, ProofIDs{"urn:uuid:60102d04-b51e-11ed-acfe-2fcd717666a7"}, proof.PreviousProof)
+	assert.NotEmpty(t, proof.ProofValue)
+
+	t.Run("previousProof is marshalled as string or array", func(t *testing.T) {
+		data, err := json.Marshal(proof)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"previousProof":"urn:uuid:60102d04-b51e-11ed-acfe-2fcd717666a7"`)
+
+		proof.PreviousProof = append(proof.PreviousProof, "urn:uuid:other")
+		data, err = json.Marshal(proof)
+		require.NoError(t, err)
+		var actual Proof
+		require.NoError(t, json.Unmarshal(data, &actual))
+		assert.Len(t, actual.PreviousProof, 2)
+	})
+}
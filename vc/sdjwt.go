@@ -0,0 +1,729 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwt"
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/did"
+	"github.com/ugradid/ugradid-common/signature"
+)
+
+const (
+	// SDJWTVCType is the typ header of SD-JWT VCs.
+	SDJWTVCType = "dc+sd-jwt"
+	// legacySDJWTVCType is the typ header of SD-JWT VCs used by earlier drafts of SD-JWT VC, which is still accepted.
+	legacySDJWTVCType = "vc+sd-jwt"
+	keyBindingJWTType = "kb+jwt"
+
+	sdClaim        = "_sd"
+	sdAlgClaim     = "_sd_alg"
+	sdHashClaim    = "sd_hash"
+	vctClaim       = "vct"
+	cnfClaim       = "cnf"
+	statusClaim    = "status"
+	arrayDigestKey = "..."
+	sdAlgorithm    = "sha-256"
+	sdSeparator    = "~"
+	saltSize       = 16
+)
+
+// registeredSDJWTClaims are the claims of an SD-JWT VC that aren't claims about the credential subject.
+var registeredSDJWTClaims = map[string]bool{
+	jwt.IssuerKey: true, jwt.SubjectKey: true, jwt.IssuedAtKey: true, jwt.NotBeforeKey: true, jwt.ExpirationKey: true,
+	jwt.JwtIDKey: true, jwt.AudienceKey: true, vctClaim: true, cnfClaim: true, statusClaim: true, sdClaim: true, sdAlgClaim: true,
+}
+
+// SDJWT is a Selective Disclosure JWT (https://datatracker.ietf.org/doc/draft-ietf-oauth-selective-disclosure-jwt/):
+// an issuer-signed JWT in which selectively disclosable claims are replaced by digests, the disclosures of the claims
+// the holder chooses to disclose, and optionally a key binding JWT proving the holder presents it.
+type SDJWT struct {
+	// JWT is the issuer-signed JWT.
+	JWT string
+	// Disclosures holds the disclosures of the disclosed claims.
+	Disclosures []Disclosure
+	// KeyBinding is the key binding JWT, signed by the holder. It is optional.
+	KeyBinding string
+}
+
+// Disclosure discloses a selectively disclosable claim of an SD-JWT: an object property or an array element.
+type Disclosure struct {
+	// Salt is a random value which prevents the claim from being guessed from its digest.
+	Salt string
+	// Name is the name of the disclosed object property. It is empty for array elements.
+	Name string
+	// Value is the value of the disclosed claim.
+	Value interface{}
+	// element indicates the disclosure is of an array element.
+	element bool
+	// encoded is the disclosure as it appears in the SD-JWT. Its digest is computed over this exact encoding.
+	encoded string
+}
+
+// SDJWTOptions holds the parameters for issuing an SD-JWT VC.
+type SDJWTOptions struct {
+	// Disclosable holds the JSON pointers (RFC 6901) of the selectively disclosable claims, relative to the credential
+	// subject, e.g. /address/street_address or /nationalities/0. Claims nested in a disclosable claim can be
+	// disclosable as well.
+	Disclosable []string
+	// HolderKey is the public key of the holder, to which the SD-JWT is bound (cnf claim). It is optional, but without
+	// it presentations can't have a key binding JWT.
+	HolderKey crypto.PublicKey
+	// VCT is the type of the credential (vct claim). Defaults to the credential's last type other than VerifiableCredential.
+	VCT string
+}
+
+// IssueSDJWT issues the credential as SD-JWT VC (https://datatracker.ietf.org/doc/draft-ietf-oauth-sd-jwt-vc/), signed
+// with the given signer, which must hold the private key of the given verification method of the issuer.
+// The credential subject's claims become claims of the JWT, of which the ones given in the options are selectively
+// disclosable. The issuer, issuanceDate (or validFrom), expirationDate (or validUntil), id and credentialSubject id are
+// mapped to the iss, nbf, exp, jti and sub claims. The returned SD-JWT holds the disclosures of all selectively disclosable claims.
+// Credentials with multiple subjects can't be issued as SD-JWT VC.
+func IssueSDJWT(credential VerifiableCredential, signer crypto.Signer, verificationMethod ssi.URI, options SDJWTOptions) (*SDJWT, error) {
+	if len(credential.AdditionalSubjects) > 0 {
+		return nil, errors.New("credential with multiple subjects can't be issued as SD-JWT VC")
+	}
+	var subject map[string]interface{}
+	if err := remarshal(credential.CredentialSubject, &subject); err != nil {
+		return nil, err
+	}
+	for name := range subject {
+		if name != "id" && registeredSDJWTClaims[name] {
+			return nil, fmt.Errorf("credential subject claim %s conflicts with registered SD-JWT VC claim", name)
+		}
+	}
+	vct, err := options.vct(credential)
+	if err != nil {
+		return nil, err
+	}
+	claims := map[string]interface{}{
+		jwt.IssuerKey: credential.Issuer.String(),
+		vctClaim:      vct,
+		sdAlgClaim:    sdAlgorithm,
+	}
+	validFrom, validUntil := credential.Validity()
+	if !validFrom.IsZero() {
+		claims[jwt.IssuedAtKey] = validFrom
+		claims[jwt.NotBeforeKey] = validFrom
+	}
+	if id, ok := subject["id"].(string); ok {
+		claims[jwt.SubjectKey] = id
+		delete(subject, "id")
+	}
+	if validUntil != nil {
+		claims[jwt.ExpirationKey] = *validUntil
+	}
+	if credential.ID != nil {
+		claims[jwt.JwtIDKey] = credential.ID.String()
+	}
+	if options.HolderKey != nil {
+		holderKey, err := did.PublicKeyJWK(options.HolderKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid holder key: %w", err)
+		}
+		claims[cnfClaim] = map[string]interface{}{"jwk": holderKey}
+	}
+
+	c := concealer{disclosable: make(map[string]bool)}
+	for _, pointer := range options.Disclosable {
+		c.disclosable[pointer] = true
+	}
+	concealed, err := c.conceal(subject, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(c.disclosable) > 0 {
+		var missing []string
+		for pointer := range c.disclosable {
+			missing = append(missing, pointer)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("disclosable claims not found in credential subject: %s", strings.Join(missing, ", "))
+	}
+	for name, value := range concealed.(map[string]interface{}) {
+		claims[name] = value
+	}
+
+	token, err := signJWT(claims, signer, map[string]interface{}{typHeader: SDJWTVCType, kidHeader: verificationMethod.String()})
+	if err != nil {
+		return nil, err
+	}
+	return &SDJWT{JWT: token, Disclosures: c.disclosures}, nil
+}
+
+// ParseSDJWT parses an SD-JWT in its compact serialization: <JWT>~<disclosure>~...~<key binding JWT>, without
+// verifying it.
+func ParseSDJWT(token string) (*SDJWT, error) {
+	parts := strings.Split(token, sdSeparator)
+	if len(parts) < 2 {
+		return nil, errors.New("invalid SD-JWT: no separator")
+	}
+	result := &SDJWT{JWT: parts[0], KeyBinding: parts[len(parts)-1]}
+	for _, encoded := range parts[1 : len(parts)-1] {
+		disclosure, err := parseDisclosure(encoded)
+		if err != nil {
+			return nil, err
+		}
+		result.Disclosures = append(result.Disclosures, disclosure)
+	}
+	return result, nil
+}
+
+// String returns the compact serialization of the SD-JWT.
+func (s SDJWT) String() string {
+	return s.withoutKeyBinding() + s.KeyBinding
+}
+
+// Disclose returns a copy of the SD-JWT that only discloses the claims at the given JSON pointers (relative to the
+// credential subject, as in SDJWTOptions), including the claims nested in them. The disclosures of the claims they
+// are nested in are kept as well. Claims that aren't selectively disclosable are always disclosed.
+// Holders use it to select the claims to present. The key binding JWT isn't copied, since it's over the disclosures.
+func (s SDJWT) Disclose(pointers ...string) (*SDJWT, error) {
+	payload, err := sdJWTPayload(s.JWT)
+	if err != nil {
+		return nil, err
+	}
+	r, err := newRevealer(s.Disclosures)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.reveal(payload, ""); err != nil {
+		return nil, err
+	}
+	for _, pointer := range pointers {
+		if !r.claims[pointer] {
+			return nil, fmt.Errorf("claim not found: %s", pointer)
+		}
+	}
+	result := &SDJWT{JWT: s.JWT}
+	for _, disclosure := range s.Disclosures {
+		path, ok := r.paths[disclosure.Digest()]
+		if !ok {
+			continue
+		}
+		for _, pointer := range pointers {
+			if path == pointer || strings.HasPrefix(pointer, path+"/") || strings.HasPrefix(path, pointer+"/") {
+				result.Disclosures = append(result.Disclosures, disclosure)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// Bind returns a copy of the SD-JWT with a key binding JWT, signed with the given signer, which must hold the private
+// key of the holder the SD-JWT is bound to. The audience and nonce are provided by the verifier to bind the
+// presentation to it, and are checked as domain and challenge by Verifier.VerifySDJWT.
+func (s SDJWT) Bind(signer crypto.Signer, audience string, nonce string) (*SDJWT, error) {
+	claims := map[string]interface{}{
+		jwt.IssuedAtKey: time.Now().UTC().Truncate(time.Second),
+		jwt.AudienceKey: audience,
+		nonceClaim:      nonce,
+		sdHashClaim:     s.digest(),
+	}
+	keyBinding, err := signJWT(claims, signer, map[string]interface{}{typHeader: keyBindingJWTType})
+	if err != nil {
+		return nil, err
+	}
+	result := s
+	result.KeyBinding = keyBinding
+	return &result, nil
+}
+
+// VerifySDJWT verifies an SD-JWT VC and returns the credential, of which the credential subject holds the disclosed
+// claims. The issuer-signed JWT must be signed by an assertionMethod of the issuer, identified by the kid header, and be
+// valid according to its nbf and exp claims at the time given in the options. A key binding JWT is verified when
+// present, and required when the options hold a challenge or domain, which must match its nonce and aud claims.
+// Failed checks are reported in the result; an error is returned when the SD-JWT can't be decoded, e.g. because
+// its disclosures don't match its digests.
+func (v Verifier) VerifySDJWT(ctx context.Context, token string, options VerificationOptions) (*VerifiableCredential, *VerificationResult, error) {
+	sdJWT, err := ParseSDJWT(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	headers, claims, err := parseJWT(sdJWT.JWT)
+	if err != nil {
+		return nil, nil, err
+	}
+	if typ, ok := headers[typHeader].(string); ok && typ != SDJWTVCType && typ != legacySDJWTVCType {
+		return nil, nil, fmt.Errorf("invalid SD-JWT VC: unsupported typ: %s", typ)
+	}
+	credential, err := sdJWT.credential(claims)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := v.verifyJWT(ctx, sdJWT.JWT, headers, claims, proofRequirements{
+		controller:   &credential.Issuer,
+		proofPurpose: AssertionMethodProofPurpose,
+		validAt:      options.validAt(),
+	})
+	checkValidity(*credential, options, result)
+	check := func(name Check, err error) bool {
+		result.Checks = append(result.Checks, CheckResult{Check: name, Error: err})
+		return err == nil
+	}
+	if sdJWT.KeyBinding == "" {
+		if options.Challenge != "" || options.Domain != "" {
+			check(KeyBindingCheck, errors.New("SD-JWT has no key binding JWT"))
+		}
+		return credential, result, nil
+	}
+	keyBinding, err := sdJWT.verifyKeyBinding(claims, options.validAt(), options.keyBindingMaxAge())
+	if !check(KeyBindingCheck, err) {
+		return credential, result, nil
+	}
+	if options.Challenge != "" {
+		nonce, _ := keyBinding.Get(nonceClaim)
+		challenge, _ := nonce.(string)
+		if !check(ChallengeCheck, checkBinding("challenge", &challenge, options.Challenge)) {
+			return credential, result, nil
+		}
+	}
+	if options.Domain != "" {
+		check(DomainCheck, checkAudience(keyBinding.Audience(), options.Domain))
+	}
+	return credential, result, nil
+}
+
+// credential returns the credential of the SD-JWT VC with the disclosed claims as credential subject.
+func (s SDJWT) credential(claims jwt.Token) (*VerifiableCredential, error) {
+	payload, err := sdJWTPayload(s.JWT)
+	if err != nil {
+		return nil, err
+	}
+	r, err := newRevealer(s.Disclosures)
+	if err != nil {
+		return nil, err
+	}
+	revealed, err := r.reveal(payload, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, disclosure := range s.Disclosures {
+		if _, ok := r.paths[disclosure.Digest()]; !ok {
+			return nil, fmt.Errorf("invalid SD-JWT: disclosure is not referenced: %s", disclosure)
+		}
+	}
+
+	credential := VerifiableCredential{
+		Context:           []ssi.URI{VCContextV1URI()},
+		Type:              []ssi.URI{VerifiableCredentialTypeV1URI()},
+		IssuanceDate:      claims.NotBefore(),
+		CredentialSubject: make(map[string]interface{}),
+	}
+	if credential.IssuanceDate.IsZero() {
+		credential.IssuanceDate = claims.IssuedAt()
+	}
+	if expires := claims.Expiration(); !expires.IsZero() {
+		credential.ExpirationDate = &expires
+	}
+	if claims.Issuer() == "" {
+		return nil, fmt.Errorf("invalid SD-JWT VC: no %s claim", jwt.IssuerKey)
+	}
+	if err := mapURIClaim(jwt.IssuerKey, claims.Issuer(), &credential.Issuer); err != nil {
+		return nil, err
+	}
+	if id := claims.JwtID(); id != "" {
+		credential.ID = &ssi.URI{}
+		if err := mapURIClaim(jwt.JwtIDKey, id, credential.ID); err != nil {
+			return nil, err
+		}
+	}
+	vct, _ := payload[vctClaim].(string)
+	credentialType, err := ssi.ParseURI(vct)
+	if vct == "" || err != nil {
+		return nil, fmt.Errorf("invalid SD-JWT VC: invalid %s claim", vctClaim)
+	}
+	credential.Type = append(credential.Type, *credentialType)
+	if subject := claims.Subject(); subject != "" {
+		credential.CredentialSubject["id"] = subject
+	}
+	for name, value := range revealed.(map[string]interface{}) {
+		if !registeredSDJWTClaims[name] {
+			credential.CredentialSubject[name] = value
+		}
+	}
+	return &credential, nil
+}
+
+// verifyKeyBinding verifies the key binding JWT is signed by the holder key in the given cnf claim and is over the
+// SD-JWT's JWT and disclosures, and that it was issued no longer than maxAge before validAt. It returns the key binding
+// JWT's claims.
+func (s SDJWT) verifyKeyBinding(claims jwt.Token, validAt time.Time, maxAge time.Duration) (jwt.Token, error) {
+	cnf, _ := claims.Get(cnfClaim)
+	var confirmation struct {
+		JWK map[string]interface{} `json:"jwk"`
+	}
+	if err := remarshal(cnf, &confirmation); err != nil || confirmation.JWK == nil {
+		return nil, errors.New("SD-JWT is not bound to a holder key")
+	}
+	holderKey, err := did.ParsePublicKeyJWK(confirmation.JWK)
+	if err != nil {
+		return nil, fmt.Errorf("invalid holder key: %w", err)
+	}
+	headers, payload, err := signature.VerifyJWS(s.KeyBinding, holderKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key binding JWT: %w", err)
+	}
+	if headers[typHeader] != keyBindingJWTType {
+		return nil, fmt.Errorf("invalid key binding JWT: typ must be %s", keyBindingJWTType)
+	}
+	keyBinding := jwt.New()
+	if err := json.Unmarshal(payload, keyBinding); err != nil {
+		return nil, fmt.Errorf("invalid key binding JWT: %w", err)
+	}
+	issuedAt := keyBinding.IssuedAt()
+	if issuedAt.IsZero() {
+		return nil, fmt.Errorf("invalid key binding JWT: no %s claim", jwt.IssuedAtKey)
+	}
+	if issuedAt.After(validAt) {
+		return nil, fmt.Errorf("key binding JWT is issued in the future: %s", issuedAt.Format(time.RFC3339))
+	}
+	if validAt.Sub(issuedAt) > maxAge {
+		return nil, fmt.Errorf("key binding JWT is too old: issued at %s", issuedAt.Format(time.RFC3339))
+	}
+	if digest, _ := keyBinding.Get(sdHashClaim); digest != s.digest() {
+		return nil, errors.New("key binding JWT is not over the presented SD-JWT")
+	}
+	return keyBinding, nil
+}
+
+// withoutKeyBinding returns the compact serialization of the SD-JWT without key binding JWT: <JWT>~<disclosure>~...~
+func (s SDJWT) withoutKeyBinding() string {
+	var builder strings.Builder
+	builder.WriteString(s.JWT)
+	builder.WriteString(sdSeparator)
+	for _, disclosure := range s.Disclosures {
+		builder.WriteString(disclosure.encoded)
+		builder.WriteString(sdSeparator)
+	}
+	return builder.String()
+}
+
+// digest returns the digest of the SD-JWT, as held by the sd_hash claim of its key binding JWT.
+func (s SDJWT) digest() string {
+	return sdDigest(s.withoutKeyBinding())
+}
+
+func newDisclosure(name string, value interface{}, element bool) (Disclosure, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return Disclosure{}, err
+	}
+	encodedSalt := base64.RawURLEncoding.EncodeToString(salt)
+	members := []interface{}{encodedSalt, name, value}
+	if element {
+		members = []interface{}{encodedSalt, value}
+	}
+	data, err := json.Marshal(members)
+	if err != nil {
+		return Disclosure{}, err
+	}
+	// Parsed from its encoding, so its value is the same as when it's parsed by the holder or verifier
+	return parseDisclosure(base64.RawURLEncoding.EncodeToString(data))
+}
+
+func parseDisclosure(encoded string) (Disclosure, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Disclosure{}, fmt.Errorf("invalid disclosure: %w", err)
+	}
+	var members []interface{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return Disclosure{}, fmt.Errorf("invalid disclosure: %w", err)
+	}
+	disclosure := Disclosure{encoded: encoded}
+	var ok bool
+	switch len(members) {
+	case 2:
+		disclosure.Salt, ok = members[0].(string)
+		disclosure.Value = members[1]
+		disclosure.element = true
+	case 3:
+		disclosure.Salt, ok = members[0].(string)
+		if name, isString := members[1].(string); isString && name != sdClaim && name != arrayDigestKey {
+			disclosure.Name = name
+		} else {
+			ok = false
+		}
+		disclosure.Value = members[2]
+	}
+	if !ok {
+		return Disclosure{}, errors.New("invalid disclosure: expected [salt, name, value] or [salt, value]")
+	}
+	return disclosure, nil
+}
+
+// String returns the encoded disclosure.
+func (d Disclosure) String() string {
+	return d.encoded
+}
+
+// Digest returns the digest of the disclosure, by which the SD-JWT references it.
+func (d Disclosure) Digest() string {
+	return sdDigest(d.encoded)
+}
+
+func sdDigest(value string) string {
+	digest := sha256.Sum256([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// concealer replaces the disclosable claims of a value by digests, collecting their disclosures.
+type concealer struct {
+	// disclosable holds the JSON pointers of the claims to conceal. Concealed claims are removed from it.
+	disclosable map[string]bool
+	disclosures []Disclosure
+}
+
+// conceal returns the value with its disclosable claims replaced by digests. Nested claims are concealed first, so
+// the disclosure of a claim holds the digests of its nested disclosable claims.
+func (c *concealer) conceal(value interface{}, path string) (interface{}, error) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		var digests []string
+		for name, claim := range typed {
+			claimPath := path + "/" + escapePointer(name)
+			concealed, err := c.conceal(claim, claimPath)
+			if err != nil {
+				return nil, err
+			}
+			if !c.disclosable[claimPath] {
+				result[name] = concealed
+				continue
+			}
+			delete(c.disclosable, claimPath)
+			disclosure, err := newDisclosure(name, concealed, false)
+			if err != nil {
+				return nil, err
+			}
+			c.disclosures = append(c.disclosures, disclosure)
+			digests = append(digests, disclosure.Digest())
+		}
+		if len(digests) > 0 {
+			// Sorted, so the digests don't reveal the order of the claims
+			sort.Strings(digests)
+			result[sdClaim] = digests
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(typed))
+		for i, element := range typed {
+			elementPath := path + "/" + strconv.Itoa(i)
+			concealed, err := c.conceal(element, elementPath)
+			if err != nil {
+				return nil, err
+			}
+			if !c.disclosable[elementPath] {
+				result[i] = concealed
+				continue
+			}
+			delete(c.disclosable, elementPath)
+			disclosure, err := newDisclosure("", concealed, true)
+			if err != nil {
+				return nil, err
+			}
+			c.disclosures = append(c.disclosures, disclosure)
+			result[i] = map[string]interface{}{arrayDigestKey: disclosure.Digest()}
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+// revealer replaces the digests in an SD-JWT's payload by the claims of the disclosures.
+type revealer struct {
+	disclosures map[string]Disclosure
+	// paths holds the JSON pointer of the claim of each revealed disclosure, by digest.
+	paths map[string]string
+	// claims holds the JSON pointers of all revealed claims, including claims that aren't selectively disclosable.
+	claims map[string]bool
+}
+
+func newRevealer(disclosures []Disclosure) (*revealer, error) {
+	r := &revealer{disclosures: make(map[string]Disclosure), paths: make(map[string]string), claims: make(map[string]bool)}
+	for _, disclosure := range disclosures {
+		digest := disclosure.Digest()
+		if _, ok := r.disclosures[digest]; ok {
+			return nil, fmt.Errorf("invalid SD-JWT: duplicate disclosure: %s", disclosure)
+		}
+		r.disclosures[digest] = disclosure
+	}
+	return r, nil
+}
+
+// reveal returns the value with the digests of known disclosures replaced by their claims, and other digests removed.
+// The top-level _sd_alg claim must be absent or sha-256, the only supported hash algorithm.
+func (r *revealer) reveal(value interface{}, path string) (interface{}, error) {
+	if path != "" {
+		r.claims[path] = true
+	}
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if path == "" {
+			if algorithm, ok := typed[sdAlgClaim]; ok && algorithm != sdAlgorithm {
+				return nil, fmt.Errorf("invalid SD-JWT: unsupported %s: %v", sdAlgClaim, algorithm)
+			}
+		}
+		result := make(map[string]interface{}, len(typed))
+		for name, claim := range typed {
+			if name == sdClaim || (path == "" && name == sdAlgClaim) {
+				continue
+			}
+			revealed, err := r.reveal(claim, path+"/"+escapePointer(name))
+			if err != nil {
+				return nil, err
+			}
+			result[name] = revealed
+		}
+		digests, ok := typed[sdClaim]
+		if !ok {
+			return result, nil
+		}
+		list, ok := digests.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid SD-JWT: invalid %s claim", sdClaim)
+		}
+		for _, entry := range list {
+			digest, ok := entry.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid SD-JWT: invalid %s claim", sdClaim)
+			}
+			disclosure, err := r.disclosure(digest, false)
+			if err != nil {
+				return nil, err
+			}
+			if disclosure == nil {
+				continue
+			}
+			if _, exists := result[disclosure.Name]; exists {
+				return nil, fmt.Errorf("invalid SD-JWT: claim %s is disclosed more than once", disclosure.Name)
+			}
+			claimPath := path + "/" + escapePointer(disclosure.Name)
+			r.paths[digest] = claimPath
+			revealed, err := r.reveal(disclosure.Value, claimPath)
+			if err != nil {
+				return nil, err
+			}
+			result[disclosure.Name] = revealed
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, 0, len(typed))
+		for i, element := range typed {
+			elementPath := path + "/" + strconv.Itoa(i)
+			if digest, ok := arrayElementDigest(element); ok {
+				disclosure, err := r.disclosure(digest, true)
+				if err != nil {
+					return nil, err
+				}
+				if disclosure == nil {
+					continue
+				}
+				r.paths[digest] = elementPath
+				element = disclosure.Value
+			}
+			revealed, err := r.reveal(element, elementPath)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, revealed)
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+// disclosure returns the disclosure with the given digest, or nil if it isn't disclosed (or is a decoy digest).
+func (r *revealer) disclosure(digest string, element bool) (*Disclosure, error) {
+	disclosure, ok := r.disclosures[digest]
+	if !ok {
+		return nil, nil
+	}
+	if _, ok := r.paths[digest]; ok {
+		return nil, fmt.Errorf("invalid SD-JWT: digest is referenced more than once: %s", digest)
+	}
+	if disclosure.element != element {
+		return nil, fmt.Errorf("invalid SD-JWT: disclosure type does not match its reference: %s", disclosure)
+	}
+	return &disclosure, nil
+}
+
+// arrayElementDigest returns the digest of a selectively disclosable array element: {"...": "<digest>"}.
+func arrayElementDigest(element interface{}) (string, bool) {
+	object, ok := element.(map[string]interface{})
+	if !ok || len(object) != 1 {
+		return "", false
+	}
+	digest, ok := object[arrayDigestKey].(string)
+	return digest, ok
+}
+
+// escapePointer escapes a JSON object member name for use in a JSON pointer (RFC 6901).
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+func (o SDJWTOptions) vct(credential VerifiableCredential) (string, error) {
+	if o.VCT != "" {
+		return o.VCT, nil
+	}
+	for i := len(credential.Type) - 1; i >= 0; i-- {
+		if credential.Type[i].String() != VerifiableCredentialType {
+			return credential.Type[i].String(), nil
+		}
+	}
+	return "", errors.New("credential has no type to use as vct")
+}
+
+func sdJWTPayload(token string) (map[string]interface{}, error) {
+	_, data, err := signature.ParseJWS(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT: %w", err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+	return payload, nil
+}
+
+// remarshal converts the value to the target by marshalling it to JSON and back.
+func remarshal(value interface{}, target interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
@@ -0,0 +1,334 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/did"
+	"github.com/ugradid/ugradid-common/signature"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testIdentityCredential(issuer testIssuer) VerifiableCredential {
+	identityType, _ := ssi.ParseURI("IdentityCredential")
+	return VerifiableCredential{
+		Context:      []ssi.URI{VCContextV1URI()},
+		Type:         []ssi.URI{VerifiableCredentialTypeV1URI(), *identityType},
+		Issuer:       issuer.document.ID.URI(),
+		IssuanceDate: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		CredentialSubject: map[string]interface{}{
+			"id":            "did:example:holder",
+			"given_name":    "Alice",
+			"family_name":   "Smith",
+			"address":       map[string]interface{}{"street_address": "Main Street 1", "locality": "Anytown"},
+			"nationalities": []interface{}{"NL", "DE"},
+		},
+	}
+}
+
+func TestDisclosure(t *testing.T) {
+	t.Run("digest (SD-JWT specification example)", func(t *testing.T) {
+		disclosure, err := parseDisclosure("WyI2cU1RdlJMNWhhaiIsICJmYW1pbHlfbmFtZSIsICJNw7ZiaXVzIl0")
+
+		require.NoError(t, err)
+		assert.Equal(t, "6qMQvRL5haj", disclosure.Salt)
+		assert.Equal(t, "family_name", disclosure.Name)
+		assert.Equal(t, "Möbius", disclosure.Value)
+		assert.Equal(t, "uutlBuYeMDyjLLTpf6Jxi7yNkEF35jdyWMn9U7b_RYY", disclosure.Digest())
+	})
+	t.Run("array element", func(t *testing.T) {
+		disclosure, err := newDisclosure("", "NL", true)
+		require.NoError(t, err)
+
+		parsed, err := parseDisclosure(disclosure.String())
+
+		require.NoError(t, err)
+		assert.True(t, parsed.element)
+		assert.Equal(t, "NL", parsed.Value)
+		assert.Equal(t, disclosure.Digest(), parsed.Digest())
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, members := range []string{`[]`, `["salt"]`, `[1, "name", "value"]`, `["salt", "_sd", "value"]`} {
+			_, err := parseDisclosure(encodeBase64URL(members))
+
+			assert.Error(t, err, members)
+		}
+	})
+}
+
+func TestIssueSDJWT(t *testing.T) {
+	issuer := newTestIssuer(t, "did:example:issuer", (*did.Document).AddAssertionMethod)
+	holderKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	credential := testIdentityCredential(issuer)
+
+	sdJWT, err := IssueSDJWT(credential, issuer.key, issuer.keyID, SDJWTOptions{
+		Disclosable: []string{"/family_name", "/address", "/address/street_address", "/nationalities/1"},
+		HolderKey:   &holderKey.PublicKey,
+	})
+	require.NoError(t, err)
+
+	t.Run("claims", func(t *testing.T) {
+		headers, data, err := signature.ParseJWS(sdJWT.JWT)
+		require.NoError(t, err)
+		assert.Equal(t, SDJWTVCType, headers["typ"])
+		assert.Equal(t, issuer.keyID.String(), headers["kid"])
+		var claims map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &claims))
+		assert.Equal(t, "did:example:issuer", claims["iss"])
+		assert.Equal(t, "did:example:holder", claims["sub"])
+		assert.Equal(t, "IdentityCredential", claims["vct"])
+		assert.Equal(t, "sha-256", claims["_sd_alg"])
+		assert.Equal(t, "Alice", claims["given_name"])
+		assert.NotContains(t, claims, "family_name")
+		assert.NotContains(t, claims, "address")
+		assert.Len(t, claims["_sd"], 2)
+		assert.Equal(t, "NL", claims["nationalities"].([]interface{})[0])
+		assert.Contains(t, claims["nationalities"].([]interface{})[1], "...")
+		assert.Contains(t, claims["cnf"], "jwk")
+		assert.Len(t, sdJWT.Disclosures, 4)
+	})
+	t.Run("serialization round trip", func(t *testing.T) {
+		parsed, err := ParseSDJWT(sdJWT.String())
+
+		require.NoError(t, err)
+		assert.Equal(t, sdJWT.String(), parsed.String())
+		assert.True(t, strings.HasSuffix(parsed.String(), "~"))
+		assert.Empty(t, parsed.KeyBinding)
+	})
+	t.Run("disclosable claim not found", func(t *testing.T) {
+		_, err := IssueSDJWT(credential, issuer.key, issuer.keyID, SDJWTOptions{Disclosable: []string{"/birthdate"}})
+
+		assert.EqualError(t, err, "disclosable claims not found in credential subject: /birthdate")
+	})
+	t.Run("claim conflicts with registered claim", func(t *testing.T) {
+		other := testIdentityCredential(issuer)
+		other.CredentialSubject["iss"] = "did:example:other"
+
+		_, err := IssueSDJWT(other, issuer.key, issuer.keyID, SDJWTOptions{})
+
+		assert.EqualError(t, err, "credential subject claim iss conflicts with registered SD-JWT VC claim")
+	})
+}
+
+func TestVerifier_VerifySDJWT(t *testing.T) {
+	ctx := context.Background()
+	store := did.NewMemoryStore()
+	issuer := newTestIssuer(t, "did:example:issuer", (*did.Document).AddAssertionMethod)
+	_, err := store.Put(issuer.document, time.Now())
+	require.NoError(t, err)
+	verifier := NewVerifier(store)
+	holderKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	nonce := "c0ae1c8e-c7e7-469f-b252-86e6a0e7387e"
+	audience := "verifier.example.com"
+	options := VerificationOptions{Challenge: nonce, Domain: audience}
+	sdJWT, err := IssueSDJWT(testIdentityCredential(issuer), issuer.key, issuer.keyID, SDJWTOptions{
+		Disclosable: []string{"/family_name", "/address", "/address/street_address", "/nationalities/1"},
+		HolderKey:   &holderKey.PublicKey,
+	})
+	require.NoError(t, err)
+
+	present := func(t *testing.T, pointers ...string) string {
+		disclosed, err := sdJWT.Disclose(pointers...)
+		require.NoError(t, err)
+		bound, err := disclosed.Bind(holderKey, audience, nonce)
+		require.NoError(t, err)
+		return bound.String()
+	}
+
+	t.Run("all claims disclosed", func(t *testing.T) {
+		credential, result, err := verifier.VerifySDJWT(ctx, sdJWT.String(), VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+		assert.Equal(t, testIdentityCredential(issuer).CredentialSubject, credential.CredentialSubject)
+		assert.Equal(t, "did:example:issuer", credential.Issuer.String())
+		assert.Equal(t, "IdentityCredential", credential.Type[1].String())
+		assert.True(t, credential.IssuanceDate.Equal(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)))
+	})
+	t.Run("selected claims disclosed with key binding", func(t *testing.T) {
+		credential, result, err := verifier.VerifySDJWT(ctx, present(t, "/address/street_address"), options)
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+		assert.Equal(t, map[string]interface{}{
+			"id":            "did:example:holder",
+			"given_name":    "Alice",
+			"address":       map[string]interface{}{"street_address": "Main Street 1", "locality": "Anytown"},
+			"nationalities": []interface{}{"NL"},
+		}, credential.CredentialSubject)
+		assert.Equal(t, []Check{ProofCheck, ValidityCheck, KeyBindingCheck, ChallengeCheck, DomainCheck}, documentChecks(result))
+	})
+	t.Run("parent disclosed without nested claim", func(t *testing.T) {
+		disclosed, err := sdJWT.Disclose("/nationalities/1")
+		require.NoError(t, err)
+		var address []Disclosure
+		for _, disclosure := range sdJWT.Disclosures {
+			if disclosure.Name == "address" {
+				address = append(address, disclosure)
+			}
+		}
+		disclosed.Disclosures = append(disclosed.Disclosures, address...)
+
+		credential, result, err := verifier.VerifySDJWT(ctx, disclosed.String(), VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+		assert.Equal(t, map[string]interface{}{"locality": "Anytown"}, credential.CredentialSubject["address"])
+		assert.Equal(t, []interface{}{"NL", "DE"}, credential.CredentialSubject["nationalities"])
+	})
+	t.Run("nested claim disclosed without parent", func(t *testing.T) {
+		var streetAddress Disclosure
+		for _, disclosure := range sdJWT.Disclosures {
+			if disclosure.Name == "street_address" {
+				streetAddress = disclosure
+			}
+		}
+		disclosed := SDJWT{JWT: sdJWT.JWT, Disclosures: []Disclosure{streetAddress}}
+
+		_, _, err := verifier.VerifySDJWT(ctx, disclosed.String(), VerificationOptions{})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "disclosure is not referenced")
+	})
+	t.Run("duplicate disclosure", func(t *testing.T) {
+		disclosed := SDJWT{JWT: sdJWT.JWT, Disclosures: []Disclosure{sdJWT.Disclosures[0], sdJWT.Disclosures[0]}}
+
+		_, _, err := verifier.VerifySDJWT(ctx, disclosed.String(), VerificationOptions{})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate disclosure")
+	})
+	t.Run("key binding required", func(t *testing.T) {
+		_, result, err := verifier.VerifySDJWT(ctx, sdJWT.String(), options)
+
+		require.NoError(t, err)
+		require.Error(t, result.Err())
+		assert.Contains(t, result.Err().Error(), "keyBinding: SD-JWT has no key binding JWT")
+	})
+	t.Run("key binding over other disclosures", func(t *testing.T) {
+		presentation, _ := ParseSDJWT(present(t, "/family_name"))
+		other, _ := ParseSDJWT(present(t, "/address"))
+		presentation.KeyBinding = other.KeyBinding
+
+		_, result, err := verifier.VerifySDJWT(ctx, presentation.String(), options)
+
+		require.NoError(t, err)
+		require.Error(t, result.Err())
+		assert.Contains(t, result.Err().Error(), "key binding JWT is not over the presented SD-JWT")
+	})
+	t.Run("key binding signed by other key", func(t *testing.T) {
+		otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		disclosed, _ := sdJWT.Disclose("/family_name")
+		bound, err := disclosed.Bind(otherKey, audience, nonce)
+		require.NoError(t, err)
+
+		_, result, err := verifier.VerifySDJWT(ctx, bound.String(), options)
+
+		require.NoError(t, err)
+		assert.Equal(t, KeyBindingCheck, failedDocumentCheck(result))
+	})
+	t.Run("stale key binding", func(t *testing.T) {
+		stale := options
+		stale.ValidAt = time.Now().Add(DefaultKeyBindingMaxAge + time.Minute)
+
+		_, result, err := verifier.VerifySDJWT(ctx, present(t, "/family_name"), stale)
+
+		require.NoError(t, err)
+		assert.Equal(t, KeyBindingCheck, failedDocumentCheck(result))
+		assert.Contains(t, result.Err().Error(), "key binding JWT is too old")
+	})
+	t.Run("stale key binding within max age", func(t *testing.T) {
+		stale := options
+		stale.ValidAt = time.Now().Add(DefaultKeyBindingMaxAge + time.Minute)
+		stale.KeyBindingMaxAge = time.Hour
+
+		_, result, err := verifier.VerifySDJWT(ctx, present(t, "/family_name"), stale)
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+	})
+	t.Run("key binding issued in the future", func(t *testing.T) {
+		future := options
+		future.ValidAt = time.Now().Add(-time.Minute)
+
+		_, result, err := verifier.VerifySDJWT(ctx, present(t, "/family_name"), future)
+
+		require.NoError(t, err)
+		assert.Equal(t, KeyBindingCheck, failedDocumentCheck(result))
+		assert.Contains(t, result.Err().Error(), "key binding JWT is issued in the future")
+	})
+	t.Run("wrong nonce", func(t *testing.T) {
+		_, result, err := verifier.VerifySDJWT(ctx, present(t, "/family_name"), VerificationOptions{Challenge: "other"})
+
+		require.NoError(t, err)
+		assert.Equal(t, ChallengeCheck, failedDocumentCheck(result))
+	})
+	t.Run("wrong audience", func(t *testing.T) {
+		_, result, err := verifier.VerifySDJWT(ctx, present(t, "/family_name"), VerificationOptions{Domain: "other.example.com"})
+
+		require.NoError(t, err)
+		assert.Equal(t, DomainCheck, failedDocumentCheck(result))
+	})
+	t.Run("tampered disclosure", func(t *testing.T) {
+		tampered, _ := newDisclosure("family_name", "Jones", false)
+		disclosed := SDJWT{JWT: sdJWT.JWT, Disclosures: []Disclosure{tampered}}
+
+		_, _, err := verifier.VerifySDJWT(ctx, disclosed.String(), VerificationOptions{})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "disclosure is not referenced")
+	})
+	t.Run("unknown claim selected", func(t *testing.T) {
+		_, err := sdJWT.Disclose("/birthdate")
+
+		assert.EqualError(t, err, "claim not found: /birthdate")
+	})
+}
+
+func documentChecks(result *VerificationResult) []Check {
+	var names []Check
+	for _, check := range result.Checks {
+		names = append(names, check.Check)
+	}
+	return names
+}
+
+func failedDocumentCheck(result *VerificationResult) Check {
+	for _, check := range result.Checks {
+		if !check.Passed() {
+			return check.Check
+		}
+	}
+	return ""
+}
+
+func encodeBase64URL(value string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
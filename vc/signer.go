@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"crypto"
+	"time"
+
+	ssi "github.com/ugradid/ugradid-common"
+)
+
+const (
+	// AssertionMethodProofPurpose is the proof purpose of proofs asserting a credential's claims.
+	AssertionMethodProofPurpose = "assertionMethod"
+	// AuthenticationProofPurpose is the proof purpose of proofs authenticating the proof's creator.
+	AuthenticationProofPurpose = "authentication"
+	// KeyAgreementProofPurpose is the proof purpose of proofs over key agreement keys.
+	KeyAgreementProofPurpose = "keyAgreement"
+	// CapabilityInvocationProofPurpose is the proof purpose of proofs invoking a capability.
+	CapabilityInvocationProofPurpose = "capabilityInvocation"
+	// CapabilityDelegationProofPurpose is the proof purpose of proofs delegating a capability.
+	CapabilityDelegationProofPurpose = "capabilityDelegation"
+)
+
+// ProofOptions holds the optional parameters for creating a proof.
+type ProofOptions struct {
+	// ProofPurpose defines the purpose of the proof. Defaults to assertionMethod for credentials and
+	// authentication for presentations.
+	ProofPurpose string
+	// Created is the time of creation of the proof. Defaults to the current time.
+	Created time.Time
+	// Domain restricts the proof to the given domain. It is optional.
+	Domain *string
+	// Challenge is the challenge provided by the verifier the proof is created for. It is optional.
+	Challenge *string
+	// Nonce is a random value to prevent the proof from being linked to other proofs. It is optional.
+	Nonce *string
+	// Expires is the time the proof expires. It is optional.
+	Expires *time.Time
+	// Suite is the proof suite creating the proof. Defaults to JSONWebSignature2020Suite.
+	Suite ProofSuite
+}
+
+// SignCredential signs the credential with the given signer, which must hold the private key of the given
+// verification method, and appends the resulting proof to the credential's proofs.
+// The proof is created by the suite in the options, which defaults to JsonWebSignature2020.
+func SignCredential(credential *VerifiableCredential, signer crypto.Signer, verificationMethod ssi.URI, options ProofOptions) error {
+	proof, err := options.suite().Sign(*credential, options.proof(verificationMethod, AssertionMethodProofPurpose), signer)
+	if err != nil {
+		return err
+	}
+	credential.Proof = append(credential.Proof, proof)
+	return nil
+}
+
+// SignPresentation signs the presentation with the given signer, which must hold the private key of the given
+// verification method of the holder, and appends the resulting proof to the presentation's proofs.
+// The proof is created by the suite in the options, which defaults to JsonWebSignature2020.
+// To bind the presentation to a verifier, the verifier's challenge and domain should be passed in the options.
+func SignPresentation(presentation *VerifiablePresentation, signer crypto.Signer, verificationMethod ssi.URI, options ProofOptions) error {
+	proof, err := options.suite().Sign(*presentation, options.proof(verificationMethod, AuthenticationProofPurpose), signer)
+	if err != nil {
+		return err
+	}
+	presentation.Proof = append(presentation.Proof, proof)
+	return nil
+}
+
+func (o ProofOptions) suite() ProofSuite {
+	if o.Suite == nil {
+		return JSONWebSignature2020Suite{}
+	}
+	return o.Suite
+}
+
+func (o ProofOptions) proof(verificationMethod ssi.URI, defaultPurpose string) Proof {
+	proof := Proof{
+		ProofPurpose:       o.ProofPurpose,
+		VerificationMethod: verificationMethod,
+		Created:            o.Created,
+		Domain:             o.Domain,
+		Challenge:          o.Challenge,
+		Nonce:              o.Nonce,
+		Expires:            o.Expires,
+	}
+	if proof.ProofPurpose == "" {
+		proof.ProofPurpose = defaultPurpose
+	}
+	if proof.Created.IsZero() {
+		proof.Created = time.Now().UTC().Truncate(time.Second)
+	}
+	return proof
+}
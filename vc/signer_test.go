@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/signature"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCredential() VerifiableCredential {
+	issuer, _ := ssi.ParseURI("did:example:issuer")
+	return VerifiableCredential{
+		Context:           []ssi.URI{VCContextV1URI()},
+		Type:              []ssi.URI{VerifiableCredentialTypeV1URI()},
+		Issuer:            *issuer,
+		IssuanceDate:      time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		CredentialSubject: map[string]interface{}{"id": "did:example:subject", "name": "Alice"},
+	}
+}
+
+func TestSignCredential(t *testing.T) {
+	verificationMethod, _ := ssi.ParseURI("did:example:issuer#key-1")
+	ed25519Key := func() crypto.Signer {
+		_, key, _ := ed25519.GenerateKey(rand.Reader)
+		return key
+	}
+	ecdsaKey := func(curve elliptic.Curve) func() crypto.Signer {
+		return func() crypto.Signer {
+			key, _ := ecdsa.GenerateKey(curve, rand.Reader)
+			return key
+		}
+	}
+	rsaKey := func() crypto.Signer {
+		key, _ := rsa.GenerateKey(rand.Reader, 2048)
+		return key
+	}
+
+	for alg, newKey := range map[signature.Algorithm]func() crypto.Signer{
+		signature.EdDSA:  ed25519Key,
+		signature.ES256:  ecdsaKey(elliptic.P256()),
+		signature.ES256K: ecdsaKey(secp256k1.S256()),
+		signature.PS256:  rsaKey,
+	} {
+		t.Run(string(alg), func(t *testing.T) {
+			key := newKey()
+			credential := testCredential()
+
+			err := SignCredential(&credential, key, *verificationMethod, ProofOptions{})
+
+			require.NoError(t, err)
+			var proofs []JSONWebSignature2020Proof
+			require.NoError(t, credential.UnmarshalProofValue(&proofs))
+			require.Len(t, proofs, 1)
+			proof := proofs[0]
+			assert.Equal(t, ssi.JsonWebSignature2020, proof.Type)
+			assert.Equal(t, AssertionMethodProofPurpose, proof.ProofPurpose)
+			assert.Equal(t, verificationMethod.String(), proof.VerificationMethod.String())
+			assert.False(t, proof.Created.IsZero())
+
+			headers, _, err := signature.ParseJWS(proof.Jws)
+			require.NoError(t, err)
+			assert.Equal(t, string(alg), headers["alg"])
+			assert.Equal(t, false, headers["b64"])
+
+			// Signature covers the credential without proof, and the proof options without JWS
+			unsigned := credential
+			unsigned.Proof = nil
+			verifyData, err := JSONWebSignature2020Suite{}.verifyData(unsigned, proof.Proof)
+			require.NoError(t, err)
+			_, err = signature.VerifyDetachedJWS(proof.Jws, verifyData, key.Public())
+			assert.NoError(t, err)
+		})
+	}
+	t.Run("proof survives JSON round trip", func(t *testing.T) {
+		_, key, _ := ed25519.GenerateKey(rand.Reader)
+		credential := testCredential()
+		domain := "example.com"
+		require.NoError(t, SignCredential(&credential, key, *verificationMethod, ProofOptions{Domain: &domain}))
+
+		data, err := json.Marshal(credential)
+		require.NoError(t, err)
+		var actual VerifiableCredential
+		require.NoError(t, json.Unmarshal(data, &actual))
+
+		proofs, err := actual.Proofs()
+		require.NoError(t, err)
+		require.Len(t, proofs, 1)
+		assert.Equal(t, domain, *proofs[0].Domain)
+		verifyData, err := JSONWebSignature2020Suite{}.verifyData(actual, proofs[0])
+		require.NoError(t, err)
+		var jwsProofs []JSONWebSignature2020Proof
+		require.NoError(t, actual.UnmarshalProofValue(&jwsProofs))
+		_, err = signature.VerifyDetachedJWS(jwsProofs[0].Jws, verifyData, key.Public())
+		assert.NoError(t, err)
+	})
+	t.Run("RS256", func(t *testing.T) {
+		key, _ := rsa.GenerateKey(rand.Reader, 2048)
+		credential := testCredential()
+
+		err := SignCredential(&credential, key, *verificationMethod, ProofOptions{Suite: JSONWebSignature2020Suite{Algorithm: signature.RS256}})
+
+		require.NoError(t, err)
+		var proofs []JSONWebSignature2020Proof
+		require.NoError(t, credential.UnmarshalProofValue(&proofs))
+		headers, _, err := signature.ParseJWS(proofs[0].Jws)
+		require.NoError(t, err)
+		assert.Equal(t, "RS256", headers["alg"])
+		assert.NoError(t, JSONWebSignature2020Suite{}.VerifyProof(credential, proofs[0], key.Public()))
+	})
+	t.Run("unsupported key", func(t *testing.T) {
+		credential := testCredential()
+
+		err := SignCredential(&credential, unsupportedSigner{}, *verificationMethod, ProofOptions{})
+
+		assert.Error(t, err)
+		assert.Empty(t, credential.Proof)
+	})
+}
+
+type unsupportedSigner struct{}
+
+func (unsupportedSigner) Public() crypto.PublicKey {
+	return "not a key"
+}
+
+func (unsupportedSigner) Sign(_ io.Reader, _ []byte, _ crypto.SignerOpts) ([]byte, error) {
+	return nil, errors.New("not implemented")
+}
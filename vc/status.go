@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status is the status of a credential according to its credentialStatus.
+type Status string
+
+const (
+	// StatusActive indicates the credential is neither revoked nor suspended.
+	StatusActive = Status("active")
+	// StatusRevoked indicates the credential is revoked, which can't be undone.
+	StatusRevoked = Status("revoked")
+	// StatusSuspended indicates the credential is suspended, which can be undone by its issuer.
+	StatusSuspended = Status("suspended")
+)
+
+// ErrRevoked is the error of a failed StatusCheck when the credential is revoked.
+var ErrRevoked = errors.New("credential is revoked")
+
+// ErrSuspended is the error of a failed StatusCheck when the credential is suspended.
+var ErrSuspended = errors.New("credential is suspended")
+
+// ErrUnknownStatusType is the error of a failed StatusCheck when no StatusChecker is registered for the type of the
+// credential's credentialStatus.
+var ErrUnknownStatusType = errors.New("unknown credentialStatus type")
+
+// ErrStatusUnavailable is returned by a StatusChecker when the status of the credential can't be determined at the
+// moment, e.g. because the registry holding it can't be reached. Verification may be retried later.
+var ErrStatusUnavailable = errors.New("credential status unavailable")
+
+// StatusChecker checks the status of credentials with a credentialStatus of a specific type.
+type StatusChecker interface {
+	// CheckStatus returns the status of the credential at the given time according to its credentialStatus. It returns an
+	// error wrapping ErrStatusUnavailable when the status can't be determined, and any other error when the
+	// credentialStatus is invalid.
+	CheckStatus(ctx context.Context, credential VerifiableCredential, validAt time.Time) (Status, error)
+}
+
+// StatusCheckerFunc is a function implementing StatusChecker.
+type StatusCheckerFunc func(ctx context.Context, credential VerifiableCredential, validAt time.Time) (Status, error)
+
+// CheckStatus calls the function.
+func (f StatusCheckerFunc) CheckStatus(ctx context.Context, credential VerifiableCredential, validAt time.Time) (Status, error) {
+	return f(ctx, credential, validAt)
+}
+
+var statusCheckers = struct {
+	mux      sync.RWMutex
+	registry map[string]StatusChecker
+}{registry: make(map[string]StatusChecker)}
+
+// RegisterStatusChecker registers the checker for credentials with a credentialStatus of the given type,
+// replacing any checker registered for it before. Registering nil removes the checker.
+func RegisterStatusChecker(statusType string, checker StatusChecker) {
+	statusCheckers.mux.Lock()
+	defer statusCheckers.mux.Unlock()
+	if checker == nil {
+		delete(statusCheckers.registry, statusType)
+		return
+	}
+	statusCheckers.registry[statusType] = checker
+}
+
+// FindStatusChecker returns the checker registered for the given credentialStatus type, or nil if there's none.
+func FindStatusChecker(statusType string) StatusChecker {
+	statusCheckers.mux.RLock()
+	defer statusCheckers.mux.RUnlock()
+	return statusCheckers.registry[statusType]
+}
+
+// checkStatus adds a StatusCheck to the result when the credential has a credentialStatus, unless the options skip it.
+func checkStatus(ctx context.Context, credential VerifiableCredential, options VerificationOptions, result *VerificationResult) {
+	if credential.CredentialStatus == nil || options.SkipStatus {
+		return
+	}
+	result.Checks = append(result.Checks, CheckResult{Check: StatusCheck, Error: credentialStatusError(ctx, credential, options.validAt())})
+}
+
+// credentialStatusError returns the reason the credential's status at the given time is invalid, or nil when it is active.
+func credentialStatusError(ctx context.Context, credential VerifiableCredential, validAt time.Time) error {
+	statusType := credential.CredentialStatus.Type
+	checker := FindStatusChecker(statusType)
+	if checker == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownStatusType, statusType)
+	}
+	status, err := checker.CheckStatus(ctx, credential, validAt)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case StatusActive:
+		return nil
+	case StatusRevoked:
+		return ErrRevoked
+	case StatusSuspended:
+		return ErrSuspended
+	default:
+		return fmt.Errorf("unknown credential status: %s", status)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ugradid/ugradid-common/did"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier_Verify_Status(t *testing.T) {
+	const statusType = "TestStatus2021"
+	ctx := context.Background()
+	store := did.NewMemoryStore()
+	issuer := newTestIssuer(t, "did:example:issuer", (*did.Document).AddAssertionMethod)
+	_, err := store.Put(issuer.document, time.Now())
+	require.NoError(t, err)
+	verifier := NewVerifier(store)
+	credential := testCredential()
+	credential.Issuer = issuer.document.ID.URI()
+	credential.CredentialStatus = &CredentialStatus{
+		Type:       statusType,
+		Properties: map[string]interface{}{"index": "1"},
+	}
+	require.NoError(t, SignCredential(&credential, issuer.key, issuer.keyID, ProofOptions{}))
+	register := func(t *testing.T, status Status, err error) {
+		RegisterStatusChecker(statusType, StatusCheckerFunc(func(_ context.Context, actual VerifiableCredential, _ time.Time) (Status, error) {
+			assert.Equal(t, "1", actual.CredentialStatus.Properties["index"])
+			return status, err
+		}))
+		t.Cleanup(func() {
+			RegisterStatusChecker(statusType, nil)
+		})
+	}
+	statusError := func(t *testing.T, options VerificationOptions) error {
+		result, err := verifier.Verify(ctx, credential, options)
+		require.NoError(t, err)
+		for _, check := range result.Checks {
+			if check.Check == StatusCheck {
+				return check.Error
+			}
+		}
+		t.Fatal("status wasn't checked")
+		return nil
+	}
+
+	t.Run("active", func(t *testing.T) {
+		register(t, StatusActive, nil)
+
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+		assert.Contains(t, result.Checks, CheckResult{Check: StatusCheck})
+	})
+	t.Run("checked at validAt", func(t *testing.T) {
+		validAt := time.Now().Add(time.Minute).Truncate(time.Second)
+		var checkedAt time.Time
+		RegisterStatusChecker(statusType, StatusCheckerFunc(func(_ context.Context, _ VerifiableCredential, validAt time.Time) (Status, error) {
+			checkedAt = validAt
+			return StatusActive, nil
+		}))
+		defer RegisterStatusChecker(statusType, nil)
+
+		assert.NoError(t, statusError(t, VerificationOptions{ValidAt: validAt}))
+		assert.Equal(t, validAt, checkedAt)
+	})
+	t.Run("revoked", func(t *testing.T) {
+		register(t, StatusRevoked, nil)
+
+		assert.ErrorIs(t, statusError(t, VerificationOptions{}), ErrRevoked)
+	})
+	t.Run("suspended", func(t *testing.T) {
+		register(t, StatusSuspended, nil)
+
+		assert.ErrorIs(t, statusError(t, VerificationOptions{}), ErrSuspended)
+	})
+	t.Run("unknown type", func(t *testing.T) {
+		err := statusError(t, VerificationOptions{})
+
+		assert.ErrorIs(t, err, ErrUnknownStatusType)
+		assert.EqualError(t, err, "unknown credentialStatus type: TestStatus2021")
+	})
+	t.Run("unavailable", func(t *testing.T) {
+		register(t, "", fmt.Errorf("%w: connection refused", ErrStatusUnavailable))
+
+		assert.ErrorIs(t, statusError(t, VerificationOptions{}), ErrStatusUnavailable)
+	})
+	t.Run("invalid credentialStatus", func(t *testing.T) {
+		register(t, "", errors.New("invalid index"))
+
+		err := statusError(t, VerificationOptions{})
+
+		assert.EqualError(t, err, "invalid index")
+		assert.False(t, errors.Is(err, ErrStatusUnavailable))
+	})
+	t.Run("skipped", func(t *testing.T) {
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{SkipStatus: true})
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+	})
+	t.Run("presented credential", func(t *testing.T) {
+		register(t, StatusRevoked, nil)
+		presentation := VerifiablePresentation{VerifiableCredential: []VerifiableCredential{credential}}
+
+		result, err := verifier.VerifyPresentation(ctx, presentation, VerificationOptions{})
+
+		require.NoError(t, err)
+		require.Len(t, result.Credentials, 1)
+		assert.ErrorIs(t, result.Credentials[0].Err(), ErrVerificationFailed)
+		assert.Contains(t, result.Credentials[0].Err().Error(), "status: credential is revoked")
+	})
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package statuslist
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MinimumSize is the minimum number of entries of a status list, which provides group privacy to the holders of the
+// credentials listed in it (https://www.w3.org/TR/vc-bitstring-status-list/#bitstring-encoding).
+const MinimumSize = 131072
+
+// maxDecodedSize limits the size of decoded status lists, to protect against compression bombs.
+const maxDecodedSize = 16 * 1024 * 1024
+
+// multibaseBase64URL is the multibase prefix of base64url encoded lists, as required by Bitstring Status List.
+// StatusList2021 lists are base64url encoded without prefix.
+const multibaseBase64URL = "u"
+
+// Bitstring holds the status bits of a status list. The first index is the left-most bit of the first byte.
+type Bitstring []byte
+
+// NewBitstring returns a bitstring of at least the given number of bits, which are all unset.
+func NewBitstring(size int) Bitstring {
+	return make(Bitstring, (size+7)/8)
+}
+
+// Len returns the number of bits in the bitstring.
+func (b Bitstring) Len() int {
+	return len(b) * 8
+}
+
+// Get returns whether the bit at the given index is set.
+func (b Bitstring) Get(index int) (bool, error) {
+	if index < 0 || index >= b.Len() {
+		return false, fmt.Errorf("status list index out of range: %d", index)
+	}
+	return b[index/8]&(0x80>>(index%8)) != 0, nil
+}
+
+// Set sets or unsets the bit at the given index.
+func (b Bitstring) Set(index int, value bool) error {
+	if index < 0 || index >= b.Len() {
+		return fmt.Errorf("status list index out of range: %d", index)
+	}
+	if value {
+		b[index/8] |= 0x80 >> (index % 8)
+	} else {
+		b[index/8] &^= 0x80 >> (index % 8)
+	}
+	return nil
+}
+
+// Encode returns the GZIP compressed bitstring as multibase base64url string (without padding), as encodedList of
+// a BitstringStatusList.
+func (b Bitstring) Encode() (string, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(b); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return multibaseBase64URL + base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeBitstring decodes the encodedList of a BitstringStatusList or StatusList2021: a GZIP compressed bitstring as
+// base64url string, with or without multibase prefix.
+func DecodeBitstring(encodedList string) (Bitstring, error) {
+	// The base64url encoding of GZIP data always starts with H4sI, so a multibase prefix can be told apart.
+	encodedList = strings.TrimPrefix(encodedList, multibaseBase64URL)
+	compressed, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encodedList, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encodedList: %w", err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encodedList: %w", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, maxDecodedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encodedList: %w", err)
+	}
+	if len(data) > maxDecodedSize {
+		return nil, errors.New("invalid encodedList: exceeds maximum size")
+	}
+	return data, nil
+}
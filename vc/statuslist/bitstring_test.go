@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package statuslist
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitstring(t *testing.T) {
+	bits := NewBitstring(MinimumSize)
+	require.Equal(t, MinimumSize, bits.Len())
+
+	require.NoError(t, bits.Set(0, true))
+	require.NoError(t, bits.Set(94567, true))
+	require.NoError(t, bits.Set(MinimumSize-1, true))
+
+	assert.Equal(t, byte(0x80), bits[0])
+	for _, index := range []int{0, 94567, MinimumSize - 1} {
+		set, err := bits.Get(index)
+		require.NoError(t, err)
+		assert.True(t, set, index)
+	}
+	set, _ := bits.Get(1)
+	assert.False(t, set)
+	t.Run("unset", func(t *testing.T) {
+		bits := NewBitstring(8)
+		_ = bits.Set(3, true)
+
+		require.NoError(t, bits.Set(3, false))
+
+		assert.Equal(t, Bitstring{0}, bits)
+	})
+	t.Run("index out of range", func(t *testing.T) {
+		_, err := bits.Get(MinimumSize)
+		assert.EqualError(t, err, "status list index out of range: 131072")
+
+		err = bits.Set(-1, true)
+		assert.EqualError(t, err, "status list index out of range: -1")
+	})
+}
+
+func TestDecodeBitstring(t *testing.T) {
+	t.Run("StatusList2021 example", func(t *testing.T) {
+		// Example encodedList of the StatusList2021 specification
+		bits, err := DecodeBitstring("H4sIAAAAAAAAA-3BMQEAAADCoPVPbQwfoAAAAAAAAAAAAAAAAAAAAIC3AYbSVKsAQAAA")
+
+		require.NoError(t, err)
+		assert.Equal(t, NewBitstring(MinimumSize), bits)
+	})
+	t.Run("round trip", func(t *testing.T) {
+		bits := NewBitstring(MinimumSize)
+		_ = bits.Set(42, true)
+
+		encoded, err := bits.Encode()
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(encoded, "uH4sI"))
+		decoded, err := DecodeBitstring(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, bits, decoded)
+	})
+	t.Run("invalid base64url", func(t *testing.T) {
+		_, err := DecodeBitstring("H4sI!")
+
+		assert.Error(t, err)
+	})
+	t.Run("not compressed", func(t *testing.T) {
+		_, err := DecodeBitstring("uAAAA")
+
+		assert.Error(t, err)
+	})
+}
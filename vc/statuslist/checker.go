@@ -0,0 +1,214 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package statuslist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/vc"
+)
+
+// maxCredentialSize limits the size of fetched status list credentials to protect against misbehaving servers.
+const maxCredentialSize = 1024 * 1024
+
+// ErrUnavailable is returned when the status list credential of an entry can't be loaded.
+var ErrUnavailable = errors.New("status list unavailable")
+
+// ErrInvalidList is returned when the status list credential of an entry is invalid, e.g. because its proof is invalid
+// or it was issued by another issuer than the credential.
+var ErrInvalidList = errors.New("invalid status list credential")
+
+// Loader loads status list credentials.
+type Loader interface {
+	// Load returns the status list credential published at the given URL.
+	Load(ctx context.Context, url ssi.URI) (*vc.VerifiableCredential, error)
+}
+
+// LoaderFunc is a function implementing Loader, e.g. to load status list credentials from a cache.
+type LoaderFunc func(ctx context.Context, url ssi.URI) (*vc.VerifiableCredential, error)
+
+// Load calls the function.
+func (f LoaderFunc) Load(ctx context.Context, url ssi.URI) (*vc.VerifiableCredential, error) {
+	return f(ctx, url)
+}
+
+// HTTPLoader is a Loader fetching status list credentials over HTTP(S).
+type HTTPLoader struct {
+	// HTTPClient is used to fetch status list credentials. If it is nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// NewHTTPLoader creates an HTTPLoader with an HTTP client that times out after the given duration.
+func NewHTTPLoader(timeout time.Duration) *HTTPLoader {
+	return &HTTPLoader{HTTPClient: &http.Client{Timeout: timeout}}
+}
+
+// Load fetches the status list credential from the given URL. The credential must be JSON, secured by embedded proofs.
+func (l HTTPLoader) Load(ctx context.Context, url ssi.URI) (*vc.VerifiableCredential, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/vc+ld+json, application/json")
+	response, err := l.client().Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch status list credential (url=%s): %w", url.String(), err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch status list credential (url=%s): unexpected HTTP status %d", url.String(), response.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxCredentialSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read status list credential (url=%s): %w", url.String(), err)
+	}
+	if len(data) > maxCredentialSize {
+		return nil, fmt.Errorf("status list credential exceeds maximum size of %d bytes (url=%s)", maxCredentialSize, url.String())
+	}
+	var credential vc.VerifiableCredential
+	if err := json.Unmarshal(data, &credential); err != nil {
+		return nil, fmt.Errorf("unable to parse status list credential (url=%s): %w", url.String(), err)
+	}
+	return &credential, nil
+}
+
+func (l HTTPLoader) client() *http.Client {
+	if l.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return l.HTTPClient
+}
+
+// Status is the status of a credential according to its status list entry.
+type Status struct {
+	// Purpose is the status purpose of the entry, e.g. PurposeRevocation.
+	Purpose string
+	// Set indicates the entry's status bit is set, e.g. meaning the credential is revoked when the purpose is
+	// revocation.
+	Set bool
+}
+
+// Checker checks the status of credentials listed in Bitstring Status Lists or StatusList2021 lists.
+type Checker struct {
+	// Loader loads the status list credentials.
+	Loader Loader
+	// Verifier verifies the proofs of status list credentials. If it is nil, they aren't verified, which is only
+	// safe when the Loader verifies them (e.g. when they are secured as JWT) or loads them from a trusted source.
+	Verifier *vc.Verifier
+}
+
+// NewChecker creates a Checker loading status list credentials using the given loader and verifying them using the
+// given verifier.
+func NewChecker(loader Loader, verifier *vc.Verifier) *Checker {
+	return &Checker{Loader: loader, Verifier: verifier}
+}
+
+// Check returns the status of the credential according to the status list entry in its credentialStatus.
+// The status list credential must have the entry's statusListCredential as ID, be issued by the credential's issuer,
+// have the entry's status purpose and must not be expired. It returns ErrUnavailable when the status list credential can't be loaded and ErrInvalidList when it is
+// invalid. Expiry and proofs of the status list credential are checked at validAt, or the current time when it is zero.
+func (c Checker) Check(ctx context.Context, credential vc.VerifiableCredential, validAt time.Time) (*Status, error) {
+	if credential.CredentialStatus == nil {
+		return nil, errors.New("credential has no credentialStatus")
+	}
+	entry, err := ParseEntry(*credential.CredentialStatus)
+	if err != nil {
+		return nil, err
+	}
+	index, _ := entry.Index()
+	listCredential, err := c.Loader.Load(ctx, entry.StatusListCredential)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	list, err := c.verifyList(ctx, *listCredential, *entry, credential.Issuer, validAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidList, err)
+	}
+	if list.Purpose != entry.StatusPurpose {
+		return nil, fmt.Errorf("%w: statusPurpose %s does not match entry's statusPurpose %s", ErrInvalidList, list.Purpose, entry.StatusPurpose)
+	}
+	set, err := list.Bits.Get(index)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidList, err)
+	}
+	return &Status{Purpose: entry.StatusPurpose, Set: set}, nil
+}
+
+// CheckStatus implements vc.StatusChecker: a credential is revoked or suspended when the status bit of its entry is set
+// in a list with purpose PurposeRevocation or PurposeSuspension respectively. The status bits of lists with other
+// purposes don't affect the credential's status. Errors of lists that can't be loaded wrap vc.ErrStatusUnavailable,
+// while invalid lists (e.g. with an invalid proof or issued by another issuer) fail the check with ErrInvalidList.
+func (c Checker) CheckStatus(ctx context.Context, credential vc.VerifiableCredential, validAt time.Time) (vc.Status, error) {
+	status, err := c.Check(ctx, credential, validAt)
+	if errors.Is(err, ErrUnavailable) {
+		return "", fmt.Errorf("%w: %s", vc.ErrStatusUnavailable, err)
+	}
+	if err != nil {
+		return "", err
+	}
+	if status.Set && status.Purpose == PurposeRevocation {
+		return vc.StatusRevoked, nil
+	}
+	if status.Set && status.Purpose == PurposeSuspension {
+		return vc.StatusSuspended, nil
+	}
+	return vc.StatusActive, nil
+}
+
+// Register registers the checker with vc.RegisterStatusChecker for BitstringStatusListEntry and StatusList2021Entry
+// credentialStatus types, so vc.Verifier checks the status of credentials listed in status lists.
+func Register(checker *Checker) {
+	vc.RegisterStatusChecker(EntryType, checker)
+	vc.RegisterStatusChecker(StatusList2021EntryType, checker)
+}
+
+func (c Checker) verifyList(ctx context.Context, credential vc.VerifiableCredential, entry Entry, issuer ssi.URI, validAt time.Time) (*List, error) {
+	if validAt.IsZero() {
+		validAt = time.Now()
+	}
+	list, err := ParseCredential(credential)
+	if err != nil {
+		return nil, err
+	}
+	if credential.ID == nil || credential.ID.String() != entry.StatusListCredential.String() {
+		return nil, fmt.Errorf("id does not match the entry's statusListCredential %s", entry.StatusListCredential.String())
+	}
+	if credential.Issuer.String() != issuer.String() {
+		return nil, fmt.Errorf("issued by %s instead of the credential's issuer", credential.Issuer.String())
+	}
+	if _, validUntil := credential.Validity(); validUntil != nil && validUntil.Before(validAt) {
+		return nil, errors.New("expired")
+	}
+	if c.Verifier != nil {
+		result, err := c.Verifier.Verify(ctx, credential, vc.VerificationOptions{ValidAt: validAt})
+		if err != nil {
+			return nil, err
+		}
+		if err := result.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
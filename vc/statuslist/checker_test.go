@@ -0,0 +1,218 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package statuslist
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/did"
+	"github.com/ugradid/ugradid-common/vc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker_Check(t *testing.T) {
+	ctx := context.Background()
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	issuerDID, _ := did.ParseDID("did:example:issuer")
+	vmID, _ := did.ParseDIDURL("did:example:issuer#key-1")
+	vm, err := did.NewVerificationMethod(*vmID, ssi.ED25519VerificationKey2018, *issuerDID, publicKey)
+	require.NoError(t, err)
+	document := did.Document{Context: []ssi.URI{did.DIDContextV1URI()}, ID: *issuerDID}
+	document.AddAssertionMethod(vm)
+	store := did.NewMemoryStore()
+	_, err = store.Put(document, time.Now())
+	require.NoError(t, err)
+
+	var listCredential *vc.VerifiableCredential
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/status/1" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(writer).Encode(listCredential)
+	}))
+	defer server.Close()
+	listID, _ := ssi.ParseURI(server.URL + "/status/1")
+	list, _ := NewList(*listID, PurposeRevocation, 0)
+	publish := func(t *testing.T) {
+		var err error
+		listCredential, err = list.Credential(issuerDID.URI(), time.Now())
+		require.NoError(t, err)
+		require.NoError(t, vc.SignCredential(listCredential, privateKey, vmID.URI(), vc.ProofOptions{}))
+	}
+	credential := func(t *testing.T) vc.VerifiableCredential {
+		status, err := list.Assign()
+		require.NoError(t, err)
+		return vc.VerifiableCredential{Issuer: issuerDID.URI(), CredentialStatus: status}
+	}
+	checker := NewChecker(&HTTPLoader{HTTPClient: server.Client()}, vc.NewVerifier(store))
+
+	t.Run("not revoked", func(t *testing.T) {
+		credential := credential(t)
+		publish(t)
+
+		status, err := checker.Check(ctx, credential, time.Time{})
+
+		require.NoError(t, err)
+		assert.Equal(t, Status{Purpose: PurposeRevocation, Set: false}, *status)
+	})
+	t.Run("revoked", func(t *testing.T) {
+		credential := credential(t)
+		entry, _ := ParseEntry(*credential.CredentialStatus)
+		index, _ := entry.Index()
+		require.NoError(t, list.Set(index, true))
+		publish(t)
+
+		status, err := checker.Check(ctx, credential, time.Time{})
+
+		require.NoError(t, err)
+		assert.Equal(t, Status{Purpose: PurposeRevocation, Set: true}, *status)
+	})
+	t.Run("other issuer", func(t *testing.T) {
+		credential := credential(t)
+		other, _ := ssi.ParseURI("did:example:other")
+		credential.Issuer = *other
+		publish(t)
+
+		_, err := checker.Check(ctx, credential, time.Time{})
+
+		assert.ErrorIs(t, err, ErrInvalidList)
+	})
+	t.Run("invalid proof", func(t *testing.T) {
+		credential := credential(t)
+		publish(t)
+		validFrom := listCredential.ValidFrom.Add(time.Hour)
+		listCredential.ValidFrom = &validFrom
+
+		_, err := checker.Check(ctx, credential, time.Time{})
+
+		assert.ErrorIs(t, err, ErrInvalidList)
+	})
+	t.Run("expired list", func(t *testing.T) {
+		credential := credential(t)
+		publish(t)
+		validUntil := time.Now().Add(time.Hour)
+		listCredential.ValidUntil = &validUntil
+		listCredential.Proof = nil
+		require.NoError(t, vc.SignCredential(listCredential, privateKey, vmID.URI(), vc.ProofOptions{}))
+
+		_, err := checker.Check(ctx, credential, time.Time{})
+
+		require.NoError(t, err)
+
+		_, err = checker.Check(ctx, credential, validUntil.Add(time.Minute))
+
+		assert.ErrorIs(t, err, ErrInvalidList)
+		assert.Contains(t, err.Error(), "expired")
+	})
+	t.Run("other status purpose", func(t *testing.T) {
+		credential := credential(t)
+		credential.CredentialStatus.Properties["statusPurpose"] = PurposeSuspension
+		publish(t)
+
+		_, err := checker.Check(ctx, credential, time.Time{})
+
+		assert.ErrorIs(t, err, ErrInvalidList)
+	})
+	t.Run("list of other ID", func(t *testing.T) {
+		credential := credential(t)
+		credential.CredentialStatus.Properties["statusListCredential"] = server.URL + "/status/2"
+		publish(t)
+		checker := NewChecker(LoaderFunc(func(_ context.Context, _ ssi.URI) (*vc.VerifiableCredential, error) {
+			return listCredential, nil
+		}), checker.Verifier)
+
+		_, err := checker.Check(ctx, credential, time.Time{})
+
+		assert.ErrorIs(t, err, ErrInvalidList)
+		assert.Contains(t, err.Error(), "id does not match the entry's statusListCredential")
+	})
+	t.Run("unavailable", func(t *testing.T) {
+		credential := credential(t)
+		credential.CredentialStatus.Properties["statusListCredential"] = server.URL + "/status/2"
+
+		_, err := checker.Check(ctx, credential, time.Time{})
+
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+	t.Run("registered with verifier", func(t *testing.T) {
+		Register(checker)
+		defer vc.RegisterStatusChecker(EntryType, nil)
+		defer vc.RegisterStatusChecker(StatusList2021EntryType, nil)
+		suspensionList, _ := NewList(*listID, PurposeSuspension, 0)
+		list = suspensionList
+		credential := credential(t)
+		credential.Context = []ssi.URI{vc.VCContextV1URI()}
+		credential.Type = []ssi.URI{vc.VerifiableCredentialTypeV1URI()}
+		credential.CredentialSubject = map[string]interface{}{"id": "did:example:holder"}
+		require.NoError(t, vc.SignCredential(&credential, privateKey, vmID.URI(), vc.ProofOptions{}))
+		publish(t)
+
+		result, err := checker.Verifier.Verify(ctx, credential, vc.VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+
+		require.NoError(t, list.Set(0, true))
+		publish(t)
+		result, err = checker.Verifier.Verify(ctx, credential, vc.VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Checks[len(result.Checks)-1].Error, vc.ErrSuspended)
+
+		otherIssuer, _ := ssi.ParseURI("did:example:other")
+		listCredential.Issuer = *otherIssuer
+		result, err = checker.Verifier.Verify(ctx, credential, vc.VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Checks[len(result.Checks)-1].Error, ErrInvalidList)
+	})
+	t.Run("CheckStatus - unavailable", func(t *testing.T) {
+		credential := credential(t)
+		credential.CredentialStatus.Properties["statusListCredential"] = server.URL + "/status/2"
+
+		_, err := checker.CheckStatus(ctx, credential, time.Time{})
+
+		assert.ErrorIs(t, err, vc.ErrStatusUnavailable)
+	})
+	t.Run("CheckStatus - invalid list", func(t *testing.T) {
+		credential := credential(t)
+		publish(t)
+		listCredential.Proof = nil
+
+		_, err := checker.CheckStatus(ctx, credential, time.Time{})
+
+		assert.ErrorIs(t, err, ErrInvalidList)
+		assert.NotErrorIs(t, err, vc.ErrStatusUnavailable)
+	})
+	t.Run("no credentialStatus", func(t *testing.T) {
+		_, err := checker.Check(ctx, vc.VerifiableCredential{}, time.Time{})
+
+		assert.EqualError(t, err, "credential has no credentialStatus")
+	})
+}
@@ -0,0 +1,244 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package statuslist implements Bitstring Status List (https://www.w3.org/TR/vc-bitstring-status-list/) and its
+// predecessor StatusList2021 for revoking and suspending credentials. Issuers publish a status list credential holding
+// a compressed bitstring, of which every credential is assigned an entry. Verifiers fetch the status list credential
+// and check the bit of the credential's entry.
+package statuslist
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/vc"
+)
+
+const (
+	// EntryType is the credentialStatus type of credentials listed in a Bitstring Status List.
+	EntryType = "BitstringStatusListEntry"
+	// StatusList2021EntryType is the credentialStatus type of credentials listed in a StatusList2021.
+	StatusList2021EntryType = "StatusList2021Entry"
+	// CredentialType is the type of Bitstring Status List credentials.
+	CredentialType = "BitstringStatusListCredential"
+	// StatusList2021CredentialType is the type of StatusList2021 credentials.
+	StatusList2021CredentialType = "StatusList2021Credential"
+	// ContextV1 is the context of Bitstring Status List credentials.
+	ContextV1 = "https://www.w3.org/ns/credentials/status/v1"
+	// StatusList2021ContextV1 is the context of StatusList2021 credentials.
+	StatusList2021ContextV1 = "https://w3id.org/vc/status-list/2021/v1"
+
+	// PurposeRevocation is the status purpose of lists revoking credentials, which can't be undone.
+	PurposeRevocation = "revocation"
+	// PurposeSuspension is the status purpose of lists suspending credentials, which can be undone.
+	PurposeSuspension = "suspension"
+
+	listType              = "BitstringStatusList"
+	statusList2021Type    = "StatusList2021"
+	statusPurposeProperty = "statusPurpose"
+	encodedListProperty   = "encodedList"
+	listSubjectIDFragment = "list"
+)
+
+// ErrListFull is returned when all entries of a status list have been assigned.
+var ErrListFull = errors.New("status list is full")
+
+// Entry is the credentialStatus of a credential listed in a status list.
+type Entry struct {
+	ID                   *ssi.URI `json:"id,omitempty"`
+	Type                 string   `json:"type"`
+	StatusPurpose        string   `json:"statusPurpose"`
+	StatusListIndex      string   `json:"statusListIndex"`
+	StatusListCredential ssi.URI  `json:"statusListCredential"`
+	// StatusSize is the number of bits per entry. Only the default of 1 is supported.
+	StatusSize int `json:"statusSize,omitempty"`
+}
+
+// ParseEntry returns the status list entry held by the given credentialStatus, which must be a
+// BitstringStatusListEntry or StatusList2021Entry.
+func ParseEntry(status vc.CredentialStatus) (*Entry, error) {
+	if status.Type != EntryType && status.Type != StatusList2021EntryType {
+		return nil, fmt.Errorf("unsupported credentialStatus type: %s", status.Type)
+	}
+	var entry Entry
+	if err := status.UnmarshalProperties(&entry); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", status.Type, err)
+	}
+	if entry.StatusPurpose == "" {
+		return nil, fmt.Errorf("invalid %s: statusPurpose is missing", status.Type)
+	}
+	if _, err := entry.Index(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", status.Type, err)
+	}
+	if entry.StatusListCredential.String() == "" {
+		return nil, fmt.Errorf("invalid %s: statusListCredential is missing", status.Type)
+	}
+	if entry.StatusSize > 1 {
+		return nil, fmt.Errorf("invalid %s: unsupported statusSize: %d", status.Type, entry.StatusSize)
+	}
+	return &entry, nil
+}
+
+// Index returns the statusListIndex as integer.
+func (e Entry) Index() (int, error) {
+	index, err := strconv.Atoi(e.StatusListIndex)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("invalid statusListIndex: %s", e.StatusListIndex)
+	}
+	return index, nil
+}
+
+// CredentialStatus returns the entry as credentialStatus of a credential.
+func (e Entry) CredentialStatus() vc.CredentialStatus {
+	status := vc.CredentialStatus{
+		Type: e.Type,
+		Properties: map[string]interface{}{
+			statusPurposeProperty:  e.StatusPurpose,
+			"statusListIndex":      e.StatusListIndex,
+			"statusListCredential": e.StatusListCredential.String(),
+		},
+	}
+	if e.ID != nil {
+		status.ID = e.ID.URL
+	}
+	if e.StatusSize != 0 {
+		status.Properties["statusSize"] = e.StatusSize
+	}
+	return status
+}
+
+// List is a Bitstring Status List of an issuer, which assigns entries to the credentials it issues and sets their
+// status. Issuers must store the list after assigning entries or setting their status, and publish the status list
+// credential returned by Credential (after signing it) at the list's ID.
+type List struct {
+	// ID is the URL the status list credential is published at.
+	ID ssi.URI
+	// Purpose is the status purpose of the list, e.g. PurposeRevocation.
+	Purpose string
+	// Bits holds the status of the list's entries.
+	Bits Bitstring
+	// Next is the index of the entry that is assigned next.
+	Next int
+}
+
+// NewList creates a status list with the given ID and purpose, holding at least the given number of entries.
+// Lists hold at least MinimumSize entries.
+func NewList(id ssi.URI, purpose string, size int) (*List, error) {
+	if id.String() == "" {
+		return nil, errors.New("status list ID is missing")
+	}
+	if purpose == "" {
+		return nil, errors.New("status purpose is missing")
+	}
+	if size < MinimumSize {
+		size = MinimumSize
+	}
+	return &List{ID: id, Purpose: purpose, Bits: NewBitstring(size)}, nil
+}
+
+// Assign assigns the next entry of the list and returns it as credentialStatus, to be added to a new credential.
+// It returns ErrListFull when all entries have been assigned, in which case the issuer must create a new list.
+func (l *List) Assign() (*vc.CredentialStatus, error) {
+	if l.Next >= l.Bits.Len() {
+		return nil, ErrListFull
+	}
+	index := strconv.Itoa(l.Next)
+	id, err := ssi.ParseURI(l.ID.String() + "#" + index)
+	if err != nil {
+		return nil, err
+	}
+	l.Next++
+	status := Entry{
+		ID:                   id,
+		Type:                 EntryType,
+		StatusPurpose:        l.Purpose,
+		StatusListIndex:      index,
+		StatusListCredential: l.ID,
+	}.CredentialStatus()
+	return &status, nil
+}
+
+// Set sets the status of the entry at the given index, e.g. revoking the credential it is assigned to when the
+// list's purpose is revocation. The status of entries that haven't been assigned yet can't be set.
+func (l *List) Set(index int, value bool) error {
+	if index >= l.Next {
+		return fmt.Errorf("status list entry hasn't been assigned: %d", index)
+	}
+	return l.Bits.Set(index, value)
+}
+
+// Credential returns the unsigned status list credential of the list, issued by the given issuer and valid from the
+// given time. As specified for Bitstring Status Lists, it is a VC Data Model 2.0 credential.
+func (l List) Credential(issuer ssi.URI, validFrom time.Time) (*vc.VerifiableCredential, error) {
+	encodedList, err := l.Bits.Encode()
+	if err != nil {
+		return nil, err
+	}
+	id := l.ID
+	contextV1, _ := ssi.ParseURI(ContextV1)
+	credentialType, _ := ssi.ParseURI(CredentialType)
+	return &vc.VerifiableCredential{
+		Context:   []ssi.URI{vc.VCContextV2URI(), *contextV1},
+		ID:        &id,
+		Type:      []ssi.URI{vc.VerifiableCredentialTypeV1URI(), *credentialType},
+		Issuer:    issuer,
+		ValidFrom: &validFrom,
+		CredentialSubject: map[string]interface{}{
+			"id":                  l.ID.String() + "#" + listSubjectIDFragment,
+			"type":                listType,
+			statusPurposeProperty: l.Purpose,
+			encodedListProperty:   encodedList,
+		},
+	}, nil
+}
+
+// ParseCredential returns the status list held by the given Bitstring Status List or StatusList2021 credential.
+// Since the next entry to assign isn't part of the credential, issuers restoring a list from its credential must set
+// it.
+func ParseCredential(credential vc.VerifiableCredential) (*List, error) {
+	bitstringCredential, _ := ssi.ParseURI(CredentialType)
+	statusList2021Credential, _ := ssi.ParseURI(StatusList2021CredentialType)
+	if !credential.IsType(*bitstringCredential) && !credential.IsType(*statusList2021Credential) {
+		return nil, errors.New("not a status list credential")
+	}
+	var subject struct {
+		Type          string `json:"type"`
+		StatusPurpose string `json:"statusPurpose"`
+		EncodedList   string `json:"encodedList"`
+	}
+	if err := credential.UnmarshalCredentialSubject(&subject); err != nil {
+		return nil, fmt.Errorf("invalid status list: %w", err)
+	}
+	if subject.Type != listType && subject.Type != statusList2021Type {
+		return nil, fmt.Errorf("invalid status list: unsupported type: %s", subject.Type)
+	}
+	if subject.StatusPurpose == "" {
+		return nil, errors.New("invalid status list: statusPurpose is missing")
+	}
+	bits, err := DecodeBitstring(subject.EncodedList)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status list: %w", err)
+	}
+	list := &List{Purpose: subject.StatusPurpose, Bits: bits}
+	if credential.ID != nil {
+		list.ID = *credential.ID
+	}
+	return list, nil
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package statuslist
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/vc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEntry(t *testing.T) {
+	parse := func(t *testing.T, status string) (*Entry, error) {
+		var credentialStatus vc.CredentialStatus
+		require.NoError(t, json.Unmarshal([]byte(status), &credentialStatus))
+		return ParseEntry(credentialStatus)
+	}
+	t.Run("ok", func(t *testing.T) {
+		entry, err := parse(t, `{
+			"id": "https://example.com/credentials/status/3#94567",
+			"type": "BitstringStatusListEntry",
+			"statusPurpose": "revocation",
+			"statusListIndex": "94567",
+			"statusListCredential": "https://example.com/credentials/status/3"
+		}`)
+
+		require.NoError(t, err)
+		assert.Equal(t, PurposeRevocation, entry.StatusPurpose)
+		assert.Equal(t, "https://example.com/credentials/status/3", entry.StatusListCredential.String())
+		index, _ := entry.Index()
+		assert.Equal(t, 94567, index)
+	})
+	t.Run("StatusList2021Entry", func(t *testing.T) {
+		_, err := parse(t, `{"id": "https://example.com/status/1#1", "type": "StatusList2021Entry", "statusPurpose": "suspension", "statusListIndex": "1", "statusListCredential": "https://example.com/status/1"}`)
+
+		assert.NoError(t, err)
+	})
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := parse(t, `{"id": "https://example.com/status/1", "type": "RevocationList2020Status"}`)
+
+		assert.EqualError(t, err, "unsupported credentialStatus type: RevocationList2020Status")
+	})
+	t.Run("invalid index", func(t *testing.T) {
+		_, err := parse(t, `{"type": "BitstringStatusListEntry", "statusPurpose": "revocation", "statusListIndex": "-1", "statusListCredential": "https://example.com/status/1"}`)
+
+		assert.EqualError(t, err, "invalid BitstringStatusListEntry: invalid statusListIndex: -1")
+	})
+	t.Run("missing statusListCredential", func(t *testing.T) {
+		_, err := parse(t, `{"type": "BitstringStatusListEntry", "statusPurpose": "revocation", "statusListIndex": "1"}`)
+
+		assert.EqualError(t, err, "invalid BitstringStatusListEntry: statusListCredential is missing")
+	})
+	t.Run("unsupported statusSize", func(t *testing.T) {
+		_, err := parse(t, `{"type": "BitstringStatusListEntry", "statusPurpose": "message", "statusListIndex": "1", "statusSize": 2, "statusListCredential": "https://example.com/status/1"}`)
+
+		assert.EqualError(t, err, "invalid BitstringStatusListEntry: unsupported statusSize: 2")
+	})
+}
+
+func TestList(t *testing.T) {
+	id, _ := ssi.ParseURI("https://example.com/status/1")
+	issuer, _ := ssi.ParseURI("did:example:issuer")
+	list, err := NewList(*id, PurposeRevocation, 0)
+	require.NoError(t, err)
+	assert.Equal(t, MinimumSize, list.Bits.Len())
+
+	first, err := list.Assign()
+	require.NoError(t, err)
+	second, err := list.Assign()
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://example.com/status/1#1", second.ID.String())
+	entry, err := ParseEntry(*second)
+	require.NoError(t, err)
+	assert.Equal(t, Entry{
+		ID:                   &ssi.URI{URL: second.ID},
+		Type:                 EntryType,
+		StatusPurpose:        PurposeRevocation,
+		StatusListIndex:      "1",
+		StatusListCredential: *id,
+	}, *entry)
+	assert.NotEqual(t, first.ID, second.ID)
+
+	require.NoError(t, list.Set(1, true))
+	assert.EqualError(t, list.Set(2, true), "status list entry hasn't been assigned: 2")
+
+	t.Run("credential", func(t *testing.T) {
+		validFrom := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		credential, err := list.Credential(*issuer, validFrom)
+		require.NoError(t, err)
+		assert.Equal(t, 2, credential.Version())
+		assert.NoError(t, vc.W3CSpecValidator{}.Validate(*credential))
+		data, _ := json.Marshal(credential)
+		var members map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &members))
+		assert.Equal(t, []interface{}{vc.VCContextV2, ContextV1}, members["@context"])
+		assert.Equal(t, "2021-01-01T00:00:00Z", members["validFrom"])
+		assert.NotContains(t, members, "issuanceDate")
+		var actual vc.VerifiableCredential
+		require.NoError(t, json.Unmarshal(data, &actual))
+
+		parsed, err := ParseCredential(actual)
+
+		require.NoError(t, err)
+		assert.Equal(t, list.ID, parsed.ID)
+		assert.Equal(t, PurposeRevocation, parsed.Purpose)
+		assert.Equal(t, list.Bits, parsed.Bits)
+	})
+	t.Run("full", func(t *testing.T) {
+		list := &List{ID: *id, Purpose: PurposeSuspension, Bits: NewBitstring(8), Next: 8}
+
+		_, err := list.Assign()
+
+		assert.ErrorIs(t, err, ErrListFull)
+	})
+	t.Run("not a status list credential", func(t *testing.T) {
+		_, err := ParseCredential(vc.VerifiableCredential{Type: []ssi.URI{vc.VerifiableCredentialTypeV1URI()}})
+
+		assert.EqualError(t, err, "not a status list credential")
+	})
+}
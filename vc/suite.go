@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"crypto"
+	"encoding/json"
+	"sync"
+
+	ssi "github.com/ugradid/ugradid-common"
+)
+
+// ProofSuite creates and verifies proofs of a specific proof type and, for DataIntegrityProof, cryptosuite.
+type ProofSuite interface {
+	// ProofType returns the type of the proofs created by the suite.
+	ProofType() ssi.ProofType
+	// Cryptosuite returns the cryptosuite of DataIntegrityProof suites, or an empty string for other proof types.
+	Cryptosuite() string
+	// Sign signs the document (without its proof) and returns the proof to add to the document's proof set,
+	// which is the given proof completed with the suite's type and the signature.
+	Sign(document interface{}, proof Proof, signer crypto.Signer) (interface{}, error)
+	// Verify verifies the proof, given as JSON, over the document (without its proof) using the public key.
+	Verify(document interface{}, proof json.RawMessage, publicKey crypto.PublicKey) error
+}
+
+type suiteKey struct {
+	proofType   ssi.ProofType
+	cryptosuite string
+}
+
+var suites = struct {
+	mux      sync.RWMutex
+	registry map[suiteKey]ProofSuite
+}{registry: make(map[suiteKey]ProofSuite)}
+
+func init() {
+	RegisterProofSuite(JSONWebSignature2020Suite{})
+	RegisterProofSuite(Ed25519Signature2020Suite{})
+	RegisterProofSuite(EdDSAJCS2022Suite{})
+	RegisterProofSuite(EcdsaSecp256k1Signature2019Suite{})
+	RegisterProofSuite(EcdsaJCS2019Suite{})
+	RegisterProofSuite(BbsBlsSignature2020Suite{})
+	RegisterProofSuite(BbsBlsSignatureProof2020Suite{})
+}
+
+// RegisterProofSuite registers the suite for verifying proofs of its type and cryptosuite,
+// replacing any suite registered for them before.
+func RegisterProofSuite(suite ProofSuite) {
+	suites.mux.Lock()
+	defer suites.mux.Unlock()
+	suites.registry[suiteKey{suite.ProofType(), suite.Cryptosuite()}] = suite
+}
+
+// FindProofSuite returns the suite registered for the given proof type and cryptosuite, or nil if there's none.
+// For proof types other than DataIntegrityProof, the cryptosuite is empty.
+func FindProofSuite(proofType ssi.ProofType, cryptosuite string) ProofSuite {
+	suites.mux.RLock()
+	defer suites.mux.RUnlock()
+	return suites.registry[suiteKey{proofType, cryptosuite}]
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrCredentialInvalid indicates credential validation failed
+var ErrCredentialInvalid = validationError{}
+
+// ErrInvalidContext indicates the credential's `@context` is invalid (e.g. the v1 or v2 VC context isn't the first context)
+var ErrInvalidContext = errors.New("invalid context")
+
+// ErrInvalidType indicates the credential's `type` is invalid (e.g. it doesn't contain `VerifiableCredential`)
+var ErrInvalidType = errors.New("invalid type")
+
+// ErrInvalidIssuer indicates the credential's `issuer` is invalid (e.g. it isn't an absolute URI)
+var ErrInvalidIssuer = errors.New("invalid issuer")
+
+// ErrInvalidIssuanceDate indicates the credential's `issuanceDate` is invalid (e.g. it is missing)
+var ErrInvalidIssuanceDate = errors.New("invalid issuanceDate")
+
+// ErrInvalidExpirationDate indicates the credential's `expirationDate` is invalid (e.g. it is before the issuanceDate)
+var ErrInvalidExpirationDate = errors.New("invalid expirationDate")
+
+// ErrInvalidValidUntil indicates the credential's `validUntil` is invalid (e.g. it is before the validFrom)
+var ErrInvalidValidUntil = errors.New("invalid validUntil")
+
+// ErrInvalidCredentialSubject indicates the credential's `credentialSubject` is invalid (e.g. it is empty)
+var ErrInvalidCredentialSubject = errors.New("invalid credentialSubject")
+
+// ErrInvalidCredentialStatus indicates the credential's `credentialStatus` is invalid (e.g. invalid `id` or `type`)
+var ErrInvalidCredentialStatus = errors.New("invalid credentialStatus")
+
+// ErrInvalidCredentialSchema indicates the credential's `credentialSchema` is invalid (e.g. invalid `id` or `type`)
+var ErrInvalidCredentialSchema = errors.New("invalid credentialSchema")
+
+// ErrInvalidRelatedResource indicates the credential's `relatedResource` is invalid (e.g. invalid `id` or no digest)
+var ErrInvalidRelatedResource = errors.New("invalid relatedResource")
+
+// Validator defines functions for validating a credential.
+type Validator interface {
+	// Validate validates a credential. It returns the first validation error is finds wrapped in ErrCredentialInvalid.
+	Validate(credential VerifiableCredential) error
+}
+
+// MultiValidator is a validator that executes zero or more validators. It returns the first validation error it encounters.
+type MultiValidator struct {
+	Validators []Validator
+}
+
+func (m MultiValidator) Validate(credential VerifiableCredential) error {
+	for _, validator := range m.Validators {
+		if err := validator.Validate(credential); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// W3CSpecValidator validates a credential according to the W3C Verifiable Credentials Data Model specification (https://www.w3.org/TR/vc-data-model/),
+// or version 2.0 of it (https://www.w3.org/TR/vc-data-model-2.0/) for credentials with the v2 context.
+type W3CSpecValidator struct {
+}
+
+func (w W3CSpecValidator) Validate(credential VerifiableCredential) error {
+	return MultiValidator{[]Validator{
+		baseValidator{},
+		validityValidator{},
+		credentialSubjectValidator{},
+		credentialStatusValidator{},
+		credentialSchemaValidator{},
+		relatedResourceValidator{},
+	}}.Validate(credential)
+}
+
+// baseValidator validates simple top-level credential properties (@context, type, issuer)
+type baseValidator struct{}
+
+func (b baseValidator) Validate(credential VerifiableCredential) error {
+	// Verify `@context`
+	if len(credential.Context) == 0 || (credential.Context[0].String() != VCContextV1 && credential.Context[0].String() != VCContextV2) {
+		return makeValidationError(ErrInvalidContext)
+	}
+	// Verify `type`
+	if !credential.IsType(VerifiableCredentialTypeV1URI()) {
+		return makeValidationError(ErrInvalidType)
+	}
+	// Verify `issuer`
+	if credential.Issuer.Scheme == "" || len(strings.TrimSpace(credential.Issuer.String())) == 0 {
+		return makeValidationError(ErrInvalidIssuer)
+	}
+	return nil
+}
+
+// validityValidator validates the issuanceDate and expirationDate, or the validFrom and validUntil of 2.0 credentials
+type validityValidator struct{}
+
+func (v validityValidator) Validate(credential VerifiableCredential) error {
+	if credential.Version() == 2 {
+		if credential.ValidFrom != nil && credential.ValidUntil != nil && credential.ValidUntil.Before(*credential.ValidFrom) {
+			return makeValidationError(ErrInvalidValidUntil)
+		}
+		return nil
+	}
+	if credential.IssuanceDate.IsZero() {
+		return makeValidationError(ErrInvalidIssuanceDate)
+	}
+	if credential.ExpirationDate != nil && credential.ExpirationDate.Before(credential.IssuanceDate) {
+		return makeValidationError(ErrInvalidExpirationDate)
+	}
+	return nil
+}
+
+type credentialSubjectValidator struct{}
+
+func (c credentialSubjectValidator) Validate(credential VerifiableCredential) error {
+	if len(credential.CredentialSubject) == 0 {
+		return makeValidationError(ErrInvalidCredentialSubject)
+	}
+	for _, subject := range credential.AdditionalSubjects {
+		if len(subject) == 0 {
+			return makeValidationError(ErrInvalidCredentialSubject)
+		}
+	}
+	return nil
+}
+
+type credentialStatusValidator struct{}
+
+func (c credentialStatusValidator) Validate(credential VerifiableCredential) error {
+	status := credential.CredentialStatus
+	if status == nil {
+		return nil
+	}
+	// The id is optional for 2.0 credentials
+	if status.ID.Scheme == "" && (credential.Version() == 1 || status.ID.String() != "") {
+		return makeValidationError(ErrInvalidCredentialStatus)
+	}
+	if len(strings.TrimSpace(status.Type)) == 0 {
+		return makeValidationError(ErrInvalidCredentialStatus)
+	}
+	return nil
+}
+
+type credentialSchemaValidator struct{}
+
+func (c credentialSchemaValidator) Validate(credential VerifiableCredential) error {
+	for _, schema := range credential.Schemas() {
+		if len(strings.TrimSpace(schema.ID.String())) == 0 {
+			return makeValidationError(ErrInvalidCredentialSchema)
+		}
+		if len(strings.TrimSpace(string(schema.Type))) == 0 {
+			return makeValidationError(ErrInvalidCredentialSchema)
+		}
+	}
+	return nil
+}
+
+type relatedResourceValidator struct{}
+
+func (r relatedResourceValidator) Validate(credential VerifiableCredential) error {
+	for _, resource := range credential.RelatedResource {
+		if len(strings.TrimSpace(resource.ID.String())) == 0 {
+			return makeValidationError(ErrInvalidRelatedResource)
+		}
+		if resource.DigestSRI == "" && resource.DigestMultibase == "" {
+			return makeValidationError(ErrInvalidRelatedResource)
+		}
+	}
+	return nil
+}
+
+func makeValidationError(validationErr error) error {
+	return validationError{cause: validationErr}
+}
+
+type validationError struct {
+	cause error
+}
+
+func (v validationError) Unwrap() error {
+	return v.cause
+}
+
+func (v validationError) Is(err error) bool {
+	_, is := err.(validationError)
+	return is
+}
+
+func (v validationError) Error() string {
+	return fmt.Sprintf("credential validation failed: %v", v.cause)
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ssi "github.com/ugradid/ugradid-common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestW3CSpecValidator(t *testing.T) {
+	assert.NoError(t, W3CSpecValidator{}.Validate(testCredential()))
+
+	testCases := []struct {
+		name     string
+		modifier func(credential *VerifiableCredential)
+		expected error
+	}{
+		{"context missing", func(c *VerifiableCredential) {
+			c.Context = nil
+		}, ErrInvalidContext},
+		{"VC context isn't first", func(c *VerifiableCredential) {
+			c.Context = []ssi.URI{*mustParseURI("https://example.com/context"), VCContextV1URI()}
+		}, ErrInvalidContext},
+		{"VerifiableCredential type missing", func(c *VerifiableCredential) {
+			c.Type = []ssi.URI{*mustParseURI("ExampleCredential")}
+		}, ErrInvalidType},
+		{"issuer missing", func(c *VerifiableCredential) {
+			c.Issuer = ssi.URI{}
+		}, ErrInvalidIssuer},
+		{"issuer isn't an absolute URI", func(c *VerifiableCredential) {
+			c.Issuer = *mustParseURI("issuer")
+		}, ErrInvalidIssuer},
+		{"issuanceDate missing", func(c *VerifiableCredential) {
+			c.IssuanceDate = time.Time{}
+		}, ErrInvalidIssuanceDate},
+		{"expirationDate before issuanceDate", func(c *VerifiableCredential) {
+			expirationDate := c.IssuanceDate.Add(-time.Second)
+			c.ExpirationDate = &expirationDate
+		}, ErrInvalidExpirationDate},
+		{"credentialSubject empty", func(c *VerifiableCredential) {
+			c.CredentialSubject = map[string]interface{}{}
+		}, ErrInvalidCredentialSubject},
+		{"additional credentialSubject empty", func(c *VerifiableCredential) {
+			c.AdditionalSubjects = []map[string]interface{}{{}}
+		}, ErrInvalidCredentialSubject},
+		{"credentialStatus without id", func(c *VerifiableCredential) {
+			c.CredentialStatus = &CredentialStatus{Type: "BitstringStatusListEntry"}
+		}, ErrInvalidCredentialStatus},
+		{"credentialStatus without type", func(c *VerifiableCredential) {
+			c.CredentialStatus = &CredentialStatus{ID: mustParseURI("https://example.com/status/1#1").URL}
+		}, ErrInvalidCredentialStatus},
+		{"credentialSchema without id", func(c *VerifiableCredential) {
+			c.CredentialSchema = &CredentialSchema{ID: *mustParseURI("https://example.com/schema.json"), Type: "JsonSchema"}
+			c.AdditionalSchemas = []CredentialSchema{{Type: "JsonSchemaValidator2018"}}
+		}, ErrInvalidCredentialSchema},
+		{"credentialSchema without type", func(c *VerifiableCredential) {
+			c.CredentialSchema = &CredentialSchema{ID: *mustParseURI("https://example.com/schema.json")}
+		}, ErrInvalidCredentialSchema},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			credential := testCredential()
+			testCase.modifier(&credential)
+
+			err := W3CSpecValidator{}.Validate(credential)
+
+			assert.ErrorIs(t, err, ErrCredentialInvalid)
+			assert.ErrorIs(t, err, testCase.expected)
+		})
+	}
+	t.Run("2.0", func(t *testing.T) {
+		validFrom := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		credential := testCredential()
+		credential.Context = []ssi.URI{VCContextV2URI()}
+		credential.IssuanceDate = time.Time{}
+		credential.ValidFrom = &validFrom
+		credential.CredentialStatus = &CredentialStatus{Type: "BitstringStatusListEntry"}
+
+		assert.NoError(t, W3CSpecValidator{}.Validate(credential))
+
+		validUntil := validFrom.Add(-time.Second)
+		credential.ValidUntil = &validUntil
+		assert.ErrorIs(t, W3CSpecValidator{}.Validate(credential), ErrInvalidValidUntil)
+	})
+	t.Run("relatedResource without digest", func(t *testing.T) {
+		credential := testCredential()
+		credential.RelatedResource = []RelatedResource{{ID: *mustParseURI("https://example.com/image.png")}}
+
+		assert.ErrorIs(t, W3CSpecValidator{}.Validate(credential), ErrInvalidRelatedResource)
+	})
+	t.Run("error message", func(t *testing.T) {
+		err := W3CSpecValidator{}.Validate(VerifiableCredential{})
+
+		assert.EqualError(t, err, "credential validation failed: invalid context")
+		assert.False(t, errors.Is(errors.New("other"), ErrCredentialInvalid))
+	})
+}
+
+func mustParseURI(input string) *ssi.URI {
+	uri, err := ssi.ParseURI(input)
+	if err != nil {
+		panic(err)
+	}
+	return uri
+}
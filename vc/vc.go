@@ -18,12 +18,22 @@
 package vc
 
 import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
-	ssi "github.com/ugradid/ugradid-common"
-	"github.com/ugradid/ugradid-common/marshal"
+	"hash"
 	"net/url"
+	"strings"
 	"time"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/marshal"
+	"github.com/ugradid/ugradid-common/multiformat"
 )
 
 // VerifiableCredentialType is the default credential type required for every credential
@@ -62,7 +72,21 @@ func VCContextV1URI() ssi.URI {
 	}
 }
 
-// VerifiableCredential represents a credential as defined by the Verifiable Credentials Data Model 1.0 specification (https://www.w3.org/TR/vc-data-model/).
+// VCContextV2 is the context required for every credential of the Verifiable Credentials Data Model 2.0
+const VCContextV2 = "https://www.w3.org/ns/credentials/v2"
+
+// VCContextV2URI returns 'https://www.w3.org/ns/credentials/v2' as URI
+func VCContextV2URI() ssi.URI {
+	if pURI, err := ssi.ParseURI(VCContextV2); err != nil {
+		panic(err)
+	} else {
+		return *pURI
+	}
+}
+
+// VerifiableCredential represents a credential as defined by the Verifiable Credentials Data Model 1.1 specification (https://www.w3.org/TR/vc-data-model/)
+// and its successor, Verifiable Credentials Data Model 2.0 (https://www.w3.org/TR/vc-data-model-2.0/). The version of a credential is
+// determined by its first context, see Version.
 type VerifiableCredential struct {
 	// Context defines the json-ld context to dereference the URIs
 	Context []ssi.URI `json:"@context"`
@@ -70,18 +94,40 @@ type VerifiableCredential struct {
 	ID *ssi.URI `json:"id,omitempty"`
 	// Type holds multiplte types for a credential. A credential must always have the 'VerifiableCredential' type.
 	Type []ssi.URI `json:"type"`
-	// Issuer refers to the party that issued the credential
+	// Name is a human-readable name of the credential. It is optional
+	Name string `json:"name,omitempty"`
+	// Description is a human-readable description of the credential. It is optional
+	Description string `json:"description,omitempty"`
+	// Issuer refers to the party that issued the credential. When the issuer is expressed as object, it holds its id.
 	Issuer ssi.URI `json:"issuer"`
-	// IssuanceDate is a rfc3339 formatted datetime.
+	// IssuerProperties holds the other members of the issuer when it is expressed as object, e.g. its name and image.
+	// The issuer is marshalled as object when it has properties. Use IssuerMetadata to read the common ones.
+	IssuerProperties map[string]interface{} `json:"-"`
+	// IssuanceDate is a rfc3339 formatted datetime. It is required by the 1.1 data model and omitted when zero,
+	// since 2.0 credentials use ValidFrom instead.
 	IssuanceDate time.Time `json:"issuanceDate"`
-	// ExpirationDate is a rfc3339 formatted datetime. It is optional
+	// ExpirationDate is a rfc3339 formatted datetime. It is optional. 2.0 credentials use ValidUntil instead.
 	ExpirationDate *time.Time `json:"expirationDate,omitempty"`
+	// ValidFrom is a rfc3339 formatted datetime from which the 2.0 credential is valid. It is optional
+	ValidFrom *time.Time `json:"validFrom,omitempty"`
+	// ValidUntil is a rfc3339 formatted datetime until which the 2.0 credential is valid. It is optional
+	ValidUntil *time.Time `json:"validUntil,omitempty"`
 	// CredentialStatus holds information on how the credential can be revoked. It is optional
 	CredentialStatus *CredentialStatus `json:"credentialStatus,omitempty"`
-	// CredentialSchema holds information schema credential subject
+	// CredentialSchema holds information schema credential subject. It is optional
+	// When the credential has multiple schemas, it holds the first one.
 	CredentialSchema *CredentialSchema `json:"credentialSchema,omitempty"`
+	// AdditionalSchemas holds the schemas following the first one, when the credential has multiple schemas.
+	// The credentialSchema is marshalled as array when there are additional schemas. Use Schemas to iterate all schemas.
+	AdditionalSchemas []CredentialSchema `json:"-"`
 	// CredentialSubject holds the actual data for the credential. It must be extracted using the UnmarshalCredentialSubject method and a custom type.
+	// When the credential has multiple subjects, it holds the first one.
 	CredentialSubject map[string]interface{} `json:"credentialSubject"`
+	// AdditionalSubjects holds the subjects following the first one, when the credential has multiple subjects.
+	// The credentialSubject is marshalled as array when there are additional subjects. Use Subjects to iterate all subjects.
+	AdditionalSubjects []map[string]interface{} `json:"-"`
+	// RelatedResource holds resources the credential refers to, with their digests to protect their integrity. It is optional
+	RelatedResource []RelatedResource `json:"relatedResource,omitempty"`
 	// Proof contains the cryptographic proof(s). It must be extracted using the Proofs method or UnmarshalProofValue method for non-generic proof fields.
 	Proof []interface{} `json:"proof"`
 }
@@ -90,6 +136,57 @@ type VerifiableCredential struct {
 type CredentialStatus struct {
 	ID   url.URL `json:"id"`
 	Type string  `json:"type"`
+	// Properties holds the other members of the status, which are specific to its type.
+	// For example: statusPurpose, statusListIndex and statusListCredential of a BitstringStatusListEntry.
+	Properties map[string]interface{} `json:"-"`
+}
+
+func (s CredentialStatus) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(s.Properties)+2)
+	for name, value := range s.Properties {
+		members[name] = value
+	}
+	if s.ID.String() != "" {
+		members["id"] = s.ID.String()
+	}
+	members[typeKey] = s.Type
+	return json.Marshal(members)
+}
+
+func (s *CredentialStatus) UnmarshalJSON(b []byte) error {
+	type Alias CredentialStatus
+	tmp := struct {
+		Alias
+		ID string `json:"id"`
+	}{}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	id, err := url.Parse(tmp.ID)
+	if err != nil {
+		return fmt.Errorf("could not parse credentialStatus id: %w", err)
+	}
+	tmp.Alias.ID = *id
+	var members map[string]interface{}
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+	delete(members, "id")
+	delete(members, typeKey)
+	if len(members) > 0 {
+		tmp.Properties = members
+	}
+	*s = (CredentialStatus)(tmp.Alias)
+	return nil
+}
+
+// UnmarshalProperties unmarshalls the status, including its type specific properties, to the given status type.
+func (s CredentialStatus) UnmarshalProperties(target interface{}) error {
+	if asJSON, err := json.Marshal(s); err != nil {
+		return err
+	} else {
+		return json.Unmarshal(asJSON, target)
+	}
 }
 
 // CredentialSchema defines for schema subject.
@@ -98,6 +195,83 @@ type CredentialSchema struct {
 	Type ssi.SchemaType `json:"type"`
 }
 
+// RelatedResource is a resource a credential refers to, e.g. an image or a context, of which the integrity is
+// protected by its digest (https://www.w3.org/TR/vc-data-model-2.0/#integrity-of-related-resources).
+type RelatedResource struct {
+	ID ssi.URI `json:"id"`
+	// MediaType is the media type of the resource, e.g. image/png. It is optional
+	MediaType string `json:"mediaType,omitempty"`
+	// DigestSRI is the digest of the resource in Subresource Integrity format, e.g. 'sha384-...'.
+	DigestSRI string `json:"digestSRI,omitempty"`
+	// DigestMultibase is the digest of the resource as multibase encoded multihash.
+	DigestMultibase string `json:"digestMultibase,omitempty"`
+}
+
+// VerifyDigest verifies the digests of the resource match the given content of the resource.
+// SHA-256, SHA-384 and SHA-512 digests are supported.
+func (r RelatedResource) VerifyDigest(content []byte) error {
+	if r.DigestSRI == "" && r.DigestMultibase == "" {
+		return errors.New("related resource has no digest")
+	}
+	if r.DigestSRI != "" {
+		separator := strings.Index(r.DigestSRI, "-")
+		if separator < 0 {
+			return errors.New("invalid digestSRI")
+		}
+		newHash, ok := sriHashes[r.DigestSRI[:separator]]
+		if !ok {
+			return fmt.Errorf("unsupported digestSRI algorithm: %s", r.DigestSRI[:separator])
+		}
+		expected, err := base64.StdEncoding.DecodeString(r.DigestSRI[separator+1:])
+		if err != nil {
+			return fmt.Errorf("invalid digestSRI: %w", err)
+		}
+		if !equalDigest(newHash, content, expected) {
+			return errors.New("related resource does not match digestSRI")
+		}
+	}
+	if r.DigestMultibase != "" {
+		_, multihash, err := multiformat.DecodeMultibase(r.DigestMultibase)
+		if err != nil {
+			return fmt.Errorf("invalid digestMultibase: %w", err)
+		}
+		codec, rest, err := multiformat.SplitCodecPrefix(multihash)
+		if err != nil {
+			return fmt.Errorf("invalid digestMultibase: %w", err)
+		}
+		newHash, ok := multihashHashes[codec]
+		if !ok {
+			return fmt.Errorf("unsupported digestMultibase hash: %s", codec)
+		}
+		length, n := binary.Uvarint(rest)
+		if n <= 0 || uint64(len(rest)-n) != length {
+			return errors.New("invalid digestMultibase: invalid digest length")
+		}
+		if !equalDigest(newHash, content, rest[n:]) {
+			return errors.New("related resource does not match digestMultibase")
+		}
+	}
+	return nil
+}
+
+var sriHashes = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+var multihashHashes = map[multiformat.Codec]func() hash.Hash{
+	multiformat.SHA2256: sha256.New,
+	multiformat.SHA2384: sha512.New384,
+	multiformat.SHA2512: sha512.New,
+}
+
+func equalDigest(newHash func() hash.Hash, content []byte, expected []byte) bool {
+	digest := newHash()
+	digest.Write(content)
+	return subtle.ConstantTimeCompare(digest.Sum(nil), expected) == 1
+}
+
 // Proofs returns the basic proofs for this credential. For specific proof contents, UnmarshalProofValue must be used.
 func (vc VerifiableCredential) Proofs() ([]Proof, error) {
 	var (
@@ -116,28 +290,99 @@ func (vc VerifiableCredential) Proofs() ([]Proof, error) {
 
 func (vc VerifiableCredential) MarshalJSON() ([]byte, error) {
 	type alias VerifiableCredential
-	tmp := alias(vc)
+	tmp := struct {
+		alias
+		Issuer            interface{} `json:"issuer"`
+		CredentialSchema  interface{} `json:"credentialSchema,omitempty"`
+		CredentialSubject interface{} `json:"credentialSubject"`
+	}{alias: alias(vc), Issuer: vc.Issuer, CredentialSubject: vc.CredentialSubject}
+	if schemas := vc.Schemas(); len(schemas) > 0 {
+		tmp.CredentialSchema = schemas
+	}
+	if len(vc.IssuerProperties) > 0 {
+		issuer := make(map[string]interface{}, len(vc.IssuerProperties)+1)
+		for name, value := range vc.IssuerProperties {
+			issuer[name] = value
+		}
+		issuer["id"] = vc.Issuer
+		tmp.Issuer = issuer
+	}
+	if len(vc.AdditionalSubjects) > 0 {
+		subjects := make([]interface{}, 0, len(vc.AdditionalSubjects)+1)
+		for _, subject := range vc.Subjects() {
+			subjects = append(subjects, subject)
+		}
+		tmp.CredentialSubject = subjects
+	}
 	if data, err := json.Marshal(tmp); err != nil {
 		return nil, err
 	} else {
-		return marshal.NormalizeDocument(data, pluralContext,
-			marshal.Unplural(typeKey), marshal.Unplural(credentialSubjectKey), marshal.Unplural(proofKey))
+		return marshal.NormalizeDocument(data, pluralContext, omitZeroIssuanceDate,
+			marshal.Unplural(typeKey), marshal.Unplural(credentialSubjectKey), marshal.Unplural(credentialSchemaKey), marshal.Unplural(proofKey))
 	}
 }
 
 func (vc *VerifiableCredential) UnmarshalJSON(b []byte) error {
 	type Alias VerifiableCredential
 	normalizedVC, err := marshal.NormalizeDocument(b,
-		pluralContext, marshal.Plural(typeKey), marshal.Unplural(credentialSubjectKey), marshal.Plural(proofKey))
+		pluralContext, marshal.Plural(typeKey), marshal.Plural(credentialSubjectKey), marshal.Plural(credentialSchemaKey), marshal.Plural(proofKey))
 	if err != nil {
 		return err
 	}
-	tmp := Alias{}
+	tmp := struct {
+		Alias
+		Issuer            json.RawMessage          `json:"issuer"`
+		CredentialSchema  []CredentialSchema       `json:"credentialSchema"`
+		CredentialSubject []map[string]interface{} `json:"credentialSubject"`
+	}{}
 	err = json.Unmarshal(normalizedVC, &tmp)
 	if err != nil {
 		return err
 	}
-	*vc = (VerifiableCredential)(tmp)
+	*vc = (VerifiableCredential)(tmp.Alias)
+	if err := vc.unmarshalIssuer(tmp.Issuer); err != nil {
+		return err
+	}
+	if len(tmp.CredentialSchema) > 0 {
+		vc.CredentialSchema = &tmp.CredentialSchema[0]
+	}
+	if len(tmp.CredentialSchema) > 1 {
+		vc.AdditionalSchemas = tmp.CredentialSchema[1:]
+	}
+	if len(tmp.CredentialSubject) > 0 {
+		vc.CredentialSubject = tmp.CredentialSubject[0]
+	}
+	if len(tmp.CredentialSubject) > 1 {
+		vc.AdditionalSubjects = tmp.CredentialSubject[1:]
+	}
+	return nil
+}
+
+// unmarshalIssuer unmarshalls the issuer, which is either a URI or an object with an id and other properties.
+func (vc *VerifiableCredential) unmarshalIssuer(data json.RawMessage) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	if data[0] != '{' {
+		return json.Unmarshal(data, &vc.Issuer)
+	}
+	var issuer map[string]interface{}
+	if err := json.Unmarshal(data, &issuer); err != nil {
+		return err
+	}
+	id, ok := issuer["id"].(string)
+	if !ok {
+		return errors.New("issuer object must have an id")
+	}
+	parsed, err := ssi.ParseURI(id)
+	if err != nil {
+		return fmt.Errorf("could not parse issuer id: %w", err)
+	}
+	vc.Issuer = *parsed
+	delete(issuer, "id")
+	if len(issuer) > 0 {
+		vc.IssuerProperties = issuer
+	}
 	return nil
 }
 
@@ -151,7 +396,8 @@ func (vc VerifiableCredential) UnmarshalProofValue(target interface{}) error {
 	}
 }
 
-// UnmarshalCredentialSubject unmarshalls the credentialSubject to the given credentialSubject type. Always pass a slice as target.
+// UnmarshalCredentialSubject unmarshalls the (first) credentialSubject to the given credentialSubject type.
+// Use UnmarshalCredentialSubjects for credentials with multiple subjects.
 func (vc VerifiableCredential) UnmarshalCredentialSubject(target interface{}) error {
 	if asJSON, err := json.Marshal(vc.CredentialSubject); err != nil {
 		return err
@@ -160,6 +406,99 @@ func (vc VerifiableCredential) UnmarshalCredentialSubject(target interface{}) er
 	}
 }
 
+// UnmarshalCredentialSubjects unmarshalls all subjects of the credential to the given credentialSubject type. Always pass a slice as target.
+func (vc VerifiableCredential) UnmarshalCredentialSubjects(target interface{}) error {
+	if asJSON, err := json.Marshal(vc.Subjects()); err != nil {
+		return err
+	} else {
+		return json.Unmarshal(asJSON, target)
+	}
+}
+
+// Subjects returns all subjects of the credential: the CredentialSubject followed by the AdditionalSubjects.
+func (vc VerifiableCredential) Subjects() []map[string]interface{} {
+	if vc.CredentialSubject == nil && len(vc.AdditionalSubjects) == 0 {
+		return nil
+	}
+	return append([]map[string]interface{}{vc.CredentialSubject}, vc.AdditionalSubjects...)
+}
+
+// Schemas returns all schemas of the credential: the CredentialSchema followed by the AdditionalSchemas.
+func (vc VerifiableCredential) Schemas() []CredentialSchema {
+	var schemas []CredentialSchema
+	if vc.CredentialSchema != nil {
+		schemas = append(schemas, *vc.CredentialSchema)
+	}
+	return append(schemas, vc.AdditionalSchemas...)
+}
+
+// IssuerMetadata holds metadata of an issuer expressed as object.
+type IssuerMetadata struct {
+	// Name is the name of the issuer. It is empty when the issuer has no name.
+	Name string
+	// Description is the description of the issuer. It is empty when the issuer has no description.
+	Description string
+	// Image is the URL of the issuer's image, which may be a data URL. It is nil when the issuer has no image.
+	Image *ssi.URI
+}
+
+// IssuerMetadata returns the name, description and image of the issuer when it is expressed as object.
+// Language maps are supported for the name and description, of which the first value is returned.
+func (vc VerifiableCredential) IssuerMetadata() IssuerMetadata {
+	metadata := IssuerMetadata{
+		Name:        languageValue(vc.IssuerProperties["name"]),
+		Description: languageValue(vc.IssuerProperties["description"]),
+	}
+	image := vc.IssuerProperties["image"]
+	if object, ok := image.(map[string]interface{}); ok {
+		image = object["id"]
+	}
+	if imageURL, ok := image.(string); ok {
+		metadata.Image, _ = ssi.ParseURI(imageURL)
+	}
+	return metadata
+}
+
+// languageValue returns the string value of a property that is either a string, a value object with a @value
+// or a list of those, in which case the first value is returned.
+func languageValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		result, _ := v["@value"].(string)
+		return result
+	case []interface{}:
+		if len(v) > 0 {
+			return languageValue(v[0])
+		}
+	}
+	return ""
+}
+
+// Version returns the major version of the Verifiable Credentials Data Model the credential conforms to:
+// 2 when its first context is VCContextV2, otherwise 1.
+func (vc VerifiableCredential) Version() int {
+	if len(vc.Context) > 0 && vc.Context[0].String() == VCContextV2 {
+		return 2
+	}
+	return 1
+}
+
+// Validity returns the start and, when the credential expires, the end of the credential's validity period:
+// validFrom and validUntil of 2.0 credentials, or issuanceDate and expirationDate of 1.1 credentials.
+// The start is zero when a 2.0 credential has no validFrom.
+func (vc VerifiableCredential) Validity() (time.Time, *time.Time) {
+	if vc.Version() == 1 {
+		return vc.IssuanceDate, vc.ExpirationDate
+	}
+	var validFrom time.Time
+	if vc.ValidFrom != nil {
+		validFrom = *vc.ValidFrom
+	}
+	return validFrom, vc.ValidUntil
+}
+
 // IsType returns true when a credential contains the requested type
 func (vc VerifiableCredential) IsType(vcType ssi.URI) bool {
 	for _, t := range vc.Type {
@@ -185,3 +524,10 @@ func (vc VerifiableCredential) ContainsContext(context ssi.URI) bool {
 func GenerateCredentialID(issuer ssi.URI, id string) string {
 	return fmt.Sprintf("%s#%s", issuer.String(), id)
 }
+
+// omitZeroIssuanceDate removes the issuanceDate when it isn't set, e.g. of 2.0 credentials.
+func omitZeroIssuanceDate(m map[string]interface{}) {
+	if m[issuanceDateKey] == (time.Time{}).Format(time.RFC3339Nano) {
+		delete(m, issuanceDateKey)
+	}
+}
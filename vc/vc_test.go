@@ -0,0 +1,251 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ugradid/ugradid-common/multiformat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCredentialV2 = `{
+  "@context": ["https://www.w3.org/ns/credentials/v2", "https://www.w3.org/ns/credentials/examples/v2"],
+  "id": "http://university.example/credentials/3732",
+  "type": ["VerifiableCredential", "ExampleDegreeCredential"],
+  "name": "Example Degree",
+  "description": "A degree issued by the example university",
+  "issuer": "https://university.example/issuers/565049",
+  "validFrom": "2010-01-01T00:00:00Z",
+  "validUntil": "2030-01-01T00:00:00Z",
+  "credentialSchema": [{
+    "id": "https://example.org/examples/degree.json",
+    "type": "JsonSchema"
+  }, {
+    "id": "https://example.org/examples/alumni.json",
+    "type": "JsonSchema"
+  }],
+  "credentialSubject": {
+    "id": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+    "degree": {"type": "ExampleBachelorDegree", "name": "Bachelor of Science and Arts"}
+  },
+  "relatedResource": [{
+    "id": "https://university.example/images/logo.png",
+    "digestSRI": "sha384-Ml/HrjlBCNWyAX91hr6LFV2Y3heB5Tcr6IeE4/Tje8YyzYBM8IhqjHWiWpr8+ZbYU"
+  }]
+}`
+
+func TestVerifiableCredential_UnmarshalJSON(t *testing.T) {
+	t.Run("2.0", func(t *testing.T) {
+		var credential VerifiableCredential
+
+		err := json.Unmarshal([]byte(testCredentialV2), &credential)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, credential.Version())
+		assert.Equal(t, "Example Degree", credential.Name)
+		assert.Equal(t, "A degree issued by the example university", credential.Description)
+		assert.True(t, credential.IssuanceDate.IsZero())
+		require.NotNil(t, credential.ValidFrom)
+		assert.Equal(t, time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), *credential.ValidFrom)
+		validFrom, validUntil := credential.Validity()
+		assert.Equal(t, *credential.ValidFrom, validFrom)
+		assert.Equal(t, credential.ValidUntil, validUntil)
+		require.NotNil(t, credential.CredentialSchema)
+		assert.Equal(t, "https://example.org/examples/degree.json", credential.CredentialSchema.ID.String())
+		require.Len(t, credential.Schemas(), 2)
+		assert.Equal(t, "https://example.org/examples/alumni.json", credential.Schemas()[1].ID.String())
+		require.Len(t, credential.RelatedResource, 1)
+		assert.NoError(t, W3CSpecValidator{}.Validate(credential))
+
+		t.Run("marshal", func(t *testing.T) {
+			data, err := json.Marshal(credential)
+
+			require.NoError(t, err)
+			var members map[string]interface{}
+			require.NoError(t, json.Unmarshal(data, &members))
+			assert.NotContains(t, members, "issuanceDate")
+			assert.Equal(t, "2030-01-01T00:00:00Z", members["validUntil"])
+			assert.Len(t, members["credentialSchema"], 2)
+		})
+	})
+	t.Run("1.1", func(t *testing.T) {
+		credential := testCredential()
+		credential.CredentialSchema = &CredentialSchema{ID: *mustParseURI("https://example.com/schema.json"), Type: "JsonSchemaValidator2018"}
+		data, _ := json.Marshal(credential)
+		var members map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &members))
+		assert.Equal(t, "2021-01-01T00:00:00Z", members["issuanceDate"])
+		assert.IsType(t, map[string]interface{}{}, members["credentialSchema"])
+
+		var actual VerifiableCredential
+		require.NoError(t, json.Unmarshal(data, &actual))
+
+		assert.Equal(t, 1, actual.Version())
+		assert.Equal(t, credential.CredentialSchema, actual.CredentialSchema)
+		assert.Empty(t, actual.AdditionalSchemas)
+		validFrom, validUntil := actual.Validity()
+		assert.Equal(t, credential.IssuanceDate, validFrom)
+		assert.Nil(t, validUntil)
+	})
+	t.Run("credentialStatus properties", func(t *testing.T) {
+		var status CredentialStatus
+
+		err := json.Unmarshal([]byte(`{"id": "https://example.com/status/1#1", "type": "BitstringStatusListEntry", "statusListIndex": "1"}`), &status)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/status/1#1", status.ID.String())
+		assert.Equal(t, map[string]interface{}{"statusListIndex": "1"}, status.Properties)
+		data, _ := json.Marshal(status)
+		assert.JSONEq(t, `{"id": "https://example.com/status/1#1", "type": "BitstringStatusListEntry", "statusListIndex": "1"}`, string(data))
+	})
+	t.Run("issuer object", func(t *testing.T) {
+		var credential VerifiableCredential
+		issuer := `{"id": "did:example:issuer", "name": "Example University", "image": "https://university.example/logo.png"}`
+
+		err := json.Unmarshal([]byte(`{"issuer": `+issuer+`, "credentialSubject": {"id": "did:example:subject"}}`), &credential)
+
+		require.NoError(t, err)
+		assert.Equal(t, "did:example:issuer", credential.Issuer.String())
+		assert.Equal(t, map[string]interface{}{"name": "Example University", "image": "https://university.example/logo.png"}, credential.IssuerProperties)
+		data, _ := json.Marshal(credential)
+		var members map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(data, &members))
+		assert.JSONEq(t, issuer, string(members["issuer"]))
+	})
+	t.Run("issuer object without id", func(t *testing.T) {
+		var credential VerifiableCredential
+
+		err := json.Unmarshal([]byte(`{"issuer": {"name": "Example University"}}`), &credential)
+
+		assert.EqualError(t, err, "issuer object must have an id")
+	})
+	t.Run("issuer without properties is marshalled as string", func(t *testing.T) {
+		data, _ := json.Marshal(testCredential())
+		var members map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &members))
+		assert.Equal(t, "did:example:issuer", members["issuer"])
+	})
+	t.Run("multiple credentialSubjects", func(t *testing.T) {
+		var credential VerifiableCredential
+		subjects := `[{"id": "did:example:alice", "spouse": "did:example:bob"}, {"id": "did:example:bob", "spouse": "did:example:alice"}]`
+
+		err := json.Unmarshal([]byte(`{"credentialSubject": `+subjects+`}`), &credential)
+
+		require.NoError(t, err)
+		assert.Equal(t, "did:example:alice", credential.CredentialSubject["id"])
+		require.Len(t, credential.AdditionalSubjects, 1)
+		assert.Equal(t, "did:example:bob", credential.AdditionalSubjects[0]["id"])
+		assert.Len(t, credential.Subjects(), 2)
+		data, _ := json.Marshal(credential)
+		var members map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(data, &members))
+		assert.JSONEq(t, subjects, string(members["credentialSubject"]))
+	})
+}
+
+func TestVerifiableCredential_IssuerMetadata(t *testing.T) {
+	t.Run("string values", func(t *testing.T) {
+		credential := VerifiableCredential{IssuerProperties: map[string]interface{}{
+			"name":        "Example University",
+			"description": "An example university",
+			"image":       "https://university.example/logo.png",
+		}}
+
+		metadata := credential.IssuerMetadata()
+
+		assert.Equal(t, "Example University", metadata.Name)
+		assert.Equal(t, "An example university", metadata.Description)
+		require.NotNil(t, metadata.Image)
+		assert.Equal(t, "https://university.example/logo.png", metadata.Image.String())
+	})
+	t.Run("language values and image object", func(t *testing.T) {
+		credential := VerifiableCredential{IssuerProperties: map[string]interface{}{
+			"name": []interface{}{
+				map[string]interface{}{"@value": "Example University", "@language": "en"},
+				map[string]interface{}{"@value": "Voorbeelduniversiteit", "@language": "nl"},
+			},
+			"image": map[string]interface{}{"id": "https://university.example/logo.png", "type": "Image"},
+		}}
+
+		metadata := credential.IssuerMetadata()
+
+		assert.Equal(t, "Example University", metadata.Name)
+		assert.Empty(t, metadata.Description)
+		require.NotNil(t, metadata.Image)
+		assert.Equal(t, "https://university.example/logo.png", metadata.Image.String())
+	})
+	t.Run("issuer without properties", func(t *testing.T) {
+		assert.Equal(t, IssuerMetadata{}, VerifiableCredential{}.IssuerMetadata())
+	})
+}
+
+func TestVerifiableCredential_UnmarshalCredentialSubjects(t *testing.T) {
+	type subject struct {
+		ID string `json:"id"`
+	}
+	credential := VerifiableCredential{
+		CredentialSubject:  map[string]interface{}{"id": "did:example:alice"},
+		AdditionalSubjects: []map[string]interface{}{{"id": "did:example:bob"}},
+	}
+	var target []subject
+
+	err := credential.UnmarshalCredentialSubjects(&target)
+
+	require.NoError(t, err)
+	assert.Equal(t, []subject{{ID: "did:example:alice"}, {ID: "did:example:bob"}}, target)
+}
+
+func TestRelatedResource_VerifyDigest(t *testing.T) {
+	content := []byte("related resource")
+	sha384 := sha512.Sum384(content)
+	sha256Digest := sha256.Sum256(content)
+	multihash := append(multiformat.AddCodecPrefix(multiformat.SHA2256, []byte{32}), sha256Digest[:]...)
+	digestMultibase, _ := multiformat.EncodeMultibase(multiformat.Base58BTC, multihash)
+	resource := RelatedResource{
+		ID:              *mustParseURI("https://example.com/image.png"),
+		DigestSRI:       "sha384-" + base64.StdEncoding.EncodeToString(sha384[:]),
+		DigestMultibase: digestMultibase,
+	}
+
+	assert.NoError(t, resource.VerifyDigest(content))
+	t.Run("other content", func(t *testing.T) {
+		assert.EqualError(t, resource.VerifyDigest([]byte("other")), "related resource does not match digestSRI")
+	})
+	t.Run("other content (digestMultibase)", func(t *testing.T) {
+		resource := resource
+		resource.DigestSRI = ""
+
+		assert.EqualError(t, resource.VerifyDigest([]byte("other")), "related resource does not match digestMultibase")
+	})
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		resource := RelatedResource{DigestSRI: "md5-abc="}
+
+		assert.EqualError(t, resource.VerifyDigest(content), "unsupported digestSRI algorithm: md5")
+	})
+	t.Run("no digest", func(t *testing.T) {
+		assert.EqualError(t, RelatedResource{}.VerifyDigest(content), "related resource has no digest")
+	})
+}
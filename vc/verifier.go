@@ -0,0 +1,457 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/did"
+)
+
+// Check identifies a check performed when verifying a credential.
+type Check string
+
+const (
+	// ProofCheck checks the credential has at least one proof.
+	ProofCheck = Check("proof")
+	// ProofTypeCheck checks the proof is of a supported type and can be parsed.
+	ProofTypeCheck = Check("proofType")
+	// VerificationMethodCheck checks the proof's verification method can be resolved.
+	VerificationMethodCheck = Check("verificationMethod")
+	// ControllerCheck checks the proof's verification method is controlled by the issuer.
+	ControllerCheck = Check("controller")
+	// ProofPurposeCheck checks the proof's verification method is authorized for the proof's purpose,
+	// by being listed in the corresponding verification relationship of the DID document.
+	ProofPurposeCheck = Check("proofPurpose")
+	// SignatureCheck checks the proof's signature.
+	SignatureCheck = Check("signature")
+	// ChallengeCheck checks the proof contains the challenge expected by the verifier.
+	ChallengeCheck = Check("challenge")
+	// DomainCheck checks the proof is restricted to the domain expected by the verifier.
+	DomainCheck = Check("domain")
+	// ExpiresCheck checks the proof hasn't expired.
+	ExpiresCheck = Check("expires")
+	// NotBeforeCheck checks a JWT is already valid, according to its nbf claim.
+	NotBeforeCheck = Check("notBefore")
+	// ValidityCheck checks the credential is valid at the verification time, according to its issuanceDate (or
+	// validFrom) and expirationDate (or validUntil).
+	ValidityCheck = Check("validity")
+	// KeyBindingCheck checks an SD-JWT is presented by its holder: its key binding JWT must be signed by the holder's
+	// key and be over the presented SD-JWT.
+	KeyBindingCheck = Check("keyBinding")
+	// StatusCheck checks the credential isn't revoked or suspended according to its credentialStatus, using the
+	// StatusChecker registered for its type.
+	StatusCheck = Check("status")
+)
+
+// ErrVerificationFailed is returned by VerificationResult.Err when a check failed.
+var ErrVerificationFailed = errors.New("verification failed")
+
+// CheckResult is the outcome of a single check.
+type CheckResult struct {
+	Check Check
+	// Error holds the reason the check failed. It is nil when the check passed.
+	Error error
+}
+
+// Passed returns true when the check passed.
+func (c CheckResult) Passed() bool {
+	return c.Error == nil
+}
+
+// ProofResult is the outcome of verifying a single proof of a proof set. Checks are performed in order and stop at the
+// first failing check, so checks depending on it are absent.
+type ProofResult struct {
+	// Proof holds the generic proof fields. It is empty when the proof couldn't be parsed.
+	Proof  Proof
+	Checks []CheckResult
+}
+
+// Verified returns true when all checks of the proof passed.
+func (p ProofResult) Verified() bool {
+	return len(p.Checks) > 0 && allPassed(p.Checks)
+}
+
+// VerificationResult is the outcome of verifying a credential or presentation: the checks on the document itself and
+// on each proof. For presentations, it also holds the outcome of verifying each presented credential.
+type VerificationResult struct {
+	Checks      []CheckResult
+	Proofs      []ProofResult
+	Credentials []VerificationResult
+}
+
+// DefaultKeyBindingMaxAge is the maximum age of SD-JWT key binding JWTs when the verification options don't specify one.
+const DefaultKeyBindingMaxAge = 5 * time.Minute
+
+// VerificationOptions holds the values the verifier expects proofs to be bound to.
+type VerificationOptions struct {
+	// Challenge is the challenge the verifier provided to the holder. When set, proofs must contain it.
+	Challenge string
+	// Domain is the domain of the verifier. When set, proofs must be restricted to it.
+	Domain string
+	// ValidAt is the time at which proofs and credentials must be valid, e.g. not expired. Defaults to the current time.
+	ValidAt time.Time
+	// KeyBindingMaxAge is the maximum age of SD-JWT key binding JWTs at ValidAt. Defaults to DefaultKeyBindingMaxAge.
+	KeyBindingMaxAge time.Duration
+	// SkipStatus disables checking the status of credentials, e.g. when verifying offline.
+	SkipStatus bool
+}
+
+// Verified returns true when all checks of the document, its proofs and presented credentials passed.
+func (r VerificationResult) Verified() bool {
+	return r.Err() == nil
+}
+
+// Err returns an error describing the failed checks, or nil when verification succeeded.
+func (r VerificationResult) Err() error {
+	failures := r.failures()
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrVerificationFailed, strings.Join(failures, ", "))
+}
+
+func (r VerificationResult) failures() []string {
+	var failures []string
+	for _, check := range r.Checks {
+		if !check.Passed() {
+			failures = append(failures, fmt.Sprintf("%s: %s", check.Check, check.Error))
+		}
+	}
+	for i, proof := range r.Proofs {
+		for _, check := range proof.Checks {
+			if !check.Passed() {
+				failures = append(failures, fmt.Sprintf("proof %d: %s: %s", i, check.Check, check.Error))
+			}
+		}
+	}
+	for i, credential := range r.Credentials {
+		for _, failure := range credential.failures() {
+			failures = append(failures, fmt.Sprintf("credential %d: %s", i, failure))
+		}
+	}
+	return failures
+}
+
+// Verifier verifies credentials and presentations by resolving the DID documents of their proofs' verification methods.
+type Verifier struct {
+	Resolver did.Resolver
+}
+
+// NewVerifier creates a Verifier resolving DID documents using the given resolver.
+func NewVerifier(resolver did.Resolver) *Verifier {
+	return &Verifier{Resolver: resolver}
+}
+
+// Verify verifies every proof in the credential's proof set. A proof is valid when its proof purpose is
+// assertionMethod, its verification method is controlled by the issuer and listed as assertion method, it hasn't
+// expired and its signature is valid. When the options hold a challenge or domain, the proofs must be bound to them.
+// When the credential has a credentialStatus, it must not be revoked or suspended according to the StatusChecker
+// registered for its type. Failed checks are reported in the result; an error is only returned when the credential's
+// proofs can't be processed at all. The credential itself must be valid at the time given in the options, according to
+// its issuanceDate (or validFrom) and expirationDate (or validUntil).
+func (v Verifier) Verify(ctx context.Context, credential VerifiableCredential, options VerificationOptions) (*VerificationResult, error) {
+	result, err := v.verifyProofs(ctx, credential, credential.Proof, proofRequirements{
+		controller:   &credential.Issuer,
+		proofPurpose: AssertionMethodProofPurpose,
+		challenge:    options.Challenge,
+		domain:       options.Domain,
+		validAt:      options.validAt(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	checkValidity(credential, options, result)
+	checkStatus(ctx, credential, options, result)
+	return result, nil
+}
+
+// VerifyPresentation verifies every proof in the presentation's proof set and every presented credential.
+// Presentation proofs must be created by the holder using one of its authentication methods. When the options hold a
+// challenge or domain, the proofs must be bound to them, which prevents replaying presentations to other verifiers.
+// Failed checks are reported in the result; an error is only returned when the proofs can't be processed at all.
+func (v Verifier) VerifyPresentation(ctx context.Context, presentation VerifiablePresentation, options VerificationOptions) (*VerificationResult, error) {
+	result, err := v.verifyProofs(ctx, presentation, presentation.Proof, proofRequirements{
+		controller:   presentation.Holder,
+		proofPurpose: AuthenticationProofPurpose,
+		challenge:    options.Challenge,
+		domain:       options.Domain,
+		validAt:      options.validAt(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, credential := range presentation.VerifiableCredential {
+		// The challenge and domain bind the presentation, not the credentials issued before it
+		credentialResult, err := v.Verify(ctx, credential, VerificationOptions{ValidAt: options.ValidAt, SkipStatus: options.SkipStatus})
+		if err != nil {
+			return nil, err
+		}
+		result.Credentials = append(result.Credentials, *credentialResult)
+	}
+	return result, nil
+}
+
+// proofRequirements holds the requirements proofs of a document must meet, besides a valid signature.
+type proofRequirements struct {
+	// controller is the DID that must control the proofs' verification methods.
+	controller *ssi.URI
+	// proofPurpose is the required proof purpose. When empty, any proof purpose is accepted.
+	proofPurpose string
+	// challenge is the required challenge. When empty, any challenge is accepted.
+	challenge string
+	// domain is the required domain. When empty, any domain is accepted.
+	domain string
+	// validAt is the time at which the proofs must not be expired.
+	validAt time.Time
+}
+
+func (o VerificationOptions) validAt() time.Time {
+	if o.ValidAt.IsZero() {
+		return time.Now()
+	}
+	return o.ValidAt
+}
+
+func (o VerificationOptions) keyBindingMaxAge() time.Duration {
+	if o.KeyBindingMaxAge <= 0 {
+		return DefaultKeyBindingMaxAge
+	}
+	return o.KeyBindingMaxAge
+}
+
+func (v Verifier) verifyProofs(ctx context.Context, document interface{}, proofSet []interface{}, requirements proofRequirements) (*VerificationResult, error) {
+	proofs, err := rawProofs(proofSet)
+	if err != nil {
+		return nil, err
+	}
+	result := &VerificationResult{}
+	if len(proofs) == 0 {
+		result.Checks = append(result.Checks, CheckResult{Check: ProofCheck, Error: errors.New("document has no proof")})
+		return result, nil
+	}
+	result.Checks = append(result.Checks, CheckResult{Check: ProofCheck})
+
+	documents := make(map[string]*did.Document)
+	for _, proof := range proofs {
+		result.Proofs = append(result.Proofs, v.verifyProof(ctx, document, proof, requirements, documents))
+	}
+	return result, nil
+}
+
+// verifyProof verifies a single proof over the document.
+// Resolved DID documents are kept in the given map, so they are resolved only once for the proof set.
+func (v Verifier) verifyProof(ctx context.Context, document interface{}, rawProof json.RawMessage, requirements proofRequirements, documents map[string]*did.Document) ProofResult {
+	result := ProofResult{}
+	check := func(name Check, err error) bool {
+		result.Checks = append(result.Checks, CheckResult{Check: name, Error: err})
+		return err == nil
+	}
+
+	var proof Proof
+	var suite ProofSuite
+	err := json.Unmarshal(rawProof, &proof)
+	if err == nil {
+		if suite = FindProofSuite(proof.Type, proof.Cryptosuite); suite == nil {
+			err = fmt.Errorf("unsupported proof type: %s", proofTypeName(proof))
+		}
+	}
+	if !check(ProofTypeCheck, err) {
+		return result
+	}
+	result.Proof = proof
+
+	if proof.Expires != nil && !check(ExpiresCheck, checkExpiry(*proof.Expires, requirements.validAt)) {
+		return result
+	}
+	if requirements.challenge != "" && !check(ChallengeCheck, checkBinding("challenge", proof.Challenge, requirements.challenge)) {
+		return result
+	}
+	if requirements.domain != "" && !check(DomainCheck, checkBinding("domain", proof.Domain, requirements.domain)) {
+		return result
+	}
+	vmID, vmDocument, vm, err := v.resolveVerificationMethod(ctx, proof.VerificationMethod, documents)
+	if !check(VerificationMethodCheck, err) {
+		return result
+	}
+	if !check(ControllerCheck, checkController(vmID, vmDocument, vm, requirements.controller)) {
+		return result
+	}
+	if requirements.proofPurpose != "" && proof.ProofPurpose != requirements.proofPurpose {
+		check(ProofPurposeCheck, fmt.Errorf("proof purpose must be %s, not %s", requirements.proofPurpose, proof.ProofPurpose))
+		return result
+	}
+	if !check(ProofPurposeCheck, checkProofPurpose(vmID, *vmDocument, proof.ProofPurpose)) {
+		return result
+	}
+	publicKey, err := vm.PublicKey()
+	if err == nil {
+		err = suite.Verify(document, rawProof, publicKey)
+	}
+	check(SignatureCheck, err)
+	return result
+}
+
+// resolveVerificationMethod resolves the DID document of the given verification method and returns the method.
+func (v Verifier) resolveVerificationMethod(ctx context.Context, id ssi.URI, documents map[string]*did.Document) (did.DID, *did.Document, *did.VerificationMethod, error) {
+	vmID, err := did.ParseDIDURL(id.String())
+	if err != nil {
+		return did.DID{}, nil, nil, err
+	}
+	subject := did.DID{}
+	subject.Method = vmID.Method
+	subject.ID = vmID.ID
+	subject.IDStrings = vmID.IDStrings
+	document, ok := documents[subject.String()]
+	if !ok {
+		resolution, err := did.NewContextResolver(v.Resolver).ResolveContext(ctx, subject.String(), did.ResolutionOptions{})
+		if err != nil {
+			return did.DID{}, nil, nil, fmt.Errorf("unable to resolve %s: %w", subject, err)
+		}
+		document = resolution.Document
+		documents[subject.String()] = document
+	}
+	if vm := findVerificationMethod(*document, *vmID); vm != nil {
+		return *vmID, document, vm, nil
+	}
+	return did.DID{}, nil, nil, fmt.Errorf("%w: %s", did.NotFoundErr, vmID)
+}
+
+// findVerificationMethod finds the verification method in the DID document, including methods embedded in
+// verification relationships.
+func findVerificationMethod(document did.Document, id did.DID) *did.VerificationMethod {
+	if vm := document.VerificationMethod.FindByID(id); vm != nil {
+		return vm
+	}
+	for _, relationships := range []did.VerificationRelationships{document.Authentication, document.AssertionMethod,
+		document.KeyAgreement, document.CapabilityInvocation, document.CapabilityDelegation} {
+		if vm := relationships.FindByID(id); vm != nil {
+			return vm
+		}
+	}
+	return nil
+}
+
+// checkValidity adds a ValidityCheck to the result, checking the credential's validity period at the verification time.
+func checkValidity(credential VerifiableCredential, options VerificationOptions, result *VerificationResult) {
+	result.Checks = append(result.Checks, CheckResult{Check: ValidityCheck, Error: credentialValidityError(credential, options.validAt())})
+}
+
+// credentialValidityError returns the reason the credential isn't valid at the given time, or nil when it is.
+func credentialValidityError(credential VerifiableCredential, validAt time.Time) error {
+	validFrom, validUntil := credential.Validity()
+	if !validFrom.IsZero() && validAt.Before(validFrom) {
+		return fmt.Errorf("credential is not valid before %s", validFrom.Format(time.RFC3339))
+	}
+	if validUntil != nil && validAt.After(*validUntil) {
+		return fmt.Errorf("credential expired at %s", validUntil.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkExpiry checks the proof hasn't expired at the given time.
+func checkExpiry(expires time.Time, validAt time.Time) error {
+	if validAt.After(expires) {
+		return fmt.Errorf("proof expired at %s", expires.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkBinding checks the proof's value for the given property equals the expected value.
+func checkBinding(property string, actual *string, expected string) error {
+	if actual == nil {
+		return fmt.Errorf("proof has no %s", property)
+	}
+	if *actual != expected {
+		return fmt.Errorf("proof %s does not match expected value", property)
+	}
+	return nil
+}
+
+// checkController checks the verification method belongs to the DID document of the given controller, and is
+// controlled by it or one of the DID document's controllers.
+func checkController(vmID did.DID, document *did.Document, vm *did.VerificationMethod, controller *ssi.URI) error {
+	if controller == nil {
+		return errors.New("proof can't be bound: presentation has no holder")
+	}
+	if document.ID.String() != controller.String() {
+		return fmt.Errorf("verification method %s does not belong to %s", vmID, controller.String())
+	}
+	if !vm.Controller.Empty() && !vm.Controller.Equals(document.ID) && !document.IsController(vm.Controller) {
+		return fmt.Errorf("verification method %s is controlled by %s", vmID, vm.Controller)
+	}
+	return nil
+}
+
+// checkProofPurpose checks the verification method is listed in the verification relationship for the purpose.
+func checkProofPurpose(vmID did.DID, document did.Document, proofPurpose string) error {
+	var relationships did.VerificationRelationships
+	switch proofPurpose {
+	case AssertionMethodProofPurpose:
+		relationships = document.AssertionMethod
+	case AuthenticationProofPurpose:
+		relationships = document.Authentication
+	case KeyAgreementProofPurpose:
+		relationships = document.KeyAgreement
+	case CapabilityInvocationProofPurpose:
+		relationships = document.CapabilityInvocation
+	case CapabilityDelegationProofPurpose:
+		relationships = document.CapabilityDelegation
+	default:
+		return fmt.Errorf("unsupported proof purpose: %s", proofPurpose)
+	}
+	if relationships.FindByID(vmID) == nil {
+		return fmt.Errorf("verification method %s is not authorized for %s", vmID, proofPurpose)
+	}
+	return nil
+}
+
+func proofTypeName(proof Proof) string {
+	if proof.Cryptosuite == "" {
+		return string(proof.Type)
+	}
+	return fmt.Sprintf("%s (%s)", proof.Type, proof.Cryptosuite)
+}
+
+func allPassed(checks []CheckResult) bool {
+	for _, check := range checks {
+		if !check.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// rawProofs returns the JSON of each proof in the proof set.
+func rawProofs(proofs []interface{}) ([]json.RawMessage, error) {
+	data, err := json.Marshal(proofs)
+	if err != nil {
+		return nil, err
+	}
+	var result []json.RawMessage
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
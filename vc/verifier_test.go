@@ -0,0 +1,368 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+	"time"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/did"
+	"github.com/ugradid/ugradid-common/signature"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testIssuer holds a DID document with a single Ed25519 key, listed in the given verification relationships.
+type testIssuer struct {
+	document did.Document
+	key      ed25519.PrivateKey
+	keyID    ssi.URI
+}
+
+func newTestIssuer(t *testing.T, id string, relationships ...func(*did.Document, *did.VerificationMethod)) testIssuer {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	subject, err := did.ParseDID(id)
+	require.NoError(t, err)
+	vmID, err := did.ParseDIDURL(id + "#key-1")
+	require.NoError(t, err)
+	vm, err := did.NewVerificationMethod(*vmID, ssi.ED25519VerificationKey2018, *subject, publicKey)
+	require.NoError(t, err)
+	document := did.Document{Context: []ssi.URI{did.DIDContextV1URI()}, ID: *subject}
+	document.VerificationMethod.Add(vm)
+	for _, relationship := range relationships {
+		relationship(&document, vm)
+	}
+	return testIssuer{document: document, key: privateKey, keyID: vmID.URI()}
+}
+
+func (i testIssuer) credential(t *testing.T) VerifiableCredential {
+	credential := testCredential()
+	credential.Issuer = i.document.ID.URI()
+	require.NoError(t, SignCredential(&credential, i.key, i.keyID, ProofOptions{}))
+	return credential
+}
+
+func failedCheck(result ProofResult) Check {
+	for _, check := range result.Checks {
+		if !check.Passed() {
+			return check.Check
+		}
+	}
+	return ""
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	ctx := context.Background()
+	store := did.NewMemoryStore()
+	issuer := newTestIssuer(t, "did:example:issuer", (*did.Document).AddAssertionMethod)
+	_, err := store.Put(issuer.document, time.Now())
+	require.NoError(t, err)
+	verifier := NewVerifier(store)
+
+	t.Run("ok", func(t *testing.T) {
+		credential := issuer.credential(t)
+
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.True(t, result.Verified())
+		assert.NoError(t, result.Err())
+		require.Len(t, result.Proofs, 1)
+		assert.Equal(t, []Check{ProofTypeCheck, VerificationMethodCheck, ControllerCheck, ProofPurposeCheck, SignatureCheck},
+			checks(result.Proofs[0]))
+	})
+	t.Run("ok - after JSON round trip", func(t *testing.T) {
+		data, _ := json.Marshal(issuer.credential(t))
+		var credential VerifiableCredential
+		require.NoError(t, json.Unmarshal(data, &credential))
+
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+	})
+	t.Run("tampered credential", func(t *testing.T) {
+		credential := issuer.credential(t)
+		credential.CredentialSubject["name"] = "Mallory"
+
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Err(), ErrVerificationFailed)
+		assert.Equal(t, SignatureCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("no proof", func(t *testing.T) {
+		result, err := verifier.Verify(ctx, testCredential(), VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.False(t, result.Verified())
+		assert.Equal(t, ProofCheck, result.Checks[0].Check)
+	})
+	t.Run("unsupported proof type", func(t *testing.T) {
+		credential := testCredential()
+		credential.Proof = []interface{}{map[string]interface{}{"type": "Unknown"}}
+
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, ProofTypeCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("unknown DID", func(t *testing.T) {
+		other := newTestIssuer(t, "did:example:other", (*did.Document).AddAssertionMethod)
+
+		result, err := verifier.Verify(ctx, other.credential(t), VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, VerificationMethodCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("verification method of other DID", func(t *testing.T) {
+		credential := issuer.credential(t)
+		other, _ := ssi.ParseURI("did:example:other")
+		credential.Issuer = *other
+
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, ControllerCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("verification method not authorized for proof purpose", func(t *testing.T) {
+		authenticator := newTestIssuer(t, "did:example:authenticator", (*did.Document).AddAuthenticationMethod)
+		_, err := store.Put(authenticator.document, time.Now())
+		require.NoError(t, err)
+
+		result, err := verifier.Verify(ctx, authenticator.credential(t), VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, ProofPurposeCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("signed with authentication key", func(t *testing.T) {
+		authenticator := newTestIssuer(t, "did:example:authentication-only", (*did.Document).AddAuthenticationMethod)
+		_, err := store.Put(authenticator.document, time.Now())
+		require.NoError(t, err)
+		credential := testCredential()
+		credential.Issuer = authenticator.document.ID.URI()
+		require.NoError(t, SignCredential(&credential, authenticator.key, authenticator.keyID, ProofOptions{ProofPurpose: AuthenticationProofPurpose}))
+
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.False(t, result.Verified())
+		assert.Equal(t, ProofPurposeCheck, failedCheck(result.Proofs[0]))
+		assert.EqualError(t, result.Proofs[0].Checks[len(result.Proofs[0].Checks)-1].Error, "proof purpose must be assertionMethod, not authentication")
+	})
+	t.Run("credential validity", func(t *testing.T) {
+		credential := testCredential()
+		credential.Issuer = issuer.document.ID.URI()
+		expirationDate := credential.IssuanceDate.AddDate(1, 0, 0)
+		credential.ExpirationDate = &expirationDate
+		require.NoError(t, SignCredential(&credential, issuer.key, issuer.keyID, ProofOptions{}))
+		verify := func(validAt time.Time) error {
+			result, err := verifier.Verify(ctx, credential, VerificationOptions{ValidAt: validAt})
+			require.NoError(t, err)
+			assert.Equal(t, ValidityCheck, result.Checks[len(result.Checks)-1].Check)
+			return result.Checks[len(result.Checks)-1].Error
+		}
+
+		assert.NoError(t, verify(credential.IssuanceDate.AddDate(0, 6, 0)))
+		assert.EqualError(t, verify(credential.IssuanceDate.Add(-time.Second)), "credential is not valid before 2021-01-01T00:00:00Z")
+		assert.EqualError(t, verify(expirationDate.Add(time.Second)), "credential expired at 2022-01-01T00:00:00Z")
+	})
+	t.Run("credential validity - 2.0", func(t *testing.T) {
+		validFrom := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		validUntil := validFrom.AddDate(1, 0, 0)
+		credential := testCredential()
+		credential.Context = []ssi.URI{VCContextV2URI()}
+		credential.Issuer = issuer.document.ID.URI()
+		credential.IssuanceDate = time.Time{}
+		credential.ValidFrom = &validFrom
+		credential.ValidUntil = &validUntil
+		require.NoError(t, SignCredential(&credential, issuer.key, issuer.keyID, ProofOptions{}))
+
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.False(t, result.Verified())
+		assert.EqualError(t, result.Checks[len(result.Checks)-1].Error, "credential expired at 2022-01-01T00:00:00Z")
+	})
+	t.Run("expired proof", func(t *testing.T) {
+		credential := testCredential()
+		credential.Issuer = issuer.document.ID.URI()
+		expires := time.Now().Add(time.Hour)
+		require.NoError(t, SignCredential(&credential, issuer.key, issuer.keyID, ProofOptions{Expires: &expires}))
+
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{})
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+		result, err = verifier.Verify(ctx, credential, VerificationOptions{ValidAt: expires.Add(time.Second)})
+		require.NoError(t, err)
+		assert.Equal(t, ExpiresCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("expected challenge", func(t *testing.T) {
+		credential := testCredential()
+		credential.Issuer = issuer.document.ID.URI()
+		challenge := "abc"
+		require.NoError(t, SignCredential(&credential, issuer.key, issuer.keyID, ProofOptions{Challenge: &challenge}))
+
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{Challenge: challenge})
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+		result, err = verifier.Verify(ctx, issuer.credential(t), VerificationOptions{Challenge: challenge})
+		require.NoError(t, err)
+		assert.Equal(t, ChallengeCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("RSA issuer", func(t *testing.T) {
+		rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+		subject, _ := did.ParseDID("did:example:rsa")
+		vmID, _ := did.ParseDIDURL("did:example:rsa#key-1")
+		vm, err := did.NewVerificationMethod(*vmID, ssi.RSAVerificationKey2018, *subject, &rsaKey.PublicKey)
+		require.NoError(t, err)
+		document := did.Document{Context: []ssi.URI{did.DIDContextV1URI()}, ID: *subject}
+		document.AddAssertionMethod(vm)
+		_, err = store.Put(document, time.Now())
+		require.NoError(t, err)
+		credential := testCredential()
+		credential.Issuer = subject.URI()
+		require.NoError(t, SignCredential(&credential, rsaKey, vmID.URI(), ProofOptions{Suite: JSONWebSignature2020Suite{Algorithm: signature.RS256}}))
+
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{})
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+	})
+	t.Run("proof set with invalid proof", func(t *testing.T) {
+		credential := issuer.credential(t)
+		_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, SignCredential(&credential, otherKey, issuer.keyID, ProofOptions{}))
+
+		result, err := verifier.Verify(ctx, credential, VerificationOptions{})
+
+		require.NoError(t, err)
+		require.Len(t, result.Proofs, 2)
+		assert.True(t, result.Proofs[0].Verified())
+		assert.False(t, result.Proofs[1].Verified())
+		assert.False(t, result.Verified())
+	})
+}
+
+func checks(result ProofResult) []Check {
+	var names []Check
+	for _, check := range result.Checks {
+		names = append(names, check.Check)
+	}
+	return names
+}
+
+func TestVerifier_VerifyPresentation(t *testing.T) {
+	ctx := context.Background()
+	store := did.NewMemoryStore()
+	issuer := newTestIssuer(t, "did:example:issuer", (*did.Document).AddAssertionMethod)
+	holder := newTestIssuer(t, "did:example:holder", (*did.Document).AddAuthenticationMethod)
+	for _, document := range []did.Document{issuer.document, holder.document} {
+		_, err := store.Put(document, time.Now())
+		require.NoError(t, err)
+	}
+	verifier := NewVerifier(store)
+	challenge := "c0ae1c8e-c7e7-469f-b252-86e6a0e7387e"
+	domain := "verifier.example.com"
+	options := VerificationOptions{Challenge: challenge, Domain: domain}
+
+	presentation := func(t *testing.T, proofOptions ProofOptions) VerifiablePresentation {
+		holderID := holder.document.ID.URI()
+		result := VerifiablePresentation{
+			Context:              []ssi.URI{VCContextV1URI()},
+			Type:                 []ssi.URI{VerifiablePresentationTypeV1URI()},
+			Holder:               &holderID,
+			VerifiableCredential: []VerifiableCredential{issuer.credential(t)},
+		}
+		require.NoError(t, SignPresentation(&result, holder.key, holder.keyID, proofOptions))
+		return result
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		result, err := verifier.VerifyPresentation(ctx, presentation(t, ProofOptions{Challenge: &challenge, Domain: &domain}), options)
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+		require.Len(t, result.Credentials, 1)
+		assert.True(t, result.Credentials[0].Verified())
+		proofs := result.Proofs[0]
+		assert.Equal(t, AuthenticationProofPurpose, proofs.Proof.ProofPurpose)
+		assert.Contains(t, checks(proofs), ChallengeCheck)
+		assert.Contains(t, checks(proofs), DomainCheck)
+	})
+	t.Run("ok - after JSON round trip", func(t *testing.T) {
+		data, _ := json.Marshal(presentation(t, ProofOptions{Challenge: &challenge, Domain: &domain}))
+		var vp VerifiablePresentation
+		require.NoError(t, json.Unmarshal(data, &vp))
+
+		result, err := verifier.VerifyPresentation(ctx, vp, options)
+
+		require.NoError(t, err)
+		assert.NoError(t, result.Err())
+	})
+	t.Run("wrong challenge", func(t *testing.T) {
+		other := "other"
+
+		result, err := verifier.VerifyPresentation(ctx, presentation(t, ProofOptions{Challenge: &other, Domain: &domain}), options)
+
+		require.NoError(t, err)
+		assert.Equal(t, ChallengeCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("missing domain", func(t *testing.T) {
+		result, err := verifier.VerifyPresentation(ctx, presentation(t, ProofOptions{Challenge: &challenge}), options)
+
+		require.NoError(t, err)
+		assert.Equal(t, DomainCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("signed with assertion key", func(t *testing.T) {
+		vp := presentation(t, ProofOptions{Challenge: &challenge, Domain: &domain, ProofPurpose: AssertionMethodProofPurpose})
+
+		result, err := verifier.VerifyPresentation(ctx, vp, options)
+
+		require.NoError(t, err)
+		assert.Equal(t, ProofPurposeCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("not signed by holder", func(t *testing.T) {
+		vp := presentation(t, ProofOptions{Challenge: &challenge, Domain: &domain})
+		other, _ := ssi.ParseURI("did:example:issuer")
+		vp.Holder = other
+
+		result, err := verifier.VerifyPresentation(ctx, vp, options)
+
+		require.NoError(t, err)
+		assert.Equal(t, ControllerCheck, failedCheck(result.Proofs[0]))
+	})
+	t.Run("invalid credential", func(t *testing.T) {
+		vp := presentation(t, ProofOptions{Challenge: &challenge, Domain: &domain})
+		vp.VerifiableCredential[0].CredentialSubject["name"] = "Mallory"
+
+		result, err := verifier.VerifyPresentation(ctx, vp, options)
+
+		require.NoError(t, err)
+		assert.False(t, result.Verified())
+		assert.Contains(t, result.Err().Error(), "credential 0: proof 0: signature")
+	})
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"encoding/json"
+
+	ssi "github.com/ugradid/ugradid-common"
+	"github.com/ugradid/ugradid-common/marshal"
+)
+
+// VerifiablePresentationType is the default presentation type required for every presentation
+const VerifiablePresentationType = "VerifiablePresentation"
+
+// VerifiablePresentationTypeV1URI returns VerifiablePresentation as URI
+func VerifiablePresentationTypeV1URI() ssi.URI {
+	if pURI, err := ssi.ParseURI(VerifiablePresentationType); err != nil {
+		panic(err)
+	} else {
+		return *pURI
+	}
+}
+
+// VerifiablePresentation represents a presentation as defined by the Verifiable Credentials Data Model 1.0 specification (https://www.w3.org/TR/vc-data-model/#presentations-0).
+type VerifiablePresentation struct {
+	// Context defines the json-ld context to dereference the URIs
+	Context []ssi.URI `json:"@context"`
+	// ID is an unique identifier for the presentation. It is optional
+	ID *ssi.URI `json:"id,omitempty"`
+	// Type holds multiple types for a presentation. A presentation must always have the 'VerifiablePresentation' type.
+	Type []ssi.URI `json:"type"`
+	// Holder refers to the party that generated the presentation. Its proofs must be created by the holder.
+	Holder *ssi.URI `json:"holder,omitempty"`
+	// VerifiableCredential holds the credentials presented by the holder. It is optional
+	VerifiableCredential []VerifiableCredential `json:"verifiableCredential,omitempty"`
+	// Proof contains the cryptographic proof(s). It must be extracted using the Proofs method or UnmarshalProofValue method for non-generic proof fields.
+	Proof []interface{} `json:"proof,omitempty"`
+}
+
+// Proofs returns the basic proofs for this presentation. For specific proof contents, UnmarshalProofValue must be used.
+func (vp VerifiablePresentation) Proofs() ([]Proof, error) {
+	var target []Proof
+	err := vp.UnmarshalProofValue(&target)
+	return target, err
+}
+
+func (vp VerifiablePresentation) MarshalJSON() ([]byte, error) {
+	type alias VerifiablePresentation
+	tmp := alias(vp)
+	if data, err := json.Marshal(tmp); err != nil {
+		return nil, err
+	} else {
+		return marshal.NormalizeDocument(data, pluralContext,
+			marshal.Unplural(typeKey), marshal.Unplural(verifiableCredentialKey), marshal.Unplural(proofKey))
+	}
+}
+
+func (vp *VerifiablePresentation) UnmarshalJSON(b []byte) error {
+	type Alias VerifiablePresentation
+	normalizedVP, err := marshal.NormalizeDocument(b,
+		pluralContext, marshal.Plural(typeKey), marshal.Plural(verifiableCredentialKey), marshal.Plural(proofKey))
+	if err != nil {
+		return err
+	}
+	tmp := Alias{}
+	err = json.Unmarshal(normalizedVP, &tmp)
+	if err != nil {
+		return err
+	}
+	*vp = (VerifiablePresentation)(tmp)
+	return nil
+}
+
+// UnmarshalProofValue unmarshalls the proof to the given proof type. Always pass a slice as target since there could be multiple proofs.
+func (vp VerifiablePresentation) UnmarshalProofValue(target interface{}) error {
+	if asJSON, err := json.Marshal(vp.Proof); err != nil {
+		return err
+	} else {
+		return json.Unmarshal(asJSON, target)
+	}
+}
+
+// IsType returns true when a presentation contains the requested type
+func (vp VerifiablePresentation) IsType(vpType ssi.URI) bool {
+	for _, t := range vp.Type {
+		if t.String() == vpType.String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ContainsContext returns true when a presentation contains the requested context
+func (vp VerifiablePresentation) ContainsContext(context ssi.URI) bool {
+	for _, c := range vp.Context {
+		if c.String() == context.String() {
+			return true
+		}
+	}
+
+	return false
+}
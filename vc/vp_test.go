@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2021 ugradid community
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package vc
+
+import (
+	"encoding/json"
+	"testing"
+
+	ssi "github.com/ugradid/ugradid-common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifiablePresentation_MarshalJSON(t *testing.T) {
+	t.Run("single values are unpluralized", func(t *testing.T) {
+		presentation := VerifiablePresentation{
+			Context:              []ssi.URI{VCContextV1URI()},
+			Type:                 []ssi.URI{VerifiablePresentationTypeV1URI()},
+			VerifiableCredential: []VerifiableCredential{testCredential()},
+		}
+
+		data, err := json.Marshal(presentation)
+
+		require.NoError(t, err)
+		var asMap map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &asMap))
+		assert.Equal(t, VerifiablePresentationType, asMap["type"])
+		assert.IsType(t, map[string]interface{}{}, asMap["verifiableCredential"])
+		assert.NotContains(t, asMap, "proof")
+	})
+	t.Run("single values are pluralized", func(t *testing.T) {
+		const input = `{
+  "@context": "https://www.w3.org/2018/credentials/v1",
+  "type": "VerifiablePresentation",
+  "holder": "did:example:holder",
+  "verifiableCredential": {
+    "@context": "https://www.w3.org/2018/credentials/v1",
+    "type": "VerifiableCredential",
+    "issuer": "did:example:issuer",
+    "issuanceDate": "2021-01-01T00:00:00Z",
+    "credentialSubject": {"id": "did:example:holder"}
+  },
+  "proof": {"type": "JsonWebSignature2020"}
+}`
+		var presentation VerifiablePresentation
+
+		err := json.Unmarshal([]byte(input), &presentation)
+
+		require.NoError(t, err)
+		assert.True(t, presentation.IsType(VerifiablePresentationTypeV1URI()))
+		assert.True(t, presentation.ContainsContext(VCContextV1URI()))
+		assert.Equal(t, "did:example:holder", presentation.Holder.String())
+		require.Len(t, presentation.VerifiableCredential, 1)
+		assert.Equal(t, "did:example:issuer", presentation.VerifiableCredential[0].Issuer.String())
+		proofs, err := presentation.Proofs()
+		require.NoError(t, err)
+		require.Len(t, proofs, 1)
+	})
+}